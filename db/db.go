@@ -6,16 +6,13 @@ import (
 	"fmt"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/nathanbizkit/article-management-go/env"
 )
 
 // New returns a database pool connection
 func New(environ *env.ENV) (*sql.DB, error) {
-	psqlInfo := fmt.Sprintf(
-		"user=%s password=%s host=%s port=%s dbname=%s sslmode=disable",
-		environ.DBUser, environ.DBPass, environ.DBHost, environ.DBPort, environ.DBName,
-	)
+	psqlInfo := connInfo(environ)
 
 	var db *sql.DB
 	var err error
@@ -39,6 +36,12 @@ func New(environ *env.ENV) (*sql.DB, error) {
 	return db, nil
 }
 
+// NewListener returns a listener of postgres notifications on its own connection,
+// which reconnects by itself and calls eventCallback on connection events
+func NewListener(environ *env.ENV, eventCallback pq.EventCallbackType) *pq.Listener {
+	return pq.NewListener(connInfo(environ), 10*time.Second, time.Minute, eventCallback)
+}
+
 // RunInTx wraps database operations with a transaction
 func RunInTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
 	tx, err := db.Begin()
@@ -58,3 +61,17 @@ func RunInTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
 
 	return err
 }
+
+// idleInTxTimeout is how long a session may sit idle in a transaction before postgres ends it
+//
+// Stream and outbox events are held back while any transaction started before theirs is open,
+// so a session left idle in a transaction must not hold them back for long.
+const idleInTxTimeout = time.Minute
+
+// connInfo returns the connection string of database
+func connInfo(environ *env.ENV) string {
+	return fmt.Sprintf(
+		"user=%s password=%s host=%s port=%s dbname=%s sslmode=disable idle_in_transaction_session_timeout=%d",
+		environ.DBUser, environ.DBPass, environ.DBHost, environ.DBPort, environ.DBName, idleInTxTimeout.Milliseconds(),
+	)
+}
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS article_tags_search_vector_update ON article_management.article_tags;
+DROP TRIGGER IF EXISTS articles_search_vector_update ON article_management.articles;
+DROP FUNCTION IF EXISTS article_management.article_tags_search_vector_trigger();
+DROP FUNCTION IF EXISTS article_management.articles_search_vector_trigger();
+DROP FUNCTION IF EXISTS article_management.article_search_vector(INTEGER, TEXT, TEXT, TEXT);
+DROP INDEX IF EXISTS article_management.articles_search_vector_idx;
+ALTER TABLE article_management.articles DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,61 @@
+ALTER TABLE article_management.articles
+	ADD COLUMN IF NOT EXISTS search_vector TSVECTOR NOT NULL DEFAULT ''::TSVECTOR;
+
+CREATE INDEX IF NOT EXISTS articles_search_vector_idx
+	ON article_management.articles USING GIN (search_vector);
+
+CREATE OR REPLACE FUNCTION article_management.article_search_vector(
+	p_article_id INTEGER,
+	p_title TEXT,
+	p_description TEXT,
+	p_body TEXT
+) RETURNS TSVECTOR AS $$
+	SELECT
+		setweight(to_tsvector('english', COALESCE(p_title, '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE((
+			SELECT string_agg(t.name, ' ')
+			FROM article_management.article_tags at
+			INNER JOIN article_management.tags t ON t.id = at.tag_id
+			WHERE at.article_id = p_article_id
+		), '')), 'A') ||
+		setweight(to_tsvector('english', COALESCE(p_description, '')), 'B') ||
+		setweight(to_tsvector('english', COALESCE(p_body, '')), 'C');
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION article_management.articles_search_vector_trigger() RETURNS TRIGGER AS $$
+BEGIN
+	NEW.search_vector := article_management.article_search_vector(NEW.id, NEW.title, NEW.description, NEW.body);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION article_management.article_tags_search_vector_trigger() RETURNS TRIGGER AS $$
+DECLARE
+	target_article_id INTEGER;
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		target_article_id := OLD.article_id;
+	ELSE
+		target_article_id := NEW.article_id;
+	END IF;
+
+	UPDATE article_management.articles a
+	SET search_vector = article_management.article_search_vector(a.id, a.title, a.description, a.body)
+	WHERE a.id = target_article_id;
+
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS articles_search_vector_update ON article_management.articles;
+CREATE TRIGGER articles_search_vector_update
+	BEFORE INSERT OR UPDATE OF title, description, body ON article_management.articles
+	FOR EACH ROW EXECUTE FUNCTION article_management.articles_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS article_tags_search_vector_update ON article_management.article_tags;
+CREATE TRIGGER article_tags_search_vector_update
+	AFTER INSERT OR DELETE ON article_management.article_tags
+	FOR EACH ROW EXECUTE FUNCTION article_management.article_tags_search_vector_trigger();
+
+UPDATE article_management.articles
+SET search_vector = article_management.article_search_vector(id, title, description, body);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS article_management.article_media;
+DROP TABLE IF EXISTS article_management.media;
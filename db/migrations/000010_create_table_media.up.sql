@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS article_management.media (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	storage_key VARCHAR(255) NOT NULL UNIQUE,
+	filename VARCHAR(255) NOT NULL,
+	content_type VARCHAR(100) NOT NULL,
+	size BIGINT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS media_user_id_idx ON article_management.media (user_id);
+
+CREATE TABLE IF NOT EXISTS article_management.article_media (
+	article_id INTEGER REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	media_id INTEGER REFERENCES article_management.media (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (article_id, media_id)
+);
@@ -0,0 +1,7 @@
+ALTER TABLE article_management.users DROP COLUMN IF EXISTS avatar_media_id;
+
+DROP TABLE IF EXISTS article_management.media_variants;
+
+DROP INDEX IF EXISTS article_management.media_unprocessed_idx;
+
+ALTER TABLE article_management.media DROP COLUMN IF EXISTS processed_at;
@@ -0,0 +1,22 @@
+ALTER TABLE article_management.media
+	ADD COLUMN IF NOT EXISTS processed_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS media_unprocessed_idx
+	ON article_management.media (id) WHERE processed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS article_management.media_variants (
+	id SERIAL PRIMARY KEY,
+	media_id INTEGER NOT NULL REFERENCES article_management.media (id) ON DELETE CASCADE,
+	name VARCHAR(50) NOT NULL,
+	storage_key VARCHAR(255) NOT NULL UNIQUE,
+	content_type VARCHAR(100) NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	size BIGINT NOT NULL,
+	hash VARCHAR(64) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (media_id, name)
+);
+
+ALTER TABLE article_management.users
+	ADD COLUMN IF NOT EXISTS avatar_media_id INTEGER REFERENCES article_management.media (id) ON DELETE SET NULL;
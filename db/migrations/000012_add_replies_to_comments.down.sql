@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS article_management.comments_parent_id_idx;
+
+ALTER TABLE article_management.comments
+	DROP COLUMN IF EXISTS deleted_at,
+	DROP COLUMN IF EXISTS depth,
+	DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,7 @@
+ALTER TABLE article_management.comments
+	ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	ADD COLUMN IF NOT EXISTS depth INTEGER NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx
+	ON article_management.comments (parent_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS article_management.comment_revisions;
+
+ALTER TABLE article_management.comments
+	DROP COLUMN IF EXISTS edited_at;
@@ -0,0 +1,12 @@
+ALTER TABLE article_management.comments
+	ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS article_management.comment_revisions (
+	id SERIAL PRIMARY KEY,
+	comment_id INTEGER NOT NULL REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	body TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS comment_revisions_comment_id_idx
+	ON article_management.comment_revisions (comment_id, created_at);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS article_management.comments_article_id_created_at_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS comments_article_id_created_at_idx
+	ON article_management.comments (article_id, created_at, id) WHERE parent_id IS NULL;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS article_management.comment_reaction_counts;
+DROP TABLE IF EXISTS article_management.comment_reactions;
+DROP TABLE IF EXISTS article_management.article_reaction_counts;
+DROP TABLE IF EXISTS article_management.article_reactions;
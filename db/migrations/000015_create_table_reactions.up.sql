@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS article_management.article_reactions (
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	reaction VARCHAR(32) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (article_id, user_id, reaction)
+);
+
+CREATE TABLE IF NOT EXISTS article_management.article_reaction_counts (
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	reaction VARCHAR(32) NOT NULL,
+	count INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (article_id, reaction)
+);
+
+CREATE TABLE IF NOT EXISTS article_management.comment_reactions (
+	comment_id INTEGER NOT NULL REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	reaction VARCHAR(32) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (comment_id, user_id, reaction)
+);
+
+CREATE TABLE IF NOT EXISTS article_management.comment_reaction_counts (
+	comment_id INTEGER NOT NULL REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	reaction VARCHAR(32) NOT NULL,
+	count INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (comment_id, reaction)
+);
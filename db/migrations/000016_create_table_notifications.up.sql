@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS article_management.notifications (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	actor_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	type VARCHAR(32) NOT NULL,
+	article_id INTEGER REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	comment_id INTEGER REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	read_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx
+	ON article_management.notifications (user_id, created_at DESC, id DESC);
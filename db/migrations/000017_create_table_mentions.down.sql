@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS article_management.comment_mentions;
+DROP TABLE IF EXISTS article_management.article_mentions;
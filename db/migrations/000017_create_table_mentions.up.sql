@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS article_management.article_mentions (
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (article_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS article_management.comment_mentions (
+	comment_id INTEGER NOT NULL REFERENCES article_management.comments (id) ON DELETE CASCADE,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (comment_id, user_id)
+);
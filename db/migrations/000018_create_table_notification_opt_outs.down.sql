@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS article_management.notifications_user_id_unread_idx;
+DROP TABLE IF EXISTS article_management.notification_opt_outs;
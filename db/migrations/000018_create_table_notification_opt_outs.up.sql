@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS article_management.notification_opt_outs (
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	type VARCHAR(32) NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, type)
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_unread_idx
+	ON article_management.notifications (user_id)
+	WHERE read_at IS NULL;
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS articles_favorites_stream ON article_management.articles;
+DROP TRIGGER IF EXISTS comments_stream ON article_management.comments;
+DROP TRIGGER IF EXISTS notifications_stream ON article_management.notifications;
+DROP TRIGGER IF EXISTS stream_events_notify ON article_management.stream_events;
+DROP FUNCTION IF EXISTS article_management.articles_favorites_stream_trigger();
+DROP FUNCTION IF EXISTS article_management.comments_stream_trigger();
+DROP FUNCTION IF EXISTS article_management.notifications_stream_trigger();
+DROP FUNCTION IF EXISTS article_management.stream_events_notify_trigger();
+DROP TABLE IF EXISTS article_management.stream_events;
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS article_management.stream_events (
+	id BIGSERIAL PRIMARY KEY,
+	type VARCHAR(32) NOT NULL,
+	user_id INTEGER REFERENCES article_management.users (id) ON DELETE CASCADE,
+	article_id INTEGER REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	target_id INTEGER NOT NULL,
+	-- ids are not committed in order, so events are pushed in order of
+	-- transactions which wrote them once those are finished
+	tx_id XID8 NOT NULL DEFAULT pg_current_xact_id(),
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS stream_events_tx_id_idx
+	ON article_management.stream_events (tx_id, id);
+
+CREATE INDEX IF NOT EXISTS stream_events_created_at_idx
+	ON article_management.stream_events (created_at);
+
+CREATE OR REPLACE FUNCTION article_management.stream_events_notify_trigger() RETURNS TRIGGER AS $$
+BEGIN
+	PERFORM pg_notify('article_management_stream', NEW.id::TEXT);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION article_management.notifications_stream_trigger() RETURNS TRIGGER AS $$
+BEGIN
+	INSERT INTO article_management.stream_events (type, user_id, target_id)
+	VALUES ('notification', NEW.user_id, NEW.id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION article_management.comments_stream_trigger() RETURNS TRIGGER AS $$
+BEGIN
+	INSERT INTO article_management.stream_events (type, article_id, target_id)
+	VALUES ('comment', NEW.article_id, NEW.id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION article_management.articles_favorites_stream_trigger() RETURNS TRIGGER AS $$
+BEGIN
+	IF NEW.favorites_count IS DISTINCT FROM OLD.favorites_count THEN
+		INSERT INTO article_management.stream_events (type, article_id, target_id)
+		VALUES ('favorites', NEW.id, NEW.id);
+	END IF;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS stream_events_notify ON article_management.stream_events;
+CREATE TRIGGER stream_events_notify
+	AFTER INSERT ON article_management.stream_events
+	FOR EACH ROW EXECUTE FUNCTION article_management.stream_events_notify_trigger();
+
+DROP TRIGGER IF EXISTS notifications_stream ON article_management.notifications;
+CREATE TRIGGER notifications_stream
+	AFTER INSERT ON article_management.notifications
+	FOR EACH ROW EXECUTE FUNCTION article_management.notifications_stream_trigger();
+
+DROP TRIGGER IF EXISTS comments_stream ON article_management.comments;
+CREATE TRIGGER comments_stream
+	AFTER INSERT ON article_management.comments
+	FOR EACH ROW EXECUTE FUNCTION article_management.comments_stream_trigger();
+
+DROP TRIGGER IF EXISTS articles_favorites_stream ON article_management.articles;
+CREATE TRIGGER articles_favorites_stream
+	AFTER UPDATE OF favorites_count ON article_management.articles
+	FOR EACH ROW EXECUTE FUNCTION article_management.articles_favorites_stream_trigger();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS article_management.webhook_delivery_attempts;
+DROP TABLE IF EXISTS article_management.webhook_deliveries;
+DROP TABLE IF EXISTS article_management.webhooks;
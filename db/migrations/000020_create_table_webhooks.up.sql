@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS article_management.webhooks (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	url TEXT NOT NULL,
+	secret VARCHAR(64) NOT NULL,
+	events VARCHAR(32)[] NOT NULL,
+	all_articles BOOLEAN NOT NULL DEFAULT FALSE,
+	active BOOLEAN NOT NULL DEFAULT TRUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx
+	ON article_management.webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS article_management.webhook_deliveries (
+	id BIGSERIAL PRIMARY KEY,
+	webhook_id INTEGER NOT NULL REFERENCES article_management.webhooks (id) ON DELETE CASCADE,
+	event VARCHAR(32) NOT NULL,
+	payload JSONB NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'pending',
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	last_response_code INTEGER,
+	delivered_at TIMESTAMPTZ,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx
+	ON article_management.webhook_deliveries (webhook_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx
+	ON article_management.webhook_deliveries (next_attempt_at)
+	WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS article_management.webhook_delivery_attempts (
+	id BIGSERIAL PRIMARY KEY,
+	delivery_id BIGINT NOT NULL REFERENCES article_management.webhook_deliveries (id) ON DELETE CASCADE,
+	response_code INTEGER,
+	error TEXT NOT NULL DEFAULT '',
+	duration_ms INTEGER NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_attempts_delivery_id_idx
+	ON article_management.webhook_delivery_attempts (delivery_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS article_management.outbox_checkpoints;
+DROP TABLE IF EXISTS article_management.outbox;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS article_management.outbox (
+	id BIGSERIAL PRIMARY KEY,
+	-- ids are not committed in order, so events are relayed in order of
+	-- transactions which wrote them once those are finished
+	tx_id XID8 NOT NULL DEFAULT pg_current_xact_id(),
+	type VARCHAR(32) NOT NULL,
+	actor_id INTEGER NOT NULL,
+	user_id INTEGER,
+	article_id INTEGER,
+	comment_id INTEGER,
+	payload JSONB NOT NULL DEFAULT '{}'::JSONB,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_tx_id_idx
+	ON article_management.outbox (tx_id, id);
+
+CREATE INDEX IF NOT EXISTS outbox_created_at_idx
+	ON article_management.outbox (created_at);
+
+CREATE TABLE IF NOT EXISTS article_management.outbox_checkpoints (
+	consumer VARCHAR(64) PRIMARY KEY,
+	last_tx_id XID8 NOT NULL DEFAULT '0',
+	last_event_id BIGINT NOT NULL DEFAULT 0,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
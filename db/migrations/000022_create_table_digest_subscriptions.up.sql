@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS article_management.digest_subscriptions (
+	user_id INTEGER PRIMARY KEY REFERENCES article_management.users (id) ON DELETE CASCADE,
+	frequency VARCHAR(16) NOT NULL DEFAULT 'off',
+	unsubscribe_token VARCHAR(64) NOT NULL UNIQUE,
+	-- end of the period covered by the last digest, new articles after it go to the next one
+	last_sent_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	-- digests are claimed while being sent, last_sent_at only moves once one is sent
+	-- and a claim of a digest which was never sent expires so that it is sent again
+	claimed_at TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS digest_subscriptions_due_idx
+	ON article_management.digest_subscriptions (last_sent_at)
+	WHERE frequency <> 'off';
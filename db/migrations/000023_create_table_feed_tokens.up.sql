@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS article_management.feed_tokens (
+	user_id INTEGER PRIMARY KEY REFERENCES article_management.users (id) ON DELETE CASCADE,
+	-- secret of private feed url of the user, rotating it revokes the previous url
+	token VARCHAR(64) NOT NULL UNIQUE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
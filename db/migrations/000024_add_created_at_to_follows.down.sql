@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS article_management.follows_from_user_id_created_at_idx;
+
+DROP INDEX IF EXISTS article_management.follows_to_user_id_created_at_idx;
+
+ALTER TABLE article_management.follows DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,9 @@
+ALTER TABLE article_management.follows
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- followers and following lists are ordered by newest follow first
+CREATE INDEX IF NOT EXISTS follows_to_user_id_created_at_idx
+	ON article_management.follows (to_user_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS follows_from_user_id_created_at_idx
+	ON article_management.follows (from_user_id, created_at DESC);
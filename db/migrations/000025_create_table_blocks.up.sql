@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS article_management.blocks (
+	from_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	to_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (from_user_id, to_user_id),
+	CHECK (from_user_id <> to_user_id)
+);
+
+-- blocks are looked up both ways, as a block hides both users from each other
+CREATE INDEX IF NOT EXISTS blocks_to_user_id_idx
+	ON article_management.blocks (to_user_id);
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS article_management.mutes (
+	from_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	to_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (from_user_id, to_user_id),
+	CHECK (from_user_id <> to_user_id)
+);
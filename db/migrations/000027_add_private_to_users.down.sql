@@ -0,0 +1 @@
+ALTER TABLE article_management.users DROP COLUMN IF EXISTS private;
@@ -0,0 +1,3 @@
+-- articles of private users are only visible to their approved followers
+ALTER TABLE article_management.users
+	ADD COLUMN IF NOT EXISTS private BOOLEAN NOT NULL DEFAULT FALSE;
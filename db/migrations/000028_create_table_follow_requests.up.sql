@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS article_management.follow_requests (
+	from_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	to_user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (from_user_id, to_user_id),
+	CHECK (from_user_id <> to_user_id)
+);
+
+-- pending requests are listed for the requested user, newest first
+CREATE INDEX IF NOT EXISTS follow_requests_to_user_id_created_at_idx
+	ON article_management.follow_requests (to_user_id, created_at DESC);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS article_management.tag_follows (
+	user_id INTEGER NOT NULL REFERENCES article_management.users (id) ON DELETE CASCADE,
+	tag_id INTEGER NOT NULL REFERENCES article_management.tags (id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (user_id, tag_id)
+);
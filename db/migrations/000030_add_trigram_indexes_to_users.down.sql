@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS article_management.users_bio_trgm_idx;
+DROP INDEX IF EXISTS article_management.users_name_trgm_idx;
+DROP INDEX IF EXISTS article_management.users_username_trgm_idx;
@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- profiles are searched by fuzzy matches of words in username, name and bio
+CREATE INDEX IF NOT EXISTS users_username_trgm_idx
+	ON article_management.users USING GIN (username gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS users_name_trgm_idx
+	ON article_management.users USING GIN (name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS users_bio_trgm_idx
+	ON article_management.users USING GIN (bio gin_trgm_ops);
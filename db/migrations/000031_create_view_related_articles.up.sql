@@ -0,0 +1,59 @@
+-- articles are related by tags they share and by users who favorited both of them,
+-- the view is refreshed periodically rather than on every write
+--
+-- pairs of articles sharing a tag, or favorited by the same user, grow with the square of
+-- articles of the tag or favorites of the user, so only the newest of them are paired and
+-- only the most related articles of each article are kept
+CREATE MATERIALIZED VIEW IF NOT EXISTS article_management.related_articles AS
+WITH recent_tags AS (
+	SELECT article_id, tag_id
+	FROM (
+		SELECT at.article_id, at.tag_id,
+		ROW_NUMBER() OVER (PARTITION BY at.tag_id ORDER BY at.article_id DESC) AS rank
+		FROM article_management.article_tags at
+	) ranked
+	WHERE rank <= 100
+),
+recent_favorites AS (
+	SELECT article_id, user_id
+	FROM (
+		SELECT fa.article_id, fa.user_id,
+		ROW_NUMBER() OVER (PARTITION BY fa.user_id ORDER BY fa.article_id DESC) AS rank
+		FROM article_management.favorite_articles fa
+	) ranked
+	WHERE rank <= 100
+),
+shared_tags AS (
+	SELECT at1.article_id, at2.article_id AS related_article_id, COUNT(*) AS shared_tags_count
+	FROM recent_tags at1
+	INNER JOIN recent_tags at2 ON at2.tag_id = at1.tag_id AND at2.article_id <> at1.article_id
+	GROUP BY at1.article_id, at2.article_id
+),
+co_favorites AS (
+	SELECT fa1.article_id, fa2.article_id AS related_article_id, COUNT(*) AS co_favorites_count
+	FROM recent_favorites fa1
+	INNER JOIN recent_favorites fa2 ON fa2.user_id = fa1.user_id AND fa2.article_id <> fa1.article_id
+	GROUP BY fa1.article_id, fa2.article_id
+),
+scored AS (
+	SELECT
+		COALESCE(st.article_id, cf.article_id) AS article_id,
+		COALESCE(st.related_article_id, cf.related_article_id) AS related_article_id,
+		COALESCE(st.shared_tags_count, 0) * 2 + COALESCE(cf.co_favorites_count, 0) AS score
+	FROM shared_tags st
+	FULL OUTER JOIN co_favorites cf ON cf.article_id = st.article_id AND cf.related_article_id = st.related_article_id
+)
+SELECT article_id, related_article_id, score
+FROM (
+	SELECT s.*,
+	ROW_NUMBER() OVER (PARTITION BY s.article_id ORDER BY s.score DESC, s.related_article_id DESC) AS rank
+	FROM scored s
+) ranked
+WHERE rank <= 50;
+
+-- a unique index lets the view be refreshed concurrently with reads
+CREATE UNIQUE INDEX IF NOT EXISTS related_articles_article_id_related_article_id_idx
+	ON article_management.related_articles (article_id, related_article_id);
+
+CREATE INDEX IF NOT EXISTS related_articles_article_id_score_idx
+	ON article_management.related_articles (article_id, score DESC);
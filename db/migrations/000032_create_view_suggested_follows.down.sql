@@ -0,0 +1 @@
+DROP MATERIALIZED VIEW IF EXISTS article_management.suggested_follows;
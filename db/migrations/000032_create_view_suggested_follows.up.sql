@@ -0,0 +1,27 @@
+-- users are suggested to follow users followed by users they follow, and authors of
+-- articles they favorited, the view is refreshed periodically rather than on every write
+CREATE MATERIALIZED VIEW IF NOT EXISTS article_management.suggested_follows AS
+WITH candidates AS (
+	SELECT f1.from_user_id AS user_id, f2.to_user_id AS suggested_user_id, 2 AS weight
+	FROM article_management.follows f1
+	INNER JOIN article_management.follows f2 ON f2.from_user_id = f1.to_user_id
+	UNION ALL
+	SELECT fa.user_id, a.user_id AS suggested_user_id, 1 AS weight
+	FROM article_management.favorite_articles fa
+	INNER JOIN article_management.articles a ON a.id = fa.article_id
+)
+SELECT c.user_id, c.suggested_user_id, SUM(c.weight) AS score
+FROM candidates c
+WHERE c.user_id <> c.suggested_user_id
+AND NOT EXISTS (
+	SELECT 1 FROM article_management.follows f
+	WHERE f.from_user_id = c.user_id AND f.to_user_id = c.suggested_user_id
+)
+GROUP BY c.user_id, c.suggested_user_id;
+
+-- a unique index lets the view be refreshed concurrently with reads
+CREATE UNIQUE INDEX IF NOT EXISTS suggested_follows_user_id_suggested_user_id_idx
+	ON article_management.suggested_follows (user_id, suggested_user_id);
+
+CREATE INDEX IF NOT EXISTS suggested_follows_user_id_score_idx
+	ON article_management.suggested_follows (user_id, score DESC);
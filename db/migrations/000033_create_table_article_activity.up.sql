@@ -0,0 +1,12 @@
+-- activity on articles is counted in hourly buckets, which trending scores are computed from
+CREATE TABLE IF NOT EXISTS article_management.article_activity (
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	bucket_start TIMESTAMPTZ NOT NULL,
+	views INTEGER NOT NULL DEFAULT 0,
+	comments INTEGER NOT NULL DEFAULT 0,
+	favorites INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (article_id, bucket_start)
+);
+
+CREATE INDEX IF NOT EXISTS article_activity_bucket_start_idx
+	ON article_management.article_activity (bucket_start);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS article_management.trending_scores (
+	trending_window VARCHAR(8) NOT NULL,
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	score DOUBLE PRECISION NOT NULL,
+	computed_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (trending_window, article_id)
+);
+
+CREATE INDEX IF NOT EXISTS trending_scores_trending_window_score_idx
+	ON article_management.trending_scores (trending_window, score DESC, article_id DESC);
@@ -0,0 +1,11 @@
+-- viewers of articles in each hourly bucket of activity, so that a viewer counts once per bucket
+CREATE TABLE IF NOT EXISTS article_management.article_views (
+	article_id INTEGER NOT NULL REFERENCES article_management.articles (id) ON DELETE CASCADE,
+	bucket_start TIMESTAMPTZ NOT NULL,
+	-- id of the user, or hash of the address of an anonymous viewer
+	viewer VARCHAR(64) NOT NULL,
+	PRIMARY KEY (article_id, bucket_start, viewer)
+);
+
+CREATE INDEX IF NOT EXISTS article_views_bucket_start_idx
+	ON article_management.article_views (bucket_start);
@@ -0,0 +1,258 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/handler"
+	"github.com/nathanbizkit/article-management-go/mail"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultInterval is how often due digests are sent, digests cover whole intervals
+	DefaultInterval = time.Hour
+	// DefaultBatchSize is the number of digests claimed at a time
+	DefaultBatchSize = 50
+	// MaxArticles is the number of articles listed in a digest, others are counted
+	MaxArticles = 20
+)
+
+//go:embed templates/*
+var templates embed.FS
+
+var (
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templates, "templates/digest.html.tmpl"))
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templates, "templates/digest.txt.tmpl"))
+)
+
+// Data is what a digest is rendered from
+type Data struct {
+	Name           string
+	Frequency      string
+	Articles       []Article
+	TotalCount     int64
+	MoreCount      int64
+	UnsubscribeURL string
+}
+
+// Article is an article listed in a digest
+type Article struct {
+	Title       string
+	Description string
+	Author      string
+	URL         string
+}
+
+// Job sends email digests of new articles of followed authors and tags to subscribed users in the background
+type Job struct {
+	logger  *zerolog.Logger
+	ds      *store.DigestStore
+	us      *store.UserStore
+	as      *store.ArticleStore
+	mailer  mail.Mailer
+	baseURL string
+}
+
+// NewJob returns a new digest job with logger, stores, mailer and base url of links in emails
+func NewJob(l *zerolog.Logger, ds *store.DigestStore, us *store.UserStore, as *store.ArticleStore, mailer mail.Mailer, baseURL string) *Job {
+	return &Job{
+		logger:  l,
+		ds:      ds,
+		us:      us,
+		as:      as,
+		mailer:  mailer,
+		baseURL: baseURL,
+	}
+}
+
+// Run sends due digests right away and then every interval until ctx is done
+func (j *Job) Run(ctx context.Context, interval time.Duration) {
+	j.logger.Info().Msg("starting digest job...")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := j.SendDue(ctx, time.Now())
+		if err != nil {
+			j.logger.Error().Err(err).Msg("failed to send digests")
+		} else if sent != 0 {
+			j.logger.Info().Int("sent", sent).Msg("sent digests")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SendDue sends digests due by now, covering periods up to the start of its hour,
+// and returns how many of them were sent
+//
+// Last sent time of a digest only moves once it is sent, or once there is nothing to send,
+// so that one failing to be sent is claimed again by the next run covering a longer period.
+func (j *Job) SendDue(ctx context.Context, now time.Time) (int, error) {
+	until := now.Truncate(DefaultInterval)
+
+	// failed digests stay claimed until this run is done, so that they are not claimed again by it
+	failed := make([]model.DigestSubscription, 0)
+	defer func() {
+		for i := range failed {
+			err := j.ds.ReleaseClaim(ctx, &failed[i])
+			if err != nil {
+				j.logger.Error().Err(err).Uint("user_id", failed[i].UserID).Msg("failed to release digest")
+			}
+		}
+	}()
+
+	var sent int
+	for ctx.Err() == nil {
+		subs, err := j.ds.ClaimDue(ctx, until, DefaultBatchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for i := range subs {
+			ok, err := j.send(ctx, &subs[i], until)
+			if err == nil {
+				err = j.ds.MarkSent(ctx, &subs[i], until)
+			}
+			if err != nil {
+				j.logger.Error().Err(err).Uint("user_id", subs[i].UserID).Msg("failed to send digest")
+				failed = append(failed, subs[i])
+				continue
+			}
+
+			if ok {
+				sent++
+			}
+		}
+
+		if len(subs) < DefaultBatchSize {
+			break
+		}
+	}
+
+	return sent, nil
+}
+
+// send sends a digest of articles created since last sent time of subscription until the time,
+// and returns false when there is none to send
+func (j *Job) send(ctx context.Context, sub *model.DigestSubscription, until time.Time) (bool, error) {
+	user, err := j.us.GetByID(ctx, sub.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	filter, err := j.feedFilter(ctx, user)
+	if err != nil || (len(filter.UserIDs) == 0 && len(filter.TagIDs) == 0) {
+		return false, err
+	}
+
+	articles, totalCount, err := j.as.GetDigestArticles(ctx, filter, sub.LastSentAt, until, MaxArticles)
+	if err != nil || len(articles) == 0 {
+		return false, err
+	}
+
+	msg, err := Render(j.NewData(user, sub, articles, totalCount))
+	if err != nil {
+		return false, err
+	}
+
+	msg.To = user.Email
+
+	err = j.mailer.Send(ctx, msg)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// feedFilter returns filter of the feed of the user, made of followed users and followed tags
+func (j *Job) feedFilter(ctx context.Context, user *model.User) (model.FeedFilter, error) {
+	filter := model.FeedFilter{Source: model.FeedSourceAll, Viewer: user}
+
+	userIDs, err := j.us.GetFeedUserIDs(ctx, user)
+	if err != nil {
+		return filter, err
+	}
+
+	tags, err := j.as.GetFollowedTags(ctx, user)
+	if err != nil {
+		return filter, err
+	}
+
+	filter.UserIDs = userIDs
+	filter.TagIDs = make([]uint, 0, len(tags))
+	for _, t := range tags {
+		filter.TagIDs = append(filter.TagIDs, t.ID)
+	}
+
+	return filter, nil
+}
+
+// NewData returns data of a digest of articles out of the total count of new articles for the user
+func (j *Job) NewData(user *model.User, sub *model.DigestSubscription, articles []model.Article, totalCount int64) Data {
+	data := Data{
+		Name:           user.Name,
+		Frequency:      sub.Frequency,
+		Articles:       make([]Article, 0, len(articles)),
+		TotalCount:     totalCount,
+		MoreCount:      totalCount - int64(len(articles)),
+		UnsubscribeURL: fmt.Sprintf("%s%s/digest/unsubscribe?token=%s", j.baseURL, handler.APIGroupPath, url.QueryEscape(sub.UnsubscribeToken)),
+	}
+
+	for _, a := range articles {
+		data.Articles = append(data.Articles, Article{
+			Title:       a.Title,
+			Description: a.Description,
+			Author:      a.Author.Username,
+			URL:         fmt.Sprintf("%s%s/articles/%s", j.baseURL, handler.APIGroupPath, strconv.FormatUint(uint64(a.ID), 10)),
+		})
+	}
+
+	return data
+}
+
+// Render renders a digest as an email with html and text bodies, and one-click unsubscribe headers
+func Render(data Data) (*mail.Message, error) {
+	var html, text bytes.Buffer
+
+	err := htmlTemplate.Execute(&html, data)
+	if err != nil {
+		return nil, err
+	}
+
+	err = textTemplate.Execute(&text, data)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := fmt.Sprintf("Your %s digest: %d new articles", data.Frequency, data.TotalCount)
+	if data.TotalCount == 1 {
+		subject = fmt.Sprintf("Your %s digest: 1 new article", data.Frequency)
+	}
+
+	return &mail.Message{
+		Subject: subject,
+		Text:    text.String(),
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      fmt.Sprintf("<%s>", data.UnsubscribeURL),
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
@@ -0,0 +1,254 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/mail"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingMailer keeps messages instead of sending them
+type recordingMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *recordingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, *msg)
+	return nil
+}
+
+// sentTo returns messages sent to the address
+func (m *recordingMailer) sentTo(address string) []mail.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]mail.Message, 0)
+	for _, msg := range m.messages {
+		if msg.To == address {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// failingMailer fails to send every message
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg *mail.Message) error {
+	return errors.New("mail server is down")
+}
+
+func TestUnit_Render(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	msg, err := Render(Data{
+		Name:      "foo <user>",
+		Frequency: model.DigestFrequencyDaily,
+		Articles: []Article{
+			{
+				Title:       "title & more",
+				Description: "description",
+				Author:      "bar_user",
+				URL:         "https://example.com/api/v1/articles/1",
+			},
+		},
+		TotalCount:     3,
+		MoreCount:      2,
+		UnsubscribeURL: "https://example.com/api/v1/digest/unsubscribe?token=abc",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "Your daily digest: 3 new articles", msg.Subject)
+	assert.Equal(t, "<https://example.com/api/v1/digest/unsubscribe?token=abc>", msg.Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Headers["List-Unsubscribe-Post"])
+
+	assert.Contains(t, msg.Text, "Hi foo <user>,")
+	assert.Contains(t, msg.Text, "3 new articles from authors and tags you follow")
+	assert.Contains(t, msg.Text, "title & more\nby bar_user\ndescription\nhttps://example.com/api/v1/articles/1")
+	assert.Contains(t, msg.Text, "...and 2 more in your feed.")
+	assert.Contains(t, msg.Text, "Unsubscribe: https://example.com/api/v1/digest/unsubscribe?token=abc")
+
+	// html is escaped
+	assert.Contains(t, msg.HTML, "Hi foo &lt;user&gt;,")
+	assert.Contains(t, msg.HTML, "title &amp; more")
+	assert.Contains(t, msg.HTML, `href="https://example.com/api/v1/articles/1"`)
+	assert.Contains(t, msg.HTML, `href="https://example.com/api/v1/digest/unsubscribe?token=abc"`)
+}
+
+func TestIntegration_Job(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	l := test.NewTestLogger(t)
+	lct := test.NewLocalTestContainer(t)
+
+	us := store.NewUserStore(lct.DB())
+	as := store.NewArticleStore(lct.DB())
+	ds := store.NewDigestStore(lct.DB())
+
+	users := make([]*model.User, 0, 3)
+	for i := 0; i < 3; i++ {
+		randStr := test.RandomString(t, 10)
+		user, err := us.Create(context.Background(), &model.User{
+			Username: fmt.Sprintf("user_%s", randStr),
+			Email:    fmt.Sprintf("%s@example.com", randStr),
+			Password: "P@55w0rD!",
+			Name:     "USER",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_, err := lct.DB().Exec(`DELETE FROM article_management.users WHERE id = $1`, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		users = append(users, user)
+	}
+
+	reader, author, tagAuthor := users[0], users[1], users[2]
+
+	err := us.Follow(context.Background(), reader, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := model.NewDigestSubscription(reader.ID, model.DigestFrequencyDaily)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sub, err := ds.Subscribe(context.Background(), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// last digest was sent two days ago
+	_, err = lct.DB().Exec(`UPDATE article_management.digest_subscriptions 
+		SET last_sent_at = $1 WHERE user_id = $2`, time.Now().Add(-48*time.Hour), reader.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	article, err := as.Create(context.Background(), &model.Article{
+		Title:       "digest article",
+		Description: "digest article",
+		Body:        "digest article",
+		UserID:      author.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// article of a followed tag written by an author the reader does not follow
+	tagArticle, err := as.Create(context.Background(), &model.Article{
+		Title:       "tagged digest article",
+		Description: "tagged digest article",
+		Body:        "tagged digest article",
+		UserID:      tagAuthor.ID,
+		Tags:        []model.Tag{{Name: test.RandomString(t, 10)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = as.FollowTag(context.Background(), &tagArticle.Tags[0], reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mailer := &recordingMailer{}
+	job := NewJob(&l, ds, us, as, mailer, "https://example.com")
+
+	// the next hour covers the article created now
+	now := time.Now().Add(time.Hour)
+
+	t.Run("SendDue: digest failing to be sent is not skipped", func(t *testing.T) {
+		before, err := ds.GetByUser(context.Background(), reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failingJob := NewJob(&l, ds, us, as, failingMailer{}, "https://example.com")
+
+		sent, err := failingJob.SendDue(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 0, sent)
+
+		after, err := ds.GetByUser(context.Background(), reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, after.LastSentAt.Equal(before.LastSentAt))
+	})
+
+	t.Run("SendDue: digest of new articles is sent", func(t *testing.T) {
+		_, err := job.SendDue(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		messages := mailer.sentTo(reader.Email)
+		if !assert.Len(t, messages, 1) {
+			return
+		}
+
+		assert.Equal(t, "Your daily digest: 2 new articles", messages[0].Subject)
+		assert.Contains(t, messages[0].Text, fmt.Sprintf("https://example.com/api/v1/articles/%d", article.ID))
+		assert.Contains(t, messages[0].Text, fmt.Sprintf("https://example.com/api/v1/articles/%d", tagArticle.ID))
+		assert.Equal(t,
+			fmt.Sprintf("<https://example.com/api/v1/digest/unsubscribe?token=%s>", sub.UnsubscribeToken),
+			messages[0].Headers["List-Unsubscribe"],
+		)
+		assert.Contains(t, messages[0].HTML, article.Title)
+
+		next, err := ds.GetByUser(context.Background(), reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.True(t, next.LastSentAt.Equal(now.Truncate(DefaultInterval)))
+	})
+
+	t.Run("SendDue: digest is not sent again before its period", func(t *testing.T) {
+		_, err := job.SendDue(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Len(t, mailer.sentTo(reader.Email), 1)
+	})
+
+	t.Run("SendDue: digest without new articles is not sent", func(t *testing.T) {
+		_, err := job.SendDue(context.Background(), now.Add(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Len(t, mailer.sentTo(reader.Email), 1)
+	})
+}
@@ -1,6 +1,11 @@
 package env
 
 import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"time"
+
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/go-ozzo/ozzo-validation/is"
 	"github.com/spf13/viper"
@@ -8,18 +13,42 @@ import (
 
 // ENV definition
 type ENV struct {
-	AppMode            string   `mapstructure:"APP_MODE"`
-	AppPort            string   `mapstructure:"APP_PORT"`
-	AppTLSPort         string   `mapstructure:"APP_TLS_PORT"`
-	TLSCertFile        string   `mapstructure:"TLS_CERT_FILE"`
-	TLSKeyFile         string   `mapstructure:"TLS_KEY_FILE"`
-	CORSAllowedOrigins []string `mapstructure:"CORS_ALLOWED_ORIGINS"`
-	AuthJWTSecretKey   string   `mapstructure:"AUTH_JWT_SECRET_KEY"`
-	DBUser             string   `mapstructure:"DB_USER"`
-	DBPass             string   `mapstructure:"DB_PASS"`
-	DBHost             string   `mapstructure:"DB_HOST"`
-	DBPort             string   `mapstructure:"DB_PORT"`
-	DBName             string   `mapstructure:"DB_NAME"`
+	AppMode            string        `mapstructure:"APP_MODE"`
+	AppPort            string        `mapstructure:"APP_PORT"`
+	AppTLSPort         string        `mapstructure:"APP_TLS_PORT"`
+	AppBaseURL         string        `mapstructure:"APP_BASE_URL"`
+	TLSCertFile        string        `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile         string        `mapstructure:"TLS_KEY_FILE"`
+	CORSAllowedOrigins []string      `mapstructure:"CORS_ALLOWED_ORIGINS"`
+	AuthJWTSecretKey   string        `mapstructure:"AUTH_JWT_SECRET_KEY"`
+	DBUser             string        `mapstructure:"DB_USER"`
+	DBPass             string        `mapstructure:"DB_PASS"`
+	DBHost             string        `mapstructure:"DB_HOST"`
+	DBPort             string        `mapstructure:"DB_PORT"`
+	DBName             string        `mapstructure:"DB_NAME"`
+	StorageDriver      string        `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalDir    string        `mapstructure:"STORAGE_LOCAL_DIR"`
+	S3Endpoint         string        `mapstructure:"S3_ENDPOINT"`
+	S3Region           string        `mapstructure:"S3_REGION"`
+	S3Bucket           string        `mapstructure:"S3_BUCKET"`
+	S3AccessKey        string        `mapstructure:"S3_ACCESS_KEY"`
+	S3SecretKey        string        `mapstructure:"S3_SECRET_KEY"`
+	S3UseSSL           bool          `mapstructure:"S3_USE_SSL"`
+	MediaMaxSize       int64         `mapstructure:"MEDIA_MAX_SIZE"`
+	CommentMaxDepth    int           `mapstructure:"COMMENT_MAX_DEPTH"`
+	CommentEditWindow  time.Duration `mapstructure:"COMMENT_EDIT_WINDOW"`
+	Moderators         []string      `mapstructure:"MODERATORS"`
+	Reactions          []string      `mapstructure:"REACTIONS"`
+	StreamHeartbeat    time.Duration `mapstructure:"STREAM_HEARTBEAT"`
+	StreamRetention    time.Duration `mapstructure:"STREAM_RETENTION"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	MailDriver         string        `mapstructure:"MAIL_DRIVER"`
+	MailFrom           string        `mapstructure:"MAIL_FROM"`
+	SMTPHost           string        `mapstructure:"SMTP_HOST"`
+	SMTPPort           string        `mapstructure:"SMTP_PORT"`
+	SMTPUser           string        `mapstructure:"SMTP_USER"`
+	SMTPPass           string        `mapstructure:"SMTP_PASS"`
 	TLSEnabled         bool
 	IsDevelopment      bool
 }
@@ -46,6 +75,7 @@ func Parse(envFile string) (*ENV, error) {
 	viper.SetDefault("APP_MODE", "develop")
 	viper.SetDefault("APP_PORT", "8000")
 	viper.SetDefault("APP_TLS_PORT", "8443")
+	viper.SetDefault("APP_BASE_URL", "http://localhost:8000")
 	viper.SetDefault("TLS_CERT_FILE", "")
 	viper.SetDefault("TLS_KEY_FILE", "")
 	viper.SetDefault("CORS_ALLOWED_ORIGINS", "*")
@@ -55,6 +85,29 @@ func Parse(envFile string) (*ENV, error) {
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_NAME", "")
+	viper.SetDefault("STORAGE_DRIVER", "local")
+	viper.SetDefault("STORAGE_LOCAL_DIR", "media")
+	viper.SetDefault("S3_ENDPOINT", "")
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "")
+	viper.SetDefault("S3_ACCESS_KEY", "")
+	viper.SetDefault("S3_SECRET_KEY", "")
+	viper.SetDefault("S3_USE_SSL", true)
+	viper.SetDefault("MEDIA_MAX_SIZE", 5<<20)
+	viper.SetDefault("COMMENT_MAX_DEPTH", 5)
+	viper.SetDefault("COMMENT_EDIT_WINDOW", "15m")
+	viper.SetDefault("MODERATORS", "")
+	viper.SetDefault("REACTIONS", "thumbs_up,thumbs_down,laugh,hooray,confused,heart,rocket,eyes")
+	viper.SetDefault("STREAM_HEARTBEAT", "15s")
+	viper.SetDefault("STREAM_RETENTION", "24h")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("MAIL_DRIVER", "log")
+	viper.SetDefault("MAIL_FROM", "no-reply@localhost")
+	viper.SetDefault("SMTP_HOST", "localhost")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USER", "")
+	viper.SetDefault("SMTP_PASS", "")
 
 	environ := ENV{}
 	err := viper.Unmarshal(&environ)
@@ -75,6 +128,11 @@ func Parse(envFile string) (*ENV, error) {
 			&environ.AppTLSPort,
 			is.Digit,
 		),
+		validation.Field(
+			&environ.AppBaseURL,
+			validation.Required,
+			is.URL,
+		),
 		validation.Field(
 			&environ.AuthJWTSecretKey,
 			validation.Required,
@@ -91,6 +149,90 @@ func Parse(envFile string) (*ENV, error) {
 			&environ.DBName,
 			validation.Required,
 		),
+		validation.Field(
+			&environ.StorageDriver,
+			validation.In("local", "s3"),
+		),
+		validation.Field(
+			&environ.StorageLocalDir,
+			validation.By(requiredForDriver(environ.StorageDriver, "local")),
+		),
+		validation.Field(
+			&environ.S3Endpoint,
+			validation.By(requiredForDriver(environ.StorageDriver, "s3")),
+		),
+		validation.Field(
+			&environ.S3Bucket,
+			validation.By(requiredForDriver(environ.StorageDriver, "s3")),
+		),
+		validation.Field(
+			&environ.S3AccessKey,
+			validation.By(requiredForDriver(environ.StorageDriver, "s3")),
+		),
+		validation.Field(
+			&environ.S3SecretKey,
+			validation.By(requiredForDriver(environ.StorageDriver, "s3")),
+		),
+		validation.Field(
+			&environ.MediaMaxSize,
+			validation.Min(int64(1)),
+		),
+		validation.Field(
+			&environ.CommentMaxDepth,
+			validation.Min(1),
+		),
+		validation.Field(
+			&environ.CommentEditWindow,
+			validation.Min(time.Second),
+		),
+		validation.Field(
+			&environ.Reactions,
+			validation.Required,
+			validation.By(func(value interface{}) error {
+				for _, r := range environ.Reactions {
+					if !reactionPattern.MatchString(r) {
+						return fmt.Errorf("%q must be lowercase letters, digits or underscores (up to 32)", r)
+					}
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&environ.StreamHeartbeat,
+			validation.Min(time.Second),
+		),
+		validation.Field(
+			&environ.StreamRetention,
+			validation.Min(time.Minute),
+		),
+		validation.Field(
+			&environ.WebhookTimeout,
+			validation.Min(time.Second),
+		),
+		validation.Field(
+			&environ.WebhookMaxAttempts,
+			validation.Min(1),
+		),
+		validation.Field(
+			&environ.MailDriver,
+			validation.In("log", "smtp"),
+		),
+		validation.Field(
+			&environ.MailFrom,
+			validation.Required,
+			validation.By(func(value interface{}) error {
+				_, err := mail.ParseAddress(environ.MailFrom)
+				return err
+			}),
+		),
+		validation.Field(
+			&environ.SMTPHost,
+			validation.By(requiredForDriver(environ.MailDriver, "smtp")),
+		),
+		validation.Field(
+			&environ.SMTPPort,
+			is.Digit,
+		),
 	)
 	if err != nil {
 		return nil, err
@@ -117,3 +259,16 @@ func Parse(envFile string) (*ENV, error) {
 
 	return &environ, nil
 }
+
+// reactionPattern matches names of reactions, which are used in urls
+var reactionPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// requiredForDriver returns a rule requiring a value when driver (of storage or mail) is in use
+func requiredForDriver(driver, expected string) validation.RuleFunc {
+	return func(value interface{}) error {
+		if driver != expected {
+			return nil
+		}
+		return validation.Validate(value, validation.Required)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -60,20 +61,38 @@ func TestUnit_ENV(t *testing.T) {
 					AppMode:     "dev",
 					AppPort:     "8000",
 					AppTLSPort:  "8443",
+					AppBaseURL:  "http://localhost:8000",
 					TLSCertFile: "/certs/localCA.pem",
 					TLSKeyFile:  "/certs/localCA_unencrypted.key",
 					CORSAllowedOrigins: []string{
 						"http://localhost:8000",
 						"https://localhost:8443",
 					},
-					AuthJWTSecretKey: "secret",
-					DBUser:           "root",
-					DBPass:           "password",
-					DBHost:           "db",
-					DBPort:           "5432",
-					DBName:           "app",
-					TLSEnabled:       true,
-					IsDevelopment:    true,
+					AuthJWTSecretKey:   "secret",
+					DBUser:             "root",
+					DBPass:             "password",
+					DBHost:             "db",
+					DBPort:             "5432",
+					DBName:             "app",
+					StorageDriver:      "local",
+					StorageLocalDir:    "media",
+					S3Region:           "us-east-1",
+					S3UseSSL:           true,
+					MediaMaxSize:       5 << 20,
+					CommentMaxDepth:    5,
+					CommentEditWindow:  15 * time.Minute,
+					Moderators:         []string{},
+					Reactions:          []string{"thumbs_up", "thumbs_down", "laugh", "hooray", "confused", "heart", "rocket", "eyes"},
+					StreamHeartbeat:    15 * time.Second,
+					StreamRetention:    24 * time.Hour,
+					WebhookTimeout:     10 * time.Second,
+					WebhookMaxAttempts: 8,
+					MailDriver:         "log",
+					MailFrom:           "no-reply@localhost",
+					SMTPHost:           "localhost",
+					SMTPPort:           "587",
+					TLSEnabled:         true,
+					IsDevelopment:      true,
 				},
 				false,
 			},
@@ -101,20 +120,38 @@ func TestUnit_ENV(t *testing.T) {
 					AppMode:     "dev",
 					AppPort:     "8000",
 					AppTLSPort:  "8443",
+					AppBaseURL:  "http://localhost:8000",
 					TLSCertFile: "/certs/localCA.pem",
 					TLSKeyFile:  "/certs/localCA_unencrypted.key",
 					CORSAllowedOrigins: []string{
 						"http://localhost:8000",
 						"https://localhost:8443",
 					},
-					AuthJWTSecretKey: "secret",
-					DBUser:           "root",
-					DBPass:           "password",
-					DBHost:           "db",
-					DBPort:           "5432",
-					DBName:           "app",
-					TLSEnabled:       true,
-					IsDevelopment:    true,
+					AuthJWTSecretKey:   "secret",
+					DBUser:             "root",
+					DBPass:             "password",
+					DBHost:             "db",
+					DBPort:             "5432",
+					DBName:             "app",
+					StorageDriver:      "local",
+					StorageLocalDir:    "media",
+					S3Region:           "us-east-1",
+					S3UseSSL:           true,
+					MediaMaxSize:       5 << 20,
+					CommentMaxDepth:    5,
+					CommentEditWindow:  15 * time.Minute,
+					Moderators:         []string{},
+					Reactions:          []string{"thumbs_up", "thumbs_down", "laugh", "hooray", "confused", "heart", "rocket", "eyes"},
+					StreamHeartbeat:    15 * time.Second,
+					StreamRetention:    24 * time.Hour,
+					WebhookTimeout:     10 * time.Second,
+					WebhookMaxAttempts: 8,
+					MailDriver:         "log",
+					MailFrom:           "no-reply@localhost",
+					SMTPHost:           "localhost",
+					SMTPPort:           "587",
+					TLSEnabled:         true,
+					IsDevelopment:      true,
 				},
 				false,
 			},
@@ -139,6 +176,7 @@ func TestUnit_ENV(t *testing.T) {
 					AppMode:            "dev",
 					AppPort:            "8000",
 					AppTLSPort:         "8443",
+					AppBaseURL:         "http://localhost:8000",
 					TLSCertFile:        "/certs/localCA.pem",
 					TLSKeyFile:         "/certs/localCA_unencrypted.key",
 					CORSAllowedOrigins: []string{},
@@ -148,6 +186,23 @@ func TestUnit_ENV(t *testing.T) {
 					DBHost:             "db",
 					DBPort:             "5432",
 					DBName:             "app",
+					StorageDriver:      "local",
+					StorageLocalDir:    "media",
+					S3Region:           "us-east-1",
+					S3UseSSL:           true,
+					MediaMaxSize:       5 << 20,
+					CommentMaxDepth:    5,
+					CommentEditWindow:  15 * time.Minute,
+					Moderators:         []string{},
+					Reactions:          []string{"thumbs_up", "thumbs_down", "laugh", "hooray", "confused", "heart", "rocket", "eyes"},
+					StreamHeartbeat:    15 * time.Second,
+					StreamRetention:    24 * time.Hour,
+					WebhookTimeout:     10 * time.Second,
+					WebhookMaxAttempts: 8,
+					MailDriver:         "log",
+					MailFrom:           "no-reply@localhost",
+					SMTPHost:           "localhost",
+					SMTPPort:           "587",
 					TLSEnabled:         true,
 					IsDevelopment:      true,
 				},
@@ -314,6 +369,134 @@ func TestUnit_ENV(t *testing.T) {
 				nil,
 				true,
 			},
+			{
+				"parse: s3 storage",
+				"",
+				func(t *testing.T) {
+					t.Setenv("APP_MODE", "prod")
+					t.Setenv("APP_BASE_URL", "https://articles.example.com")
+					t.Setenv("AUTH_JWT_SECRET_KEY", "secret")
+					t.Setenv("DB_USER", "root")
+					t.Setenv("DB_PASS", "password")
+					t.Setenv("DB_NAME", "app")
+					t.Setenv("STORAGE_DRIVER", "s3")
+					t.Setenv("S3_ENDPOINT", "s3.example.com")
+					t.Setenv("S3_BUCKET", "media")
+					t.Setenv("S3_ACCESS_KEY", "access")
+					t.Setenv("S3_SECRET_KEY", "secret")
+					t.Setenv("MEDIA_MAX_SIZE", "1024")
+					t.Setenv("COMMENT_MAX_DEPTH", "3")
+					t.Setenv("COMMENT_EDIT_WINDOW", "1h")
+					t.Setenv("MODERATORS", "foo_user,bar_user")
+					t.Setenv("REACTIONS", "like,heart")
+					t.Setenv("STREAM_HEARTBEAT", "30s")
+					t.Setenv("STREAM_RETENTION", "1h")
+					t.Setenv("WEBHOOK_TIMEOUT", "5s")
+					t.Setenv("WEBHOOK_MAX_ATTEMPTS", "3")
+					t.Setenv("MAIL_DRIVER", "smtp")
+					t.Setenv("MAIL_FROM", "Articles <news@example.com>")
+					t.Setenv("SMTP_HOST", "smtp.example.com")
+					t.Setenv("SMTP_PORT", "2525")
+					t.Setenv("SMTP_USER", "user")
+					t.Setenv("SMTP_PASS", "pass")
+				},
+				&ENV{
+					AppMode:            "prod",
+					AppPort:            "8000",
+					AppTLSPort:         "8443",
+					AppBaseURL:         "https://articles.example.com",
+					CORSAllowedOrigins: []string{},
+					AuthJWTSecretKey:   "secret",
+					DBUser:             "root",
+					DBPass:             "password",
+					DBHost:             "localhost",
+					DBPort:             "5432",
+					DBName:             "app",
+					StorageDriver:      "s3",
+					StorageLocalDir:    "media",
+					S3Endpoint:         "s3.example.com",
+					S3Region:           "us-east-1",
+					S3Bucket:           "media",
+					S3AccessKey:        "access",
+					S3SecretKey:        "secret",
+					S3UseSSL:           true,
+					MediaMaxSize:       1024,
+					CommentMaxDepth:    3,
+					CommentEditWindow:  time.Hour,
+					Moderators:         []string{"foo_user", "bar_user"},
+					Reactions:          []string{"like", "heart"},
+					StreamHeartbeat:    30 * time.Second,
+					StreamRetention:    time.Hour,
+					WebhookTimeout:     5 * time.Second,
+					WebhookMaxAttempts: 3,
+					MailDriver:         "smtp",
+					MailFrom:           "Articles <news@example.com>",
+					SMTPHost:           "smtp.example.com",
+					SMTPPort:           "2525",
+					SMTPUser:           "user",
+					SMTPPass:           "pass",
+				},
+				false,
+			},
+			{
+				"parse: s3 storage without bucket",
+				"",
+				func(t *testing.T) {
+					t.Setenv("APP_MODE", "dev")
+					t.Setenv("AUTH_JWT_SECRET_KEY", "secret")
+					t.Setenv("DB_USER", "root")
+					t.Setenv("DB_PASS", "password")
+					t.Setenv("DB_NAME", "app")
+					t.Setenv("STORAGE_DRIVER", "s3")
+					t.Setenv("S3_ENDPOINT", "s3.example.com")
+					t.Setenv("S3_ACCESS_KEY", "access")
+					t.Setenv("S3_SECRET_KEY", "secret")
+				},
+				nil,
+				true,
+			},
+			{
+				"parse: invalid reaction",
+				"",
+				func(t *testing.T) {
+					t.Setenv("APP_MODE", "dev")
+					t.Setenv("AUTH_JWT_SECRET_KEY", "secret")
+					t.Setenv("DB_USER", "root")
+					t.Setenv("DB_PASS", "password")
+					t.Setenv("DB_NAME", "app")
+					t.Setenv("REACTIONS", "like,Thumbs Up")
+				},
+				nil,
+				true,
+			},
+			{
+				"parse: invalid mail driver",
+				"",
+				func(t *testing.T) {
+					t.Setenv("APP_MODE", "dev")
+					t.Setenv("AUTH_JWT_SECRET_KEY", "secret")
+					t.Setenv("DB_USER", "root")
+					t.Setenv("DB_PASS", "password")
+					t.Setenv("DB_NAME", "app")
+					t.Setenv("MAIL_DRIVER", "carrier_pigeon")
+				},
+				nil,
+				true,
+			},
+			{
+				"parse: invalid storage driver",
+				"",
+				func(t *testing.T) {
+					t.Setenv("APP_MODE", "dev")
+					t.Setenv("AUTH_JWT_SECRET_KEY", "secret")
+					t.Setenv("DB_USER", "root")
+					t.Setenv("DB_PASS", "password")
+					t.Setenv("DB_NAME", "app")
+					t.Setenv("STORAGE_DRIVER", "ftp")
+				},
+				nil,
+				true,
+			},
 		}
 
 		for _, tt := range tests {
@@ -340,6 +523,7 @@ func resetEnvironment(t *testing.T) {
 	t.Setenv("APP_MODE", "")
 	t.Setenv("APP_PORT", "")
 	t.Setenv("APP_TLS_PORT", "")
+	t.Setenv("APP_BASE_URL", "")
 	t.Setenv("TLS_CERT_FILE", "")
 	t.Setenv("TLS_KEY_FILE", "")
 	t.Setenv("CORS_ALLOWED_ORIGINS", "")
@@ -349,4 +533,27 @@ func resetEnvironment(t *testing.T) {
 	t.Setenv("DB_HOST", "")
 	t.Setenv("DB_PORT", "")
 	t.Setenv("DB_NAME", "")
+	t.Setenv("STORAGE_DRIVER", "")
+	t.Setenv("STORAGE_LOCAL_DIR", "")
+	t.Setenv("S3_ENDPOINT", "")
+	t.Setenv("S3_REGION", "")
+	t.Setenv("S3_BUCKET", "")
+	t.Setenv("S3_ACCESS_KEY", "")
+	t.Setenv("S3_SECRET_KEY", "")
+	t.Setenv("S3_USE_SSL", "")
+	t.Setenv("MEDIA_MAX_SIZE", "")
+	t.Setenv("COMMENT_MAX_DEPTH", "")
+	t.Setenv("COMMENT_EDIT_WINDOW", "")
+	t.Setenv("MODERATORS", "")
+	t.Setenv("REACTIONS", "")
+	t.Setenv("STREAM_HEARTBEAT", "")
+	t.Setenv("STREAM_RETENTION", "")
+	t.Setenv("WEBHOOK_TIMEOUT", "")
+	t.Setenv("WEBHOOK_MAX_ATTEMPTS", "")
+	t.Setenv("MAIL_DRIVER", "")
+	t.Setenv("MAIL_FROM", "")
+	t.Setenv("SMTP_HOST", "")
+	t.Setenv("SMTP_PORT", "")
+	t.Setenv("SMTP_USER", "")
+	t.Setenv("SMTP_PASS", "")
 }
@@ -0,0 +1,250 @@
+package feed
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Feed formats, which are also extensions of feed urls
+const (
+	FormatAtom = "atom"
+	FormatRSS  = "rss"
+)
+
+// Content types of feed formats
+const (
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+)
+
+const (
+	atomNamespace    = "http://www.w3.org/2005/Atom"
+	contentNamespace = "http://purl.org/rss/1.0/modules/content/"
+	dcNamespace      = "http://purl.org/dc/elements/1.1/"
+)
+
+var errUnknownFormat = errors.New("unknown feed format")
+
+// Feed is a list of entries which is encoded as Atom or RSS
+type Feed struct {
+	// ID identifies the feed, it is also the url of the feed itself
+	ID          string
+	Title       string
+	Description string
+	// Link is the url of what the feed lists
+	Link    string
+	Updated time.Time
+	Entries []Entry
+}
+
+// Entry is an article in a feed
+type Entry struct {
+	ID          string
+	Title       string
+	Link        string
+	Author      string
+	Summary     string
+	ContentHTML string
+	Categories  []string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// SplitName splits a feed name with an extension into its name and format
+func SplitName(name string) (string, string, error) {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 {
+		return "", "", errUnknownFormat
+	}
+
+	format := name[i+1:]
+	if format != FormatAtom && format != FormatRSS {
+		return "", "", errUnknownFormat
+	}
+
+	return name[:i], format, nil
+}
+
+// Encode encodes feed in the format and returns it with its content type
+func (f *Feed) Encode(format string) ([]byte, string, error) {
+	var v interface{}
+	var contentType string
+
+	switch format {
+	case FormatAtom:
+		v, contentType = f.atom(), ContentTypeAtom
+	case FormatRSS:
+		v, contentType = f.rss(), ContentTypeRSS
+	default:
+		return nil, "", errUnknownFormat
+	}
+
+	b, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return append([]byte(xml.Header), b...), contentType, nil
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"feed"`
+	XMLNS    string      `xml:"xmlns,attr"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Links      []atomLink     `xml:"link"`
+	Author     atomPerson     `xml:"author"`
+	Categories []atomCategory `xml:"category"`
+	Summary    atomText       `xml:"summary"`
+	Content    atomText       `xml:"content"`
+}
+
+func (f *Feed) atom() atomFeed {
+	feed := atomFeed{
+		XMLNS:    atomNamespace,
+		ID:       f.ID,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.ID},
+			{Rel: "alternate", Href: f.Link},
+		},
+		Entries: make([]atomEntry, 0, len(f.Entries)),
+	}
+
+	for _, e := range f.Entries {
+		entry := atomEntry{
+			ID:         e.ID,
+			Title:      e.Title,
+			Published:  e.Published.UTC().Format(time.RFC3339),
+			Updated:    e.Updated.UTC().Format(time.RFC3339),
+			Links:      []atomLink{{Rel: "alternate", Href: e.Link}},
+			Author:     atomPerson{Name: e.Author},
+			Categories: make([]atomCategory, 0, len(e.Categories)),
+			Summary:    atomText{Type: "text", Body: e.Summary},
+			Content:    atomText{Type: "html", Body: e.ContentHTML},
+		}
+
+		for _, c := range e.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: c})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return feed
+}
+
+type rssFeed struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type rssContent struct {
+	Value string `xml:",cdata"`
+}
+
+type rssItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link"`
+	GUID        rssGUID    `xml:"guid"`
+	PubDate     string     `xml:"pubDate"`
+	Creator     string     `xml:"dc:creator"`
+	Categories  []string   `xml:"category"`
+	Description string     `xml:"description"`
+	Content     rssContent `xml:"content:encoded"`
+}
+
+func (f *Feed) rss() rssFeed {
+	description := f.Description
+	if description == "" {
+		description = f.Title
+	}
+
+	feed := rssFeed{
+		Version:   "2.0",
+		AtomNS:    atomNamespace,
+		ContentNS: contentNamespace,
+		DCNS:      dcNamespace,
+		Channel: rssChannel{
+			Title:         f.Title,
+			Link:          f.Link,
+			Description:   description,
+			AtomLink:      rssLink{Rel: "self", Type: "application/rss+xml", Href: f.ID},
+			LastBuildDate: f.Updated.UTC().Format(time.RFC1123Z),
+			Items:         make([]rssItem, 0, len(f.Entries)),
+		},
+	}
+
+	for _, e := range f.Entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       e.Title,
+			Link:        e.Link,
+			GUID:        rssGUID{IsPermaLink: e.ID == e.Link, Value: e.ID},
+			PubDate:     e.Published.UTC().Format(time.RFC1123Z),
+			Creator:     e.Author,
+			Categories:  e.Categories,
+			Description: e.Summary,
+			Content:     rssContent{Value: e.ContentHTML},
+		})
+	}
+
+	return feed
+}
@@ -0,0 +1,147 @@
+package feed
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Feed(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	published := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	f := Feed{
+		ID:          "https://example.com/api/v1/feeds/articles.atom",
+		Title:       "Articles",
+		Description: "Newest articles",
+		Link:        "https://example.com/api/v1/articles",
+		Updated:     updated,
+		Entries: []Entry{
+			{
+				ID:          "https://example.com/api/v1/articles/1",
+				Title:       "title & more",
+				Link:        "https://example.com/api/v1/articles/1",
+				Author:      "foo_user",
+				Summary:     "description",
+				ContentHTML: "<p>body ]]> end</p>",
+				Categories:  []string{"go", "feeds"},
+				Published:   published,
+				Updated:     updated,
+			},
+		},
+	}
+
+	t.Run("SplitName", func(t *testing.T) {
+		tests := []struct {
+			title          string
+			name           string
+			expectedName   string
+			expectedFormat string
+			hasError       bool
+		}{
+			{"split name: atom", "articles.atom", "articles", FormatAtom, false},
+			{"split name: rss", "go.lang.rss", "go.lang", FormatRSS, false},
+			{"split name: unknown format", "articles.json", "", "", true},
+			{"split name: no format", "articles", "", "", true},
+			{"split name: no name", ".rss", "", "", true},
+		}
+
+		for _, tt := range tests {
+			name, format, err := SplitName(tt.name)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expectedName, name, tt.title)
+				assert.Equal(t, tt.expectedFormat, format, tt.title)
+			}
+		}
+	})
+
+	t.Run("Encode: atom", func(t *testing.T) {
+		b, contentType, err := f.Encode(FormatAtom)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, ContentTypeAtom, contentType)
+
+		var decoded atomFeed
+		err = xml.Unmarshal(b, &decoded)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, atomNamespace, decoded.XMLName.Space)
+		assert.Equal(t, f.ID, decoded.ID)
+		assert.Equal(t, "2024-01-02T08:00:00Z", decoded.Updated)
+		assert.Equal(t, []atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: f.ID},
+			{Rel: "alternate", Href: f.Link},
+		}, decoded.Links)
+
+		if assert.Len(t, decoded.Entries, 1) {
+			entry := decoded.Entries[0]
+			assert.Equal(t, "title & more", entry.Title)
+			assert.Equal(t, "foo_user", entry.Author.Name)
+			assert.Equal(t, "2024-01-01T08:00:00Z", entry.Published)
+			assert.Equal(t, []atomCategory{{Term: "go"}, {Term: "feeds"}}, entry.Categories)
+			assert.Equal(t, atomText{Type: "html", Body: "<p>body ]]> end</p>"}, entry.Content)
+		}
+	})
+
+	t.Run("Encode: rss", func(t *testing.T) {
+		b, contentType, err := f.Encode(FormatRSS)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, ContentTypeRSS, contentType)
+
+		var decoded struct {
+			Version string `xml:"version,attr"`
+			Channel struct {
+				Title         string `xml:"title"`
+				LastBuildDate string `xml:"lastBuildDate"`
+				Items         []struct {
+					Title      string   `xml:"title"`
+					GUID       string   `xml:"guid"`
+					PubDate    string   `xml:"pubDate"`
+					Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+					Categories []string `xml:"category"`
+					Content    string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+				} `xml:"item"`
+			} `xml:"channel"`
+		}
+		err = xml.Unmarshal(b, &decoded)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, "2.0", decoded.Version)
+		assert.Equal(t, "Articles", decoded.Channel.Title)
+		assert.Equal(t, "Tue, 02 Jan 2024 08:00:00 +0000", decoded.Channel.LastBuildDate)
+
+		if assert.Len(t, decoded.Channel.Items, 1) {
+			item := decoded.Channel.Items[0]
+			assert.Equal(t, "title & more", item.Title)
+			assert.Equal(t, "https://example.com/api/v1/articles/1", item.GUID)
+			assert.Equal(t, "Mon, 01 Jan 2024 08:00:00 +0000", item.PubDate)
+			assert.Equal(t, "foo_user", item.Creator)
+			assert.Equal(t, []string{"go", "feeds"}, item.Categories)
+			assert.Equal(t, "<p>body ]]> end</p>", item.Content)
+		}
+	})
+
+	t.Run("Encode: unknown format", func(t *testing.T) {
+		_, _, err := f.Encode("json")
+		assert.Error(t, err)
+	})
+}
@@ -57,6 +57,14 @@ func (h *Handler) CreateArticle(ctx *gin.Context) {
 		return
 	}
 
+	article.Mentions, err = h.resolveMentions(ctx, article.Body)
+	if err != nil {
+		msg := "failed to get mentioned users"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	createdArticle, err := h.as.Create(ctx.Request.Context(), &article)
 	if err != nil {
 		msg := "failed to create article"
@@ -65,12 +73,36 @@ func (h *Handler) CreateArticle(ctx *gin.Context) {
 		return
 	}
 
+	err = h.SetMentions(ctx, []*model.Article{createdArticle}, nil)
+	if err != nil {
+		msg := "failed to get mentions"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.renderer.RenderArticle(createdArticle)
+	if err != nil {
+		msg := "failed to render article body"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, nil, []*model.Article{createdArticle})
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	favorited := false
 	following := false
 	ctx.AbortWithStatusJSON(http.StatusOK, createdArticle.ResponseArticle(favorited, following))
 }
 
-// GetArticle gets an article
+// GetArticle gets an article, articles of private users are only for their followers
 func (h *Handler) GetArticle(ctx *gin.Context) {
 	h.logger.Info().Msg("get article")
 
@@ -101,6 +133,28 @@ func (h *Handler) GetArticle(ctx *gin.Context) {
 		}
 	}
 
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get article (id=%d) of private user", userID, slug)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	// a view failing to count must not fail getting the article
+	err = h.ts.RecordView(ctx.Request.Context(), article, currentUser, model.ViewerKey(currentUser, ctx.ClientIP()))
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to record view of article (id=%d)", slug))
+	}
+
 	favorited, err := h.as.IsFavorited(ctx.Request.Context(), article, currentUser)
 	if err != nil {
 		msg := "failed to get favorited status"
@@ -117,33 +171,54 @@ func (h *Handler) GetArticle(ctx *gin.Context) {
 		return
 	}
 
+	err = h.renderer.RenderArticle(article)
+	if err != nil {
+		msg := "failed to render article body"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, []*model.Article{article}, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	ctx.AbortWithStatusJSON(http.StatusOK, article.ResponseArticle(favorited, following))
 }
 
-// GetArticles gets recent articles globally
+// GetArticles gets articles globally, filtered and sorted by queries
 func (h *Handler) GetArticles(ctx *gin.Context) {
 	h.logger.Info().Msg("get articles")
 
-	var favoritedBy *model.User
+	filter, err := model.ParseArticleFilter(ctx.Request.URL.Query())
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	favByUsername := ctx.Query("favorited")
 	if favByUsername != "" {
-		var err error
-		favoritedBy, err = h.us.GetByUsername(ctx.Request.Context(), favByUsername)
+		filter.FavoritedBy, err = h.us.GetByUsername(ctx.Request.Context(), favByUsername)
 		if err != nil {
-			favoritedBy = nil
+			filter.FavoritedBy = nil
 			h.logger.Warn().Msg("skipped: cannot find user (favorited by)")
 		}
 	}
 
-	tagName := ctx.Query("tag")
-	author := ctx.Query("username")
-	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
-
-	articles, err := h.as.GetArticles(ctx.Request.Context(), tagName, author, favoritedBy, limit, offset)
+	page, err := h.GetPageQuery(ctx, defaultLimit, defaultOffset)
+	if err == nil {
+		err = page.ValidateSort(filter.Sort)
+	}
 	if err != nil {
-		msg := "failed to search articles"
-		h.logger.Error().Err(err).Msg(msg)
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -159,31 +234,68 @@ func (h *Handler) GetArticles(ctx *gin.Context) {
 		}
 	}
 
-	resp := make([]message.ArticleResponse, 0, len(articles))
+	filter.Viewer = currentUser
+
+	articles, pageInfo, err := h.as.GetArticles(ctx.Request.Context(), filter, page)
+	if err != nil {
+		msg := "failed to search articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	articleIDs := make([]uint, 0, len(articles))
+	authorIDs := make([]uint, 0, len(articles))
 	for _, article := range articles {
-		favorited, err := h.as.IsFavorited(ctx.Request.Context(), &article, currentUser)
-		if err != nil {
-			msg := "failed to get favorited status"
-			h.logger.Error().Err(err).Msg(msg)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
-			return
-		}
+		articleIDs = append(articleIDs, article.ID)
+		authorIDs = append(authorIDs, article.Author.ID)
+	}
 
-		following, err := h.us.IsFollowing(ctx.Request.Context(), currentUser, &article.Author)
-		if err != nil {
-			msg := "failed to get following status"
-			h.logger.Error().Err(err).Msg(msg)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
-			return
-		}
+	favorited, err := h.as.AreFavorited(ctx.Request.Context(), articleIDs, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	refs := make([]*model.Article, 0, len(articles))
+	for i := range articles {
+		refs = append(refs, &articles[i])
+	}
 
-		resp = append(resp, article.ResponseArticle(favorited, following))
+	err = h.SetDetails(ctx, currentUser, refs, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
 	}
 
-	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{Articles: resp, ArticlesCount: int64(len(resp))})
+	resp := make([]message.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		resp = append(resp, article.ResponseArticle(favorited[article.ID], following[article.Author.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{
+		Articles:      resp,
+		ArticlesCount: pageInfo.TotalCount,
+		Links:         h.GetPageLinks(ctx, pageInfo),
+	})
 }
 
-// GetFeedArticles gets recent articles from users that current user follows
+// GetFeedArticles gets recent articles from users and tags that current user follows,
+// except articles of muted or blocked users
+//
+// The source query chooses whether articles come from followed users, followed tags or both.
 func (h *Handler) GetFeedArticles(ctx *gin.Context) {
 	h.logger.Info().Msg("get feed articles")
 
@@ -195,16 +307,33 @@ func (h *Handler) GetFeedArticles(ctx *gin.Context) {
 		return
 	}
 
-	userIDs, err := h.us.GetFollowingUserIDs(ctx.Request.Context(), currentUser)
+	filter, err := model.ParseFeedFilter(ctx.Request.URL.Query())
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.SetFeedSources(ctx, currentUser, &filter)
 	if err != nil {
-		h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to get following user ids of user %d", currentUser.ID))
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to get feed sources of user %d", currentUser.ID))
 		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to get followers"})
 		return
 	}
 
-	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+	page, err := h.GetPageQuery(ctx, defaultLimit, defaultOffset)
+	if err == nil {
+		err = page.ValidateSort(model.ArticleSortNewest)
+	}
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	articles, err := h.as.GetFeedArticles(ctx.Request.Context(), userIDs, limit, offset)
+	articles, pageInfo, err := h.as.GetFeedArticles(ctx.Request.Context(), filter, page)
 	if err != nil {
 		msg := "failed to search articles from user's followers"
 		h.logger.Error().Err(err).Msg(msg)
@@ -212,21 +341,53 @@ func (h *Handler) GetFeedArticles(ctx *gin.Context) {
 		return
 	}
 
-	following := true
-	resp := make([]message.ArticleResponse, 0, len(articles))
+	articleIDs := make([]uint, 0, len(articles))
+	authorIDs := make([]uint, 0, len(articles))
 	for _, article := range articles {
-		favorited, err := h.as.IsFavorited(ctx.Request.Context(), &article, currentUser)
-		if err != nil {
-			msg := "failed to get favorited status"
-			h.logger.Error().Err(err).Msg(msg)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
-			return
-		}
+		articleIDs = append(articleIDs, article.ID)
+		authorIDs = append(authorIDs, article.Author.ID)
+	}
+
+	favorited, err := h.as.AreFavorited(ctx.Request.Context(), articleIDs, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// authors of articles from followed tags are not always followed
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	refs := make([]*model.Article, 0, len(articles))
+	for i := range articles {
+		refs = append(refs, &articles[i])
+	}
+
+	err = h.SetDetails(ctx, currentUser, refs, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
 
-		resp = append(resp, article.ResponseArticle(favorited, following))
+	resp := make([]message.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		resp = append(resp, article.ResponseArticle(favorited[article.ID], following[article.Author.ID]))
 	}
 
-	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{Articles: resp, ArticlesCount: int64(len(resp))})
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{
+		Articles:      resp,
+		ArticlesCount: pageInfo.TotalCount,
+		Links:         h.GetPageLinks(ctx, pageInfo),
+	})
 }
 
 // UpdateArticle updates an article
@@ -282,6 +443,14 @@ func (h *Handler) UpdateArticle(ctx *gin.Context) {
 		return
 	}
 
+	article.Mentions, err = h.resolveMentions(ctx, article.Body)
+	if err != nil {
+		msg := "failed to get mentioned users"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	updatedArticle, err := h.as.Update(ctx.Request.Context(), article)
 	if err != nil {
 		msg := "failed to update article"
@@ -299,6 +468,22 @@ func (h *Handler) UpdateArticle(ctx *gin.Context) {
 	}
 
 	following := false
+	err = h.renderer.RenderArticle(updatedArticle)
+	if err != nil {
+		msg := "failed to render article body"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, []*model.Article{updatedArticle}, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	ctx.AbortWithStatusJSON(http.StatusOK, updatedArticle.ResponseArticle(favorited, following))
 }
 
@@ -375,6 +560,22 @@ func (h *Handler) FavoriteArticle(ctx *gin.Context) {
 		return
 	}
 
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to favorite article (id=%d) of private user", currentUser.ID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
 	favorited, err := h.as.IsFavorited(ctx.Request.Context(), article, currentUser)
 	if err != nil {
 		msg := "failed to get favorited status"
@@ -414,6 +615,22 @@ func (h *Handler) FavoriteArticle(ctx *gin.Context) {
 	}
 
 	favorited = true
+	err = h.renderer.RenderArticle(article)
+	if err != nil {
+		msg := "failed to render article body"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, []*model.Article{article}, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	ctx.AbortWithStatusJSON(http.StatusOK, article.ResponseArticle(favorited, following))
 }
 
@@ -444,6 +661,22 @@ func (h *Handler) UnfavoriteArticle(ctx *gin.Context) {
 		return
 	}
 
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to unfavorite article (id=%d) of private user", currentUser.ID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
 	favorited, err := h.as.IsFavorited(ctx.Request.Context(), article, currentUser)
 	if err != nil {
 		msg := "failed to get favorited status"
@@ -483,5 +716,21 @@ func (h *Handler) UnfavoriteArticle(ctx *gin.Context) {
 	}
 
 	favorited = false
+	err = h.renderer.RenderArticle(article)
+	if err != nil {
+		msg := "failed to render article body"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, []*model.Article{article}, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	ctx.AbortWithStatusJSON(http.StatusOK, article.ResponseArticle(favorited, following))
 }
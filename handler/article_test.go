@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -268,6 +270,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				assert.Equal(t, tt.expectedBody.Title, actualBody.Title, tt.title)
 				assert.Equal(t, tt.expectedBody.Description, actualBody.Description, tt.title)
 				assert.Equal(t, tt.expectedBody.Body, actualBody.Body, tt.title)
+				assert.Equal(t, fmt.Sprintf("<p>%s</p>\n", tt.expectedBody.Body), actualBody.BodyHTML, tt.title)
 				assert.Equal(t, tt.expectedBody.Favorited, actualBody.Favorited, tt.title)
 				assert.Equal(t, tt.expectedBody.FavoritesCount, actualBody.FavoritesCount, tt.title)
 				assert.Equal(t, tt.expectedBody.Author, actualBody.Author, tt.title)
@@ -281,6 +284,11 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 		fooUser := createRandomUser(t, lct.DB())
 		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
 
+		err := h.renderer.RenderArticle(fooArticle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
 		tests := []struct {
 			title              string
 			reqUser            *model.User
@@ -426,8 +434,9 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				limit     string
 				offset    string
 			}
-			expectedStatusCode int
-			expectedArticles   []*model.Article
+			expectedStatusCode    int
+			expectedArticles      []*model.Article
+			expectedArticlesCount int64
 		}{
 			{
 				"get articles: with default queries",
@@ -447,6 +456,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles,
+				10,
 			},
 			{
 				"get articles: allow public access with default queries",
@@ -466,6 +476,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles,
+				10,
 			},
 			{
 				"get articles: with limit and offset",
@@ -485,6 +496,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				10,
 			},
 			{
 				"get articles: allow public access with limit and offset",
@@ -504,6 +516,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				10,
 			},
 			{
 				"get articles: with tag",
@@ -523,6 +536,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				5,
 			},
 			{
 				"get articles: allow public access with tag",
@@ -542,6 +556,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				5,
 			},
 			{
 				"get articles: with author",
@@ -561,6 +576,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[0:5],
+				5,
 			},
 			{
 				"get articles: allow public access with author",
@@ -580,6 +596,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[0:5],
+				5,
 			},
 			{
 				"get articles: with various queries",
@@ -599,6 +616,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[6:8],
+				5,
 			},
 			{
 				"get articles: allow public access with various queries",
@@ -618,6 +636,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[6:8],
+				5,
 			},
 			{
 				"get articles: with favorited queries",
@@ -637,6 +656,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				5,
 			},
 			{
 				"get articles: allow public access with favorited queries",
@@ -656,6 +676,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[5:10],
+				5,
 			},
 		}
 
@@ -679,7 +700,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
 			assert.Len(t, actualBody.Articles, len(tt.expectedArticles), tt.title)
-			assert.Len(t, tt.expectedArticles, int(actualBody.ArticlesCount), tt.title)
+			assert.Equal(t, tt.expectedArticlesCount, actualBody.ArticlesCount, tt.title)
 
 			for i := 0; i < len(actualBody.Articles); i++ {
 				got := actualBody.Articles[i]
@@ -692,6 +713,239 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				assert.Equal(t, want.Author.Username, got.Author.Username, tt.title)
 			}
 		}
+
+		// walk forward through pages with cursor links, then back again
+		nextURL := "/api/v1/articles?limit=3"
+		walked := make([]uint, 0, len(articles))
+		pages := []message.ArticlesResponse{}
+		for nextURL != "" {
+			req := httptest.NewRequest(http.MethodGet, nextURL, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.GetArticles(ctx)
+
+			actualBody := test.GetResponseBody[message.ArticlesResponse](t, w.Result())
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode, "get articles: walk next links")
+			assert.Equal(t, int64(len(articles)), actualBody.ArticlesCount, "get articles: walk next links")
+
+			for _, a := range actualBody.Articles {
+				walked = append(walked, a.ID)
+			}
+
+			pages = append(pages, actualBody)
+			nextURL = actualBody.Links.Next
+		}
+
+		expectedIDs := make([]uint, 0, len(articles))
+		for _, a := range articles {
+			expectedIDs = append(expectedIDs, a.ID)
+		}
+
+		assert.Equal(t, expectedIDs, walked, "get articles: walk next links")
+		assert.Len(t, pages, 4, "get articles: walk next links")
+		assert.Empty(t, pages[0].Links.Prev, "get articles: first page has no prev link")
+
+		prevURL := pages[len(pages)-1].Links.Prev
+		req := httptest.NewRequest(http.MethodGet, prevURL, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+		h.GetArticles(ctx)
+
+		actualBody := test.GetResponseBody[message.ArticlesResponse](t, w.Result())
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode, "get articles: follow prev link")
+		assert.Equal(t, pages[len(pages)-2].Articles, actualBody.Articles, "get articles: follow prev link")
+
+		// invalid cursor
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/articles?after=invalid", nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+		h.GetArticles(ctx)
+
+		errBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+		assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode, "get articles: invalid cursor")
+		assert.Equal(t,
+			map[string]interface{}{"error": "validation error: After: invalid cursor."},
+			errBody,
+			"get articles: invalid cursor",
+		)
+
+		// sorts and filters
+		createRandomComment(t, lct.DB(), articles[9].ID, reqUser.ID)
+		createRandomComment(t, lct.DB(), articles[9].ID, reqUser.ID)
+		createRandomComment(t, lct.DB(), articles[8].ID, reqUser.ID)
+
+		oldest := slices.Clone(articles)
+		slices.Reverse(oldest)
+
+		mostCommented := []*model.Article{articles[9], articles[8]}
+		mostCommented = append(mostCommented, articles[0:8]...)
+
+		otherTag := test.RandomString(t, 10)
+
+		filterTests := []struct {
+			title                 string
+			query                 url.Values
+			expectedStatusCode    int
+			expectedArticles      []*model.Article
+			expectedArticlesCount int64
+			expectedError         map[string]interface{}
+		}{
+			{
+				"get articles: sort by oldest",
+				url.Values{"sort": {"oldest"}},
+				http.StatusOK,
+				oldest,
+				10,
+				nil,
+			},
+			{
+				"get articles: sort by most favorited",
+				url.Values{"sort": {"most_favorited"}, "limit": {"5"}},
+				http.StatusOK,
+				articles[5:10],
+				10,
+				nil,
+			},
+			{
+				"get articles: sort by most commented",
+				url.Values{"sort": {"most_commented"}},
+				http.StatusOK,
+				mostCommented,
+				10,
+				nil,
+			},
+			{
+				"get articles: with multiple authors",
+				url.Values{"username": {fooUser.Username + "," + barUser.Username}},
+				http.StatusOK,
+				articles,
+				10,
+				nil,
+			},
+			{
+				"get articles: with any of tags",
+				url.Values{"tag": {tag.Name, otherTag}},
+				http.StatusOK,
+				articles[5:10],
+				5,
+				nil,
+			},
+			{
+				"get articles: with all of tags",
+				url.Values{"tag": {tag.Name, otherTag}, "tag_match": {"all"}},
+				http.StatusOK,
+				[]*model.Article{},
+				0,
+				nil,
+			},
+			{
+				"get articles: with excluded tag",
+				url.Values{"exclude_tag": {tag.Name}},
+				http.StatusOK,
+				articles[0:5],
+				5,
+				nil,
+			},
+			{
+				"get articles: with created date range",
+				url.Values{
+					"created_after":  {articles[7].CreatedAt.Format(time.RFC3339Nano)},
+					"created_before": {articles[2].CreatedAt.Format(time.RFC3339Nano)},
+				},
+				http.StatusOK,
+				articles[3:8],
+				5,
+				nil,
+			},
+			{
+				"get articles: with invalid sort",
+				url.Values{"sort": {"popular"}},
+				http.StatusBadRequest,
+				nil,
+				0,
+				map[string]interface{}{
+					"error": "validation error: Sort: must be one of newest, oldest, most_favorited, recently_updated, most_commented.",
+				},
+			},
+			{
+				"get articles: with invalid date",
+				url.Values{"updated_after": {"yesterday"}},
+				http.StatusBadRequest,
+				nil,
+				0,
+				map[string]interface{}{
+					"error": "validation error: UpdatedAfter: must be a date (YYYY-MM-DD) or RFC 3339 timestamp.",
+				},
+			},
+			{
+				"get articles: with cursor of another sort",
+				url.Values{"sort": {"oldest"}, "after": {(&model.Cursor{Sort: model.ArticleSortNewest, Time: articles[0].CreatedAt, ID: articles[0].ID}).Encode()}},
+				http.StatusBadRequest,
+				nil,
+				0,
+				map[string]interface{}{"error": "validation error: After: cursor does not match sort."},
+			},
+		}
+
+		for _, tt := range filterTests {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/articles?"+tt.query.Encode(), nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.GetArticles(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.expectedError != nil {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+				continue
+			}
+
+			actualBody := test.GetResponseBody[message.ArticlesResponse](t, w.Result())
+			assert.Len(t, actualBody.Articles, len(tt.expectedArticles), tt.title)
+			assert.Equal(t, tt.expectedArticlesCount, actualBody.ArticlesCount, tt.title)
+
+			for i := 0; i < len(actualBody.Articles); i++ {
+				assert.Equal(t, tt.expectedArticles[i].ID, actualBody.Articles[i].ID, tt.title)
+			}
+
+			if tt.query.Get("sort") == model.ArticleSortMostCommented {
+				assert.Equal(t, int64(2), actualBody.Articles[0].CommentsCount, tt.title)
+				assert.Equal(t, int64(1), actualBody.Articles[1].CommentsCount, tt.title)
+				assert.Equal(t, int64(0), actualBody.Articles[2].CommentsCount, tt.title)
+			}
+		}
+
+		// walk most favorited articles with cursor links
+		nextURL = "/api/v1/articles?sort=most_favorited&limit=4"
+		walked = make([]uint, 0, len(articles))
+		for nextURL != "" {
+			req := httptest.NewRequest(http.MethodGet, nextURL, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.GetArticles(ctx)
+
+			actualBody := test.GetResponseBody[message.ArticlesResponse](t, w.Result())
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode, "get articles: walk most favorited")
+
+			for _, a := range actualBody.Articles {
+				walked = append(walked, a.ID)
+			}
+
+			nextURL = actualBody.Links.Next
+		}
+
+		expectedIDs = expectedIDs[:0]
+		for _, a := range append(slices.Clone(articles[5:10]), articles[0:5]...) {
+			expectedIDs = append(expectedIDs, a.ID)
+		}
+
+		assert.Equal(t, expectedIDs, walked, "get articles: walk most favorited")
 	})
 
 	t.Run("GetFeedArticles", func(t *testing.T) {
@@ -763,10 +1017,11 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				limit  string
 				offset string
 			}
-			expectedStatusCode int
-			expectedArticles   []*model.Article
-			expectedError      map[string]interface{}
-			hasError           bool
+			expectedStatusCode    int
+			expectedArticles      []*model.Article
+			expectedArticlesCount int64
+			expectedError         map[string]interface{}
+			hasError              bool
 		}{
 			{
 				"get articles: with default queries",
@@ -780,6 +1035,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[0:5],
+				5,
 				nil,
 				false,
 			},
@@ -795,6 +1051,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				articles[1:3],
+				5,
 				nil,
 				false,
 			},
@@ -810,6 +1067,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusOK,
 				[]*model.Article{},
+				0,
 				nil,
 				false,
 			},
@@ -825,6 +1083,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 				},
 				http.StatusNotFound,
 				nil,
+				0,
 				map[string]interface{}{"error": "current user not found"},
 				true,
 			},
@@ -851,7 +1110,7 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 			} else {
 				actualBody := test.GetResponseBody[message.ArticlesResponse](t, w.Result())
 				assert.Len(t, actualBody.Articles, len(tt.expectedArticles), tt.title)
-				assert.Len(t, tt.expectedArticles, int(actualBody.ArticlesCount), tt.title)
+				assert.Equal(t, tt.expectedArticlesCount, actualBody.ArticlesCount, tt.title)
 
 				for i := 0; i < len(actualBody.Articles); i++ {
 					got := actualBody.Articles[i]
@@ -1374,3 +1633,108 @@ func TestIntegration_ArticleHandler(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkIntegration_ArticleHandler(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping integration benchmarks.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(b)
+
+	const pageSize = 100
+
+	author := createRandomUser(b, lct.DB())
+	reqUser := createRandomUser(b, lct.DB())
+
+	err := h.us.Follow(context.Background(), reqUser, author)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	articles := make([]model.Article, 0, pageSize)
+	for i := 0; i < pageSize; i++ {
+		article := createRandomArticle(b, lct.DB(), author.ID)
+		article.Author = *author
+
+		if i%2 == 0 {
+			err := h.as.AddFavorite(context.Background(), article, reqUser,
+				func(favoritesCount int64, updatedAt time.Time) {})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		articles = append(articles, *article)
+	}
+
+	// statuses looked up row by row, as listings used to do
+	b.Run("PerRowLookups", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, article := range articles {
+				_, err := h.as.IsFavorited(context.Background(), &article, reqUser)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				_, err = h.us.IsFollowing(context.Background(), reqUser, &article.Author)
+				if err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("BatchLookups", func(b *testing.B) {
+		articleIDs := make([]uint, 0, len(articles))
+		authorIDs := make([]uint, 0, len(articles))
+		for _, article := range articles {
+			articleIDs = append(articleIDs, article.ID)
+			authorIDs = append(authorIDs, article.Author.ID)
+		}
+
+		for i := 0; i < b.N; i++ {
+			_, err := h.as.AreFavorited(context.Background(), articleIDs, reqUser)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			_, err = h.us.AreFollowing(context.Background(), reqUser, authorIDs)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("GetArticles", func(b *testing.B) {
+		apiUrl := fmt.Sprintf("/api/v1/articles?username=%s&limit=%d", author.Username, pageSize)
+
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(b, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.GetArticles(ctx)
+
+			if w.Result().StatusCode != http.StatusOK {
+				b.Fatalf("unexpected status code: %d", w.Result().StatusCode)
+			}
+		}
+	})
+
+	b.Run("GetFeedArticles", func(b *testing.B) {
+		apiUrl := fmt.Sprintf("/api/v1/articles/feed?limit=%d", pageSize)
+
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(b, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.GetFeedArticles(ctx)
+
+			if w.Result().StatusCode != http.StatusOK {
+				b.Fatalf("unexpected status code: %d", w.Result().StatusCode)
+			}
+		}
+	})
+}
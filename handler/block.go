@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// GetBlocks gets users blocked by current user
+func (h *Handler) GetBlocks(ctx *gin.Context) {
+	h.logger.Info().Msg("get blocks")
+	h.getRelatedUsers(ctx, "blocked users", h.us.GetBlocks)
+}
+
+// BlockUser blocks a user, which also removes follows between current user and the user
+//
+// Blocked users cannot follow current user, comment on articles of current user or
+// mention current user, and either of them no longer sees articles and comments of the other.
+func (h *Handler) BlockUser(ctx *gin.Context) {
+	h.logger.Info().Msg("block user")
+	h.updateRelatedUser(ctx, "block", h.us.Block)
+}
+
+// UnblockUser unblocks a user
+func (h *Handler) UnblockUser(ctx *gin.Context) {
+	h.logger.Info().Msg("unblock user")
+	h.updateRelatedUser(ctx, "unblock", h.us.Unblock)
+}
+
+// GetMutes gets users muted by current user
+func (h *Handler) GetMutes(ctx *gin.Context) {
+	h.logger.Info().Msg("get mutes")
+	h.getRelatedUsers(ctx, "muted users", h.us.GetMutes)
+}
+
+// MuteUser mutes a user, whose articles and notifications are no longer in feed of current user
+func (h *Handler) MuteUser(ctx *gin.Context) {
+	h.logger.Info().Msg("mute user")
+	h.updateRelatedUser(ctx, "mute", h.us.Mute)
+}
+
+// UnmuteUser unmutes a user
+func (h *Handler) UnmuteUser(ctx *gin.Context) {
+	h.logger.Info().Msg("unmute user")
+	h.updateRelatedUser(ctx, "unmute", h.us.Unmute)
+}
+
+// getRelatedUsers gets a page of users listed by list for current user
+func (h *Handler) getRelatedUsers(ctx *gin.Context, name string, list func(context.Context, *model.User, int64, int64) ([]model.User, int64, error)) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err = model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, count, err := list(ctx.Request.Context(), currentUser, limit, offset)
+	if err != nil {
+		msg := fmt.Sprintf("failed to get %s", name)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	userIDs := make([]uint, 0, len(users))
+	refs := make([]*model.User, 0, len(users))
+	for i := range users {
+		userIDs = append(userIDs, users[i].ID)
+		refs = append(refs, &users[i])
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, userIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, refs, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.ProfileResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, u.ResponseProfile(following[u.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ProfilesResponse{
+		Profiles:      resp,
+		ProfilesCount: count,
+	})
+}
+
+// updateRelatedUser applies update of action from current user to the user of username param,
+// and responds with profile of the user
+func (h *Handler) updateRelatedUser(ctx *gin.Context, action string, update func(context.Context, *model.User, *model.User) error) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	username := ctx.Param("username")
+
+	if currentUser.Username == username {
+		msg := fmt.Sprintf("cannot %s yourself", action)
+		err := fmt.Errorf("user (username: %s) cannot %s yourself", currentUser.Username, action)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	user, err := h.us.GetByUsername(ctx.Request.Context(), username)
+	if err != nil {
+		msg := "user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	err = update(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		h.logger.Error().Err(err).
+			Msg(fmt.Sprintf("failed to %s user: (ID: %d) -> (ID: %d)", action, currentUser.ID, user.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to %s user", action)})
+		return
+	}
+
+	following, err := h.us.IsFollowing(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, resp)
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_BlockHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	request := func(t *testing.T, handle gin.HandlerFunc, method, apiUrl string, body interface{}, user *model.User, params gin.Params) *http.Response {
+		t.Helper()
+
+		var b bytes.Buffer
+		if body != nil {
+			err := json.NewEncoder(&b).Encode(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		req := httptest.NewRequest(method, apiUrl, &b)
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+		for _, p := range params {
+			ctx.AddParam(p.Key, p.Value)
+		}
+
+		handle(ctx)
+
+		return w.Result()
+	}
+
+	usernameParam := func(user *model.User) gin.Params {
+		return gin.Params{{Key: "username", Value: user.Username}}
+	}
+
+	t.Run("Block", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		bazArticle := createRandomArticle(t, lct.DB(), bazUser.ID)
+		fooComment := createRandomComment(t, lct.DB(), bazArticle.ID, fooUser.ID)
+		barComment := createRandomComment(t, lct.DB(), bazArticle.ID, barUser.ID)
+
+		for _, follow := range [][2]*model.User{{barUser, fooUser}, {fooUser, barUser}} {
+			err := h.us.Follow(context.Background(), follow[0], follow[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		resp := request(t, h.BlockUser, http.MethodPut, "/api/v1/me/blocks/"+barUser.Username, nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profile := test.GetResponseBody[message.ProfileResponse](t, resp)
+		assert.False(t, profile.Following)
+
+		// follows are removed both ways
+		following, err := h.us.IsFollowing(context.Background(), barUser, fooUser)
+		assert.NoError(t, err)
+		assert.False(t, following)
+		following, err = h.us.IsFollowing(context.Background(), fooUser, barUser)
+		assert.NoError(t, err)
+		assert.False(t, following)
+
+		// blocking again does nothing
+		resp = request(t, h.BlockUser, http.MethodPut, "/api/v1/me/blocks/"+barUser.Username, nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = request(t, h.GetBlocks, http.MethodGet, "/api/v1/me/blocks", nil, fooUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		blocks := test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(1), blocks.ProfilesCount)
+		if assert.Len(t, blocks.Profiles, 1) {
+			assert.Equal(t, barUser.Username, blocks.Profiles[0].Username)
+		}
+
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", nil, barUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "forbidden"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+barUser.Username+"/follow", nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		fooSlug := fmt.Sprintf("%d", fooArticle.ID)
+		resp = request(t, h.CreateComment, http.MethodPost, "/api/v1/articles/"+fooSlug+"/comments",
+			message.CreateCommentRequest{Body: "hello"}, barUser, gin.Params{{Key: "slug", Value: fooSlug}})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "forbidden"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		slugParams := gin.Params{{Key: "slug", Value: fooSlug}}
+		resp = request(t, h.GetArticle, http.MethodGet, "/api/v1/articles/"+fooSlug, nil, barUser, slugParams)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		resp = request(t, h.FavoriteArticle, http.MethodPost, "/api/v1/articles/"+fooSlug+"/favorite", nil, barUser, slugParams)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// replies to comments of fooUser on articles of others are forbidden too
+		bazSlug := fmt.Sprintf("%d", bazArticle.ID)
+		resp = request(t, h.CreateComment, http.MethodPost, "/api/v1/articles/"+bazSlug+"/comments",
+			message.CreateCommentRequest{Body: "hello", ParentID: fooComment.ID}, barUser, gin.Params{{Key: "slug", Value: bazSlug}})
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+		// articles of fooUser are hidden from barUser
+		resp = request(t, h.GetArticles, http.MethodGet, "/api/v1/articles?username="+fooUser.Username, nil, barUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(0), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.GetArticles, http.MethodGet, "/api/v1/articles?username="+fooUser.Username, nil, bazUser, nil)
+		assert.Equal(t, int64(1), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		// comments of barUser are hidden from fooUser, and the other way around
+		for _, tt := range []struct {
+			user     *model.User
+			expected uint
+		}{{fooUser, fooComment.ID}, {barUser, barComment.ID}} {
+			resp = request(t, h.GetComments, http.MethodGet, "/api/v1/articles/"+bazSlug+"/comments", nil, tt.user, gin.Params{{Key: "slug", Value: bazSlug}})
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			comments := test.GetResponseBody[message.CommentsResponse](t, resp)
+			assert.Equal(t, int64(1), comments.CommentsCount)
+			if assert.Len(t, comments.Comments, 1) {
+				assert.Equal(t, tt.expected, comments.Comments[0].ID)
+			}
+		}
+
+		resp = request(t, h.UnblockUser, http.MethodDelete, "/api/v1/me/blocks/"+barUser.Username, nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", nil, barUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("Block: followers and following", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+		quxUser := createRandomUser(t, lct.DB())
+
+		// barUser and bazUser follow fooUser, fooUser follows barUser back
+		for _, follow := range [][2]*model.User{{barUser, fooUser}, {bazUser, fooUser}, {fooUser, barUser}} {
+			err := h.us.Follow(context.Background(), follow[0], follow[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := h.us.Block(context.Background(), quxUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := request(t, h.GetFollowers, http.MethodGet, "/api/v1/profiles/"+fooUser.Username+"/followers", nil, quxUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.ProfilesResponse{
+			Profiles:      []message.ProfileResponse{bazUser.ResponseProfileOfViewer(false, false)},
+			ProfilesCount: 1,
+		}, test.GetResponseBody[message.ProfilesResponse](t, resp))
+
+		resp = request(t, h.GetFollowing, http.MethodGet, "/api/v1/profiles/"+fooUser.Username+"/following", nil, quxUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.ProfilesResponse{
+			Profiles:      []message.ProfileResponse{},
+			ProfilesCount: 0,
+		}, test.GetResponseBody[message.ProfilesResponse](t, resp))
+	})
+
+	t.Run("Block: invalid", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+
+		resp := request(t, h.BlockUser, http.MethodPut, "/api/v1/me/blocks/"+fooUser.Username, nil, fooUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "cannot block yourself"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		resp = request(t, h.BlockUser, http.MethodPut, "/api/v1/me/blocks/unknown_user", nil, fooUser, gin.Params{{Key: "username", Value: "unknown_user"}})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "user not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		resp = request(t, h.GetBlocks, http.MethodGet, "/api/v1/me/blocks", nil, &model.User{ID: 0}, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "current user not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
+
+	t.Run("Mute", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+
+		err := h.us.Follow(context.Background(), fooUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp := request(t, h.MuteUser, http.MethodPut, "/api/v1/me/mutes/"+barUser.Username, nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profile := test.GetResponseBody[message.ProfileResponse](t, resp)
+		assert.True(t, profile.Following)
+
+		resp = request(t, h.GetMutes, http.MethodGet, "/api/v1/me/mutes", nil, fooUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		mutes := test.GetResponseBody[message.ProfilesResponse](t, resp)
+		if assert.Len(t, mutes.Profiles, 1) {
+			assert.Equal(t, barUser.ResponseProfile(true), mutes.Profiles[0])
+		}
+
+		// muted users are only hidden from feed
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed", nil, fooUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(0), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.GetArticles, http.MethodGet, "/api/v1/articles?username="+barUser.Username, nil, fooUser, nil)
+		articles := test.GetResponseBody[message.ArticlesResponse](t, resp)
+		if assert.Len(t, articles.Articles, 1) {
+			assert.Equal(t, barArticle.ID, articles.Articles[0].ID)
+		}
+
+		resp = request(t, h.UnmuteUser, http.MethodDelete, "/api/v1/me/mutes/"+barUser.Username, nil, fooUser, usernameParam(barUser))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed", nil, fooUser, nil)
+		assert.Equal(t, int64(1), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.MuteUser, http.MethodPut, "/api/v1/me/mutes/"+fooUser.Username, nil, fooUser, usernameParam(fooUser))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "cannot mute yourself"}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
+}
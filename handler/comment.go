@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nathanbizkit/article-management-go/message"
@@ -36,6 +37,23 @@ func (h *Handler) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	// blocks between current user and the author are covered by visibility
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to comment on article (id=%d) hidden from them", currentUser.ID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
 	var req message.CreateCommentRequest
 	err = ctx.ShouldBindJSON(&req)
 	if err != nil {
@@ -59,6 +77,50 @@ func (h *Handler) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	if req.ParentID != 0 {
+		parent, err := h.as.GetCommentByID(ctx.Request.Context(), req.ParentID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("parent comment (id=%d) not found", req.ParentID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "parent comment not found"})
+			return
+		}
+
+		err = comment.ValidateReply(parent, h.environ.CommentMaxDepth)
+		if err != nil {
+			err := fmt.Errorf("validation error: %w", err)
+			h.logger.Error().Err(err).Msg("validation error")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		blocked, err := h.us.IsBlockedBetween(ctx.Request.Context(), currentUser, &parent.Author)
+		if err != nil {
+			msg := "failed to get blocked status"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		if blocked {
+			msg := "forbidden"
+			err := fmt.Errorf("user (id=%d) attempted to reply to comment (id=%d) with a block between them and its author", currentUser.ID, parent.ID)
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	comment.Mentions, err = h.resolveMentions(ctx, comment.Body)
+	if err != nil {
+		msg := "failed to get mentioned users"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	createdComment, err := h.as.CreateComment(ctx.Request.Context(), &comment)
 	if err != nil {
 		msg := "failed to create comment"
@@ -67,11 +129,28 @@ func (h *Handler) CreateComment(ctx *gin.Context) {
 		return
 	}
 
+	err = h.SetMentions(ctx, nil, []*model.Comment{createdComment})
+	if err != nil {
+		msg := "failed to get mentions"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, []*model.User{&createdComment.Author}, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
 	following := false
 	ctx.AbortWithStatusJSON(http.StatusOK, createdComment.ResponseComment(following))
 }
 
-// GetComments gets comments of an article
+// GetComments gets a page of comment threads of an article, either flattened depth-first
+// with depth of each comment (format=flat, default) or nested (format=tree)
 func (h *Handler) GetComments(ctx *gin.Context) {
 	h.logger.Info().Msg("get comments")
 
@@ -83,18 +162,39 @@ func (h *Handler) GetComments(ctx *gin.Context) {
 		return
 	}
 
-	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	format := ctx.DefaultQuery("format", model.CommentFormatFlat)
+	err = model.ValidateCommentFormat(format)
 	if err != nil {
-		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
-		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	comments, err := h.as.GetComments(ctx.Request.Context(), article)
+	sort := ctx.DefaultQuery("sort", model.CommentSortNewest)
+	err = model.ValidateCommentSort(sort)
 	if err != nil {
-		msg := "failed to get comments"
-		h.logger.Error().Err(err).Msg(msg)
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.GetPageQuery(ctx, defaultLimit, defaultOffset)
+	if err == nil {
+		err = page.ValidateSort(sort)
+	}
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
 		return
 	}
 
@@ -110,20 +210,250 @@ func (h *Handler) GetComments(ctx *gin.Context) {
 		}
 	}
 
-	resp := make([]message.CommentResponse, 0, len(comments))
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get comments of article (id=%d) of private user", userID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	comments, pageInfo, err := h.as.GetComments(ctx.Request.Context(), article, currentUser, sort, page)
+	if err != nil {
+		msg := "failed to get comments"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	authorIDs := make([]uint, 0, len(comments))
 	for _, c := range comments {
-		following, err := h.us.IsFollowing(ctx.Request.Context(), currentUser, &c.Author)
+		authorIDs = append(authorIDs, c.Author.ID)
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	refs := make([]*model.Comment, 0, len(comments))
+	for i := range comments {
+		refs = append(refs, &comments[i])
+	}
+
+	err = h.SetDetails(ctx, currentUser, nil, refs)
+	if err != nil {
+		msg := "failed to get comment details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	threads := model.NestComments(comments)
+
+	resp := make([]message.CommentResponse, 0, len(comments))
+	if format == model.CommentFormatTree {
+		for _, c := range threads {
+			resp = append(resp, c.ResponseCommentThread(following))
+		}
+	} else {
+		for _, c := range model.FlattenComments(threads) {
+			resp = append(resp, c.ResponseComment(following[c.Author.ID]))
+		}
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.CommentsResponse{
+		Comments:      resp,
+		CommentsCount: article.CommentsCount,
+		Links:         h.GetPageLinks(ctx, pageInfo),
+	})
+}
+
+// UpdateComment updates body of a comment within edit window, keeping its prior body
+func (h *Handler) UpdateComment(ctx *gin.Context) {
+	h.logger.Info().Msg("update comment")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid comment id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var req message.UpdateCommentRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	comment, err := h.as.GetCommentByID(ctx.Request.Context(), id)
+	if err != nil || comment.IsDeleted() {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("comment (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+
+	if slug != comment.ArticleID {
+		msg := "the comment is not from this article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	if comment.UserID != currentUser.ID {
+		err := fmt.Errorf(
+			"current user (id=%d) is forbidden to update this comment (id=%d)",
+			currentUser.ID, comment.ID,
+		)
+		msg := "forbidden"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	if !comment.IsEditable(h.environ.CommentEditWindow, time.Now()) {
+		err := fmt.Errorf("comment (id=%d) is past its edit window (%s)", comment.ID, h.environ.CommentEditWindow)
+		msg := "comment can no longer be edited"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	updatedComment := comment
+	if req.Body != comment.Body {
+		comment.Body = req.Body
+
+		err = comment.Validate()
+		if err != nil {
+			err := fmt.Errorf("validation error: %w", err)
+			h.logger.Error().Err(err).Msg("validation error")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		comment.Mentions, err = h.resolveMentions(ctx, comment.Body)
 		if err != nil {
-			msg := "failed to get following status"
+			msg := "failed to get mentioned users"
 			h.logger.Error().Err(err).Msg(msg)
 			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
 			return
 		}
 
-		resp = append(resp, c.ResponseComment(following))
+		updatedComment, err = h.as.UpdateComment(ctx.Request.Context(), comment)
+		if err != nil {
+			msg := "failed to update comment"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
 	}
 
-	ctx.AbortWithStatusJSON(http.StatusOK, message.CommentsResponse{Comments: resp})
+	err = h.SetDetails(ctx, currentUser, nil, []*model.Comment{updatedComment})
+	if err != nil {
+		msg := "failed to get comment details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following := false
+	ctx.AbortWithStatusJSON(http.StatusOK, updatedComment.ResponseComment(following))
+}
+
+// GetCommentRevisions gets prior bodies of an edited comment, only for moderators
+func (h *Handler) GetCommentRevisions(ctx *gin.Context) {
+	h.logger.Info().Msg("get comment revisions")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	if !h.IsModerator(currentUser) {
+		err := fmt.Errorf("current user (id=%d) is not a moderator", currentUser.ID)
+		msg := "forbidden"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid comment id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	comment, err := h.as.GetCommentByID(ctx.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("comment (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+
+	if slug != comment.ArticleID {
+		msg := "the comment is not from this article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	revisions, err := h.as.GetCommentRevisions(ctx.Request.Context(), comment)
+	if err != nil {
+		msg := "failed to get comment revisions"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.CommentRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		resp = append(resp, r.ResponseCommentRevision())
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.CommentRevisionsResponse{Revisions: resp})
 }
 
 // DeleteComment deletes a comment from an article
@@ -155,7 +485,7 @@ func (h *Handler) DeleteComment(ctx *gin.Context) {
 	}
 
 	comment, err := h.as.GetCommentByID(ctx.Request.Context(), id)
-	if err != nil {
+	if err != nil || comment.IsDeleted() {
 		h.logger.Error().Err(err).Msg(fmt.Sprintf("comment (id=%d) not found", id))
 		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 		return
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
@@ -166,6 +167,7 @@ func TestIntegration_CommentHandler(t *testing.T) {
 						comment2.ResponseComment(false),
 						comment1.ResponseComment(false),
 					},
+					CommentsCount: 2,
 				},
 				nil,
 				false,
@@ -191,6 +193,7 @@ func TestIntegration_CommentHandler(t *testing.T) {
 						comment2.ResponseComment(false),
 						comment1.ResponseComment(false),
 					},
+					CommentsCount: 2,
 				},
 				nil,
 				false,
@@ -248,6 +251,555 @@ func TestIntegration_CommentHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("GetComments: pagination and sorts", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		reqSlug := strconv.Itoa(int(fooArticle.ID))
+
+		roots := []*model.Comment{}
+		for i := 0; i < 5; i++ {
+			roots = append(roots, createRandomComment(t, lct.DB(), fooArticle.ID, fooUser.ID))
+		}
+
+		reply := createRandomReply(t, lct.DB(), roots[2], fooUser.ID)
+		createRandomReply(t, lct.DB(), reply, fooUser.ID)
+		createRandomReply(t, lct.DB(), roots[2], fooUser.ID)
+		createRandomReply(t, lct.DB(), roots[4], fooUser.ID)
+
+		getComments := func(t *testing.T, apiUrl string) *httptest.ResponseRecorder {
+			t.Helper()
+
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+			ctx.AddParam("slug", reqSlug)
+
+			h.GetComments(ctx)
+			return w
+		}
+
+		rootIDs := func(comments []message.CommentResponse) []uint {
+			ids := []uint{}
+			for _, c := range comments {
+				if c.ParentID == nil {
+					ids = append(ids, c.ID)
+				}
+			}
+			return ids
+		}
+
+		sortTests := []struct {
+			title              string
+			query              string
+			expectedStatusCode int
+			expectedRootIDs    []uint
+			expectedError      map[string]interface{}
+		}{
+			{
+				"get comments: sort by newest",
+				"sort=newest",
+				http.StatusOK,
+				[]uint{roots[4].ID, roots[3].ID, roots[2].ID, roots[1].ID, roots[0].ID},
+				nil,
+			},
+			{
+				"get comments: sort by oldest",
+				"sort=oldest",
+				http.StatusOK,
+				[]uint{roots[0].ID, roots[1].ID, roots[2].ID, roots[3].ID, roots[4].ID},
+				nil,
+			},
+			{
+				"get comments: sort by top",
+				"sort=top&limit=2",
+				http.StatusOK,
+				[]uint{roots[2].ID, roots[4].ID},
+				nil,
+			},
+			{
+				"get comments: invalid sort",
+				"sort=best",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "validation error: sort: must be one of newest, oldest, top."},
+			},
+			{
+				"get comments: limit above maximum is clamped",
+				"sort=oldest&limit=1000",
+				http.StatusOK,
+				[]uint{roots[0].ID, roots[1].ID, roots[2].ID, roots[3].ID, roots[4].ID},
+				nil,
+			},
+			{
+				"get comments: with cursor of another sort",
+				"sort=oldest&after=" + (&model.Cursor{Sort: model.CommentSortNewest, Time: roots[0].CreatedAt, ID: roots[0].ID}).Encode(),
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "validation error: After: cursor does not match sort."},
+			},
+		}
+
+		for _, tt := range sortTests {
+			w := getComments(t, fmt.Sprintf("/api/v1/articles/%v/comments?%s", reqSlug, tt.query))
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.expectedError != nil {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+				continue
+			}
+
+			actualBody := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+			assert.Equal(t, tt.expectedRootIDs, rootIDs(actualBody.Comments), tt.title)
+			assert.Equal(t, int64(9), actualBody.CommentsCount, tt.title)
+		}
+
+		// walk forward through pages with cursor links, then back again
+		nextURL := fmt.Sprintf("/api/v1/articles/%v/comments?limit=2", reqSlug)
+		walked := []uint{}
+		pages := []message.CommentsResponse{}
+		for nextURL != "" {
+			w := getComments(t, nextURL)
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			actualBody := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+			walked = append(walked, rootIDs(actualBody.Comments)...)
+			pages = append(pages, actualBody)
+			nextURL = actualBody.Links.Next
+		}
+
+		assert.Equal(t, []uint{roots[4].ID, roots[3].ID, roots[2].ID, roots[1].ID, roots[0].ID}, walked)
+		assert.Len(t, pages, 3)
+
+		// replies come along with their top-level comment
+		assert.Len(t, pages[0].Comments, 3)
+		assert.Len(t, pages[1].Comments, 5)
+		assert.Len(t, pages[2].Comments, 1)
+
+		prev := test.GetResponseBody[message.CommentsResponse](t, getComments(t, pages[2].Links.Prev).Result())
+		assert.Equal(t, rootIDs(pages[1].Comments), rootIDs(prev.Comments))
+	})
+
+	t.Run("Replies", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+
+		root := createRandomComment(t, lct.DB(), barArticle.ID, fooUser.ID)
+		fooComment := createRandomComment(t, lct.DB(), fooArticle.ID, fooUser.ID)
+
+		deepest := root
+		for i := 0; i < lct.Environ().CommentMaxDepth; i++ {
+			deepest = createRandomReply(t, lct.DB(), deepest, barUser.ID)
+		}
+
+		createTests := []struct {
+			title              string
+			reqParentID        uint
+			expectedStatusCode int
+			expectedDepth      int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"create reply: success",
+				root.ID,
+				http.StatusOK,
+				1,
+				nil,
+				false,
+			},
+			{
+				"create reply: parent not found",
+				math.MaxInt32,
+				http.StatusNotFound,
+				0,
+				map[string]interface{}{"error": "parent comment not found"},
+				true,
+			},
+			{
+				"create reply: parent from another article",
+				fooComment.ID,
+				http.StatusBadRequest,
+				0,
+				map[string]interface{}{"error": "validation error: ParentID: must be a comment of the same article."},
+				true,
+			},
+			{
+				"create reply: too deep",
+				deepest.ID,
+				http.StatusBadRequest,
+				0,
+				map[string]interface{}{
+					"error": fmt.Sprintf("validation error: ParentID: replies cannot be nested deeper than %d levels.", lct.Environ().CommentMaxDepth),
+				},
+				true,
+			},
+		}
+
+		for _, tt := range createTests {
+			body, err := json.Marshal(message.CreateCommentRequest{
+				Body:     test.RandomString(t, 20),
+				ParentID: tt.reqParentID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reqSlug := strconv.Itoa(int(barArticle.ID))
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/comments", reqSlug)
+			req := httptest.NewRequest(http.MethodPost, apiUrl, bytes.NewReader(body))
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+			ctx.AddParam("slug", reqSlug)
+
+			h.CreateComment(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.CommentResponse](t, w.Result())
+				assert.Equal(t, &tt.reqParentID, actualBody.ParentID, tt.title)
+				assert.Equal(t, tt.expectedDepth, actualBody.Depth, tt.title)
+				t.Cleanup(func() { deleteComment(t, lct.DB(), actualBody.ID) })
+			}
+		}
+
+		getComments := func(t *testing.T, query string) *httptest.ResponseRecorder {
+			t.Helper()
+
+			reqSlug := strconv.Itoa(int(barArticle.ID))
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/comments%s", reqSlug, query)
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+			ctx.AddParam("slug", reqSlug)
+
+			h.GetComments(ctx)
+			return w
+		}
+
+		t.Run("flat format", func(t *testing.T) {
+			w := getComments(t, "")
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			actualBody := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+			assert.Len(t, actualBody.Comments, lct.Environ().CommentMaxDepth+2)
+
+			assert.Equal(t, root.ID, actualBody.Comments[0].ID)
+			assert.Equal(t, int64(2), actualBody.Comments[0].RepliesCount)
+			for i, c := range actualBody.Comments[1 : lct.Environ().CommentMaxDepth+1] {
+				assert.Equal(t, i+1, c.Depth)
+				assert.Empty(t, c.Replies)
+			}
+		})
+
+		t.Run("tree format", func(t *testing.T) {
+			w := getComments(t, "?format=tree")
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			actualBody := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+			assert.Len(t, actualBody.Comments, 1)
+
+			thread := actualBody.Comments[0]
+			assert.Equal(t, root.ID, thread.ID)
+			assert.Len(t, thread.Replies, 2)
+
+			depth := 0
+			for c := thread; len(c.Replies) > 0; c = c.Replies[0] {
+				depth++
+			}
+			assert.Equal(t, lct.Environ().CommentMaxDepth, depth)
+		})
+
+		t.Run("invalid format", func(t *testing.T) {
+			w := getComments(t, "?format=nested")
+			assert.Equal(t, http.StatusBadRequest, w.Result().StatusCode)
+
+			actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+			assert.Equal(t, map[string]interface{}{"error": "validation error: format: must be one of flat, tree."}, actualBody)
+		})
+
+		t.Run("deleted parent", func(t *testing.T) {
+			reqSlug := strconv.Itoa(int(barArticle.ID))
+			reqID := strconv.Itoa(int(root.ID))
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/comments/%v", reqSlug, reqID)
+			req := httptest.NewRequest(http.MethodDelete, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+			ctx.AddParam("slug", reqSlug)
+			ctx.AddParam("id", reqID)
+
+			h.DeleteComment(ctx)
+			assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+			w = getComments(t, "?format=tree")
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+			actualBody := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+			assert.Len(t, actualBody.Comments, 1)
+
+			placeholder := actualBody.Comments[0]
+			assert.Equal(t, root.ID, placeholder.ID)
+			assert.True(t, placeholder.Deleted)
+			assert.Equal(t, model.DeletedCommentBody, placeholder.Body)
+			assert.Empty(t, placeholder.Author.Username)
+			assert.Len(t, placeholder.Replies, 2)
+		})
+	})
+
+	t.Run("UpdateComment", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+
+		fooComment := createRandomComment(t, lct.DB(), barArticle.ID, fooUser.ID)
+		barComment := createRandomComment(t, lct.DB(), barArticle.ID, barUser.ID)
+
+		oldComment := createRandomComment(t, lct.DB(), barArticle.ID, fooUser.ID)
+		_, err := lct.DB().Exec(
+			"UPDATE article_management.comments SET created_at = $1 WHERE id = $2",
+			time.Now().Add(-lct.Environ().CommentEditWindow-time.Minute), oldComment.ID,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		randStr := test.RandomString(t, 20)
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqSlug            string
+			reqID              string
+			reqBody            *message.UpdateCommentRequest
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"update comment: success",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"update comment: wrong current user id",
+				&model.User{ID: 0},
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusNotFound,
+				map[string]interface{}{"error": "current user not found"},
+				true,
+			},
+			{
+				"update comment: invalid comment id",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				"invalid_id",
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid comment id"},
+				true,
+			},
+			{
+				"update comment: wrong comment id",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				"0",
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusNotFound,
+				map[string]interface{}{"error": "comment not found"},
+				true,
+			},
+			{
+				"update comment: comment is not from the article",
+				fooUser,
+				strconv.Itoa(int(fooArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "the comment is not from this article"},
+				true,
+			},
+			{
+				"update comment: forbidden to update other user's comment",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(barComment.ID)),
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"update comment: past edit window",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(oldComment.ID)),
+				&message.UpdateCommentRequest{Body: randStr},
+				http.StatusForbidden,
+				map[string]interface{}{"error": "comment can no longer be edited"},
+				true,
+			},
+			{
+				"update comment: no body",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				&message.UpdateCommentRequest{Body: ""},
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "validation error: Body: cannot be blank."},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(tt.reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/comments/%v", tt.reqSlug, tt.reqID)
+			req := httptest.NewRequest(http.MethodPut, apiUrl, bytes.NewReader(body))
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("slug", tt.reqSlug)
+			ctx.AddParam("id", tt.reqID)
+
+			h.UpdateComment(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.CommentResponse](t, w.Result())
+				assert.Equal(t, tt.reqBody.Body, actualBody.Body, tt.title)
+				assert.True(t, actualBody.Edited, tt.title)
+				assert.NotNil(t, actualBody.EditedAt, tt.title)
+				assert.NotEqual(t, actualBody.CreatedAt, actualBody.UpdatedAt, tt.title)
+			}
+		}
+
+		revisions, err := h.as.GetCommentRevisions(context.Background(), fooComment)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 1)
+		assert.Equal(t, fooComment.Body, revisions[0].Body)
+	})
+
+	t.Run("GetCommentRevisions", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		lct.Environ().Moderators = []string{barUser.Username}
+		t.Cleanup(func() {
+			lct.Environ().Moderators = nil
+		})
+
+		barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+		fooComment := createRandomComment(t, lct.DB(), barArticle.ID, fooUser.ID)
+
+		bodies := []string{fooComment.Body}
+		for i := 0; i < 2; i++ {
+			fooComment.Body = test.RandomString(t, 20)
+			_, err := h.as.UpdateComment(context.Background(), fooComment)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			bodies = append(bodies, fooComment.Body)
+		}
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqSlug            string
+			reqID              string
+			expectedStatusCode int
+			expectedBodies     []string
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"get comment revisions: success",
+				barUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				http.StatusOK,
+				bodies[:2],
+				nil,
+				false,
+			},
+			{
+				"get comment revisions: forbidden for non-moderator",
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				strconv.Itoa(int(fooComment.ID)),
+				http.StatusForbidden,
+				nil,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"get comment revisions: wrong comment id",
+				barUser,
+				strconv.Itoa(int(barArticle.ID)),
+				"0",
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "comment not found"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/comments/%v/revisions", tt.reqSlug, tt.reqID)
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("slug", tt.reqSlug)
+			ctx.AddParam("id", tt.reqID)
+
+			h.GetCommentRevisions(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.CommentRevisionsResponse](t, w.Result())
+
+				actualBodies := []string{}
+				for _, r := range actualBody.Revisions {
+					actualBodies = append(actualBodies, r.Body)
+				}
+				assert.Equal(t, tt.expectedBodies, actualBodies, tt.title)
+			}
+		}
+	})
+
 	t.Run("DeleteComment", func(t *testing.T) {
 		fooUser := createRandomUser(t, lct.DB())
 		barUser := createRandomUser(t, lct.DB())
@@ -365,3 +917,48 @@ func TestIntegration_CommentHandler(t *testing.T) {
 		}
 	})
 }
+
+func BenchmarkIntegration_CommentHandler(b *testing.B) {
+	if testing.Short() {
+		b.Skip("skipping integration benchmarks.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(b)
+
+	const pageSize = 100
+
+	author := createRandomUser(b, lct.DB())
+	reqUser := createRandomUser(b, lct.DB())
+	article := createRandomArticle(b, lct.DB(), author.ID)
+
+	for i := 0; i < pageSize; i++ {
+		commenter := createRandomUser(b, lct.DB())
+		createRandomComment(b, lct.DB(), article.ID, commenter.ID)
+
+		if i%2 == 0 {
+			err := h.us.Follow(context.Background(), reqUser, commenter)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("GetComments", func(b *testing.B) {
+		slug := strconv.Itoa(int(article.ID))
+		apiUrl := fmt.Sprintf("/api/v1/articles/%s/comments", slug)
+
+		for i := 0; i < b.N; i++ {
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(b, lct.Environ(), w, req, reqUser.ID, time.Now())
+			ctx.AddParam("slug", slug)
+
+			h.GetComments(ctx)
+
+			if w.Result().StatusCode != http.StatusOK {
+				b.Fatalf("unexpected status code: %d", w.Result().StatusCode)
+			}
+		}
+	})
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// unsubscribeTemplate is the page of unsubscribe links in digests, it only unsubscribes
+// once its form is posted so that link scanners of mail servers do not unsubscribe users
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe</title></head>
+<body style="font-family: sans-serif; line-height: 1.5; max-width: 600px; margin: 0 auto;">
+{{if .Off}}<p>You are not subscribed to a digest.</p>
+{{else}}<p>Stop getting your {{.Frequency}} digest of new articles?</p>
+<form method="post" action="{{.Action}}"><button type="submit">Unsubscribe</button></form>
+{{end}}</body>
+</html>
+`))
+
+// GetDigestSubscription gets how often current user gets an email digest
+func (h *Handler) GetDigestSubscription(ctx *gin.Context) {
+	h.logger.Info().Msg("get digest subscription")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	sub, err := h.ds.GetByUser(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get digest subscription"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, sub.ResponseDigestSubscription())
+}
+
+// UpdateDigestSubscription sets how often current user gets an email digest
+func (h *Handler) UpdateDigestSubscription(ctx *gin.Context) {
+	h.logger.Info().Msg("update digest subscription")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	var req message.UpdateDigestSubscriptionRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	sub, err := model.NewDigestSubscription(currentUser.ID, req.Frequency)
+	if err != nil {
+		msg := "failed to update digest subscription"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = sub.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedSub, err := h.ds.Subscribe(ctx.Request.Context(), &sub)
+	if err != nil {
+		msg := "failed to update digest subscription"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, updatedSub.ResponseDigestSubscription())
+}
+
+// ConfirmUnsubscribeDigest renders a page confirming to turn off digest subscription of the token
+// in unsubscribe links of digests, it is public so that the link works without login
+func (h *Handler) ConfirmUnsubscribeDigest(ctx *gin.Context) {
+	h.logger.Info().Msg("confirm unsubscribe digest")
+
+	token := ctx.Query("token")
+	if token == "" {
+		msg := "invalid unsubscribe token"
+		h.logger.Error().Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	sub, err := h.ds.GetByUnsubscribeToken(ctx.Request.Context(), token)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("digest subscription not found")
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "digest subscription not found"})
+		return
+	}
+
+	var page bytes.Buffer
+	err = unsubscribeTemplate.Execute(&page, map[string]interface{}{
+		"Off":       sub.Frequency == model.DigestFrequencyOff,
+		"Frequency": sub.Frequency,
+		"Action":    "?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		msg := "failed to render unsubscribe page"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// token in the url is not leaked to other sites
+	ctx.Header("Referrer-Policy", "no-referrer")
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+}
+
+// UnsubscribeDigest turns off digest subscription of the token, posted by the confirmation page
+// of unsubscribe links or by one-click unsubscribe of mail clients (RFC 8058)
+func (h *Handler) UnsubscribeDigest(ctx *gin.Context) {
+	h.logger.Info().Msg("unsubscribe digest")
+
+	token := ctx.Query("token")
+	if token == "" {
+		msg := "invalid unsubscribe token"
+		h.logger.Error().Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	sub, err := h.ds.Unsubscribe(ctx.Request.Context(), token)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("digest subscription not found")
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "digest subscription not found"})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, sub.ResponseDigestSubscription())
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_DigestHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	fooUser := createRandomUser(t, lct.DB())
+
+	getDigestSubscription := func(t *testing.T, user *model.User) message.DigestSubscriptionResponse {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/digest", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+
+		h.GetDigestSubscription(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		return test.GetResponseBody[message.DigestSubscriptionResponse](t, w.Result())
+	}
+
+	t.Run("GetDigestSubscription", func(t *testing.T) {
+		resp := getDigestSubscription(t, fooUser)
+		assert.Equal(t, model.DigestFrequencyOff, resp.Frequency)
+		assert.Nil(t, resp.NextDigestAt)
+	})
+
+	t.Run("UpdateDigestSubscription", func(t *testing.T) {
+		tests := []struct {
+			title              string
+			reqBody            *message.UpdateDigestSubscriptionRequest
+			expectedStatusCode int
+			expectedFrequency  string
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"update digest subscription: daily",
+				&message.UpdateDigestSubscriptionRequest{Frequency: model.DigestFrequencyDaily},
+				http.StatusOK,
+				model.DigestFrequencyDaily,
+				nil,
+				false,
+			},
+			{
+				"update digest subscription: weekly",
+				&message.UpdateDigestSubscriptionRequest{Frequency: model.DigestFrequencyWeekly},
+				http.StatusOK,
+				model.DigestFrequencyWeekly,
+				nil,
+				false,
+			},
+			{
+				"update digest subscription: unknown frequency",
+				&message.UpdateDigestSubscriptionRequest{Frequency: "hourly"},
+				http.StatusBadRequest,
+				"",
+				map[string]interface{}{
+					"error": "validation error: Frequency: must be a valid value.",
+				},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(tt.reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/me/digest", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+			h.UpdateDigestSubscription(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.DigestSubscriptionResponse](t, w.Result())
+				assert.Equal(t, tt.expectedFrequency, actualBody.Frequency, tt.title)
+				assert.NotNil(t, actualBody.NextDigestAt, tt.title)
+			}
+		}
+
+		resp := getDigestSubscription(t, fooUser)
+		assert.Equal(t, model.DigestFrequencyWeekly, resp.Frequency)
+	})
+
+	t.Run("ConfirmUnsubscribeDigest", func(t *testing.T) {
+		sub, err := h.ds.GetByUser(context.Background(), fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			title              string
+			token              string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"confirm unsubscribe digest: link",
+				sub.UnsubscribeToken,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"confirm unsubscribe digest: unknown token",
+				"unknown",
+				http.StatusNotFound,
+				map[string]interface{}{"error": "digest subscription not found"},
+				true,
+			},
+			{
+				"confirm unsubscribe digest: no token",
+				"",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid unsubscribe token"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/digest/unsubscribe", nil)
+
+			q := req.URL.Query()
+			q.Add("token", tt.token)
+			req.URL.RawQuery = q.Encode()
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = req
+
+			h.ConfirmUnsubscribeDigest(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				assert.Equal(t, "text/html; charset=utf-8", w.Result().Header.Get("Content-Type"), tt.title)
+				assert.Contains(t, w.Body.String(), `<form method="post" action="?token=`+url.QueryEscape(tt.token)+`">`, tt.title)
+			}
+		}
+
+		// following the link does not unsubscribe
+		resp := getDigestSubscription(t, fooUser)
+		assert.Equal(t, model.DigestFrequencyWeekly, resp.Frequency)
+	})
+
+	t.Run("UnsubscribeDigest", func(t *testing.T) {
+		sub, err := h.ds.GetByUser(context.Background(), fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			title              string
+			token              string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"unsubscribe digest: one-click",
+				sub.UnsubscribeToken,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"unsubscribe digest: confirmed, again",
+				sub.UnsubscribeToken,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"unsubscribe digest: unknown token",
+				"unknown",
+				http.StatusNotFound,
+				map[string]interface{}{"error": "digest subscription not found"},
+				true,
+			},
+			{
+				"unsubscribe digest: no token",
+				"",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid unsubscribe token"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/digest/unsubscribe", nil)
+
+			q := req.URL.Query()
+			q.Add("token", tt.token)
+			req.URL.RawQuery = q.Encode()
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = req
+
+			h.UnsubscribeDigest(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.DigestSubscriptionResponse](t, w.Result())
+				assert.Equal(t, model.DigestFrequencyOff, actualBody.Frequency, tt.title)
+				assert.Nil(t, actualBody.NextDigestAt, tt.title)
+			}
+		}
+
+		resp := getDigestSubscription(t, fooUser)
+		assert.Equal(t, model.DigestFrequencyOff, resp.Frequency)
+	})
+}
@@ -0,0 +1,270 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/feed"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+const feedLimit = 20
+
+// GetArticlesFeed gets newest articles globally as an Atom or RSS feed
+func (h *Handler) GetArticlesFeed(ctx *gin.Context) {
+	h.logger.Info().Msg("get articles feed")
+
+	_, format, err := feed.SplitName(path.Base(ctx.Request.URL.Path))
+	if err != nil {
+		msg := "feed not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	filter := model.ArticleFilter{TagMatch: model.TagMatchAny, Sort: model.ArticleSortNewest}
+
+	articles, _, err := h.as.GetArticles(ctx.Request.Context(), filter, model.Page{Limit: feedLimit})
+	if err != nil {
+		msg := "failed to get articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	h.ServeArticlesFeed(ctx, format, "Articles", "Newest articles", h.appURL("/articles"), articles)
+}
+
+// GetTagFeed gets newest articles of a tag as an Atom or RSS feed
+func (h *Handler) GetTagFeed(ctx *gin.Context) {
+	h.logger.Info().Msg("get tag feed")
+
+	tag, format, err := feed.SplitName(ctx.Param("tag"))
+	if err != nil {
+		msg := "feed not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	filter := model.ArticleFilter{Tags: []string{tag}, TagMatch: model.TagMatchAny, Sort: model.ArticleSortNewest}
+
+	articles, _, err := h.as.GetArticles(ctx.Request.Context(), filter, model.Page{Limit: feedLimit})
+	if err != nil {
+		msg := "failed to get articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	link := h.appURL("/articles?tag=" + url.QueryEscape(tag))
+	h.ServeArticlesFeed(ctx, format, fmt.Sprintf("Articles tagged %s", tag), fmt.Sprintf("Newest articles tagged %s", tag), link, articles)
+}
+
+// GetProfileFeed gets newest articles of a user as an Atom or RSS feed
+func (h *Handler) GetProfileFeed(ctx *gin.Context) {
+	h.logger.Info().Msg("get profile feed")
+
+	username, format, err := feed.SplitName(ctx.Param("username"))
+	if err != nil {
+		msg := "feed not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	user, err := h.us.GetByUsername(ctx.Request.Context(), username)
+	if err != nil {
+		msg := "user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	filter := model.ArticleFilter{Authors: []string{user.Username}, TagMatch: model.TagMatchAny, Sort: model.ArticleSortNewest}
+
+	articles, _, err := h.as.GetArticles(ctx.Request.Context(), filter, model.Page{Limit: feedLimit})
+	if err != nil {
+		msg := "failed to get articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	link := h.appURL("/profiles/" + url.PathEscape(user.Username))
+	h.ServeArticlesFeed(ctx, format, fmt.Sprintf("Articles by %s", user.Username), user.Bio, link, articles)
+}
+
+// GetPrivateFeed gets recent articles from users and tags that the owner of the feed token follows
+// as an Atom or RSS feed, the token in url stands in for login so that feed readers can get it
+func (h *Handler) GetPrivateFeed(ctx *gin.Context) {
+	h.logger.Info().Msg("get private feed")
+
+	token, format, err := feed.SplitName(ctx.Param("token"))
+	if err != nil {
+		msg := "feed not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	user, err := h.us.GetByFeedToken(ctx.Request.Context(), token)
+	if err != nil {
+		msg := "feed not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	filter := model.FeedFilter{Source: model.FeedSourceAll}
+
+	err = h.SetFeedSources(ctx, user, &filter)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to get feed sources of user %d", user.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to get followers"})
+		return
+	}
+
+	articles, _, err := h.as.GetFeedArticles(ctx.Request.Context(), filter, model.Page{Limit: feedLimit})
+	if err != nil {
+		msg := "failed to search articles from user's followers"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// the url holds a secret, so the feed must not be kept by shared caches
+	ctx.Header("Cache-Control", "private")
+
+	title := fmt.Sprintf("Feed of %s", user.Username)
+	h.ServeArticlesFeed(ctx, format, title, "Recent articles from users and tags you follow", h.appURL("/articles/feed"), articles)
+}
+
+// GetFeedToken gets private feed urls of current user
+func (h *Handler) GetFeedToken(ctx *gin.Context) {
+	h.logger.Info().Msg("get feed token")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	token, err := h.us.GetFeedToken(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get feed token"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, token.ResponseFeedToken(h.appURL("/feeds/private/"+token.Token)))
+}
+
+// RotateFeedToken replaces private feed token of current user, revoking its previous feed urls
+func (h *Handler) RotateFeedToken(ctx *gin.Context) {
+	h.logger.Info().Msg("rotate feed token")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	newToken, err := model.NewFeedToken(currentUser.ID)
+	if err != nil {
+		msg := "failed to rotate feed token"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	token, err := h.us.RotateFeedToken(ctx.Request.Context(), &newToken)
+	if err != nil {
+		msg := "failed to rotate feed token"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, token.ResponseFeedToken(h.appURL("/feeds/private/"+token.Token)))
+}
+
+// ServeArticlesFeed serves articles as a feed in the format with their rendered bodies
+//
+// Feeds are served with an ETag of their content and a Last-Modified time of
+// their last updated article, so that If-None-Match and If-Modified-Since
+// requests of feed readers get 304 Not Modified when nothing changed.
+func (h *Handler) ServeArticlesFeed(ctx *gin.Context, format, title, description, link string, articles []model.Article) {
+	f := feed.Feed{
+		ID:          h.environ.AppBaseURL + ctx.Request.URL.Path,
+		Title:       title,
+		Description: description,
+		Link:        link,
+		Entries:     make([]feed.Entry, 0, len(articles)),
+	}
+
+	for i := range articles {
+		article := &articles[i]
+
+		err := h.renderer.RenderArticle(article)
+		if err != nil {
+			msg := "failed to render article body"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		categories := make([]string, 0, len(article.Tags))
+		for _, t := range article.Tags {
+			categories = append(categories, t.Name)
+		}
+
+		articleURL := h.appURL(fmt.Sprintf("/articles/%d", article.ID))
+		f.Entries = append(f.Entries, feed.Entry{
+			ID:          articleURL,
+			Title:       article.Title,
+			Link:        articleURL,
+			Author:      article.Author.Username,
+			Summary:     article.Description,
+			ContentHTML: article.Rendered.HTML,
+			Categories:  categories,
+			Published:   article.CreatedAt,
+			Updated:     article.UpdatedAt,
+		})
+
+		if article.UpdatedAt.After(f.Updated) {
+			f.Updated = article.UpdatedAt
+		}
+	}
+
+	b, contentType, err := f.Encode(format)
+	if err != nil {
+		msg := "failed to encode feed"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	sum := sha256.Sum256(b)
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("ETag", fmt.Sprintf(`"%s"`, hex.EncodeToString(sum[:16])))
+
+	http.ServeContent(ctx.Writer, ctx.Request, "", f.Updated, bytes.NewReader(b))
+	ctx.Abort()
+}
+
+// appURL returns public url of an api path
+func (h *Handler) appURL(apiPath string) string {
+	return h.environ.AppBaseURL + APIGroupPath + apiPath
+}
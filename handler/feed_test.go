@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/feed"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_FeedHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	fooUser := createRandomUser(t, lct.DB())
+	barUser := createRandomUser(t, lct.DB())
+
+	fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+
+	err := h.us.Follow(context.Background(), barUser, fooUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type atomEntry struct {
+		ID      string `xml:"id"`
+		Title   string `xml:"title"`
+		Content string `xml:"content"`
+	}
+
+	type atomFeed struct {
+		Title   string      `xml:"title"`
+		Entries []atomEntry `xml:"entry"`
+	}
+
+	getFeed := func(t *testing.T, handle gin.HandlerFunc, urlPath, param, value string, header http.Header) *http.Response {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, urlPath, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = req
+		if param != "" {
+			ctx.AddParam(param, value)
+		}
+
+		handle(ctx)
+
+		return w.Result()
+	}
+
+	decodeAtom := func(t *testing.T, resp *http.Response) atomFeed {
+		t.Helper()
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var f atomFeed
+		err = xml.Unmarshal(b, &f)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return f
+	}
+
+	articleURL := fmt.Sprintf("%s/api/v1/articles/%d", lct.Environ().AppBaseURL, fooArticle.ID)
+	tag := fooArticle.Tags[0].Name
+
+	t.Run("GetArticlesFeed", func(t *testing.T) {
+		resp := getFeed(t, h.GetArticlesFeed, "/api/v1/feeds/articles.atom", "", "", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, feed.ContentTypeAtom, resp.Header.Get("Content-Type"))
+
+		f := decodeAtom(t, resp)
+		assert.Equal(t, "Articles", f.Title)
+		assert.NotEmpty(t, f.Entries)
+
+		resp = getFeed(t, h.GetArticlesFeed, "/api/v1/feeds/articles.rss", "", "", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, feed.ContentTypeRSS, resp.Header.Get("Content-Type"))
+	})
+
+	t.Run("GetTagFeed", func(t *testing.T) {
+		resp := getFeed(t, h.GetTagFeed, "/api/v1/feeds/tags/"+tag+".atom", "tag", tag+".atom", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		f := decodeAtom(t, resp)
+		if assert.Len(t, f.Entries, 1) {
+			assert.Equal(t, articleURL, f.Entries[0].ID)
+			assert.Equal(t, fooArticle.Title, f.Entries[0].Title)
+			assert.Equal(t, fmt.Sprintf("<p>%s</p>\n", fooArticle.Body), f.Entries[0].Content)
+		}
+
+		resp = getFeed(t, h.GetTagFeed, "/api/v1/feeds/tags/"+tag+".json", "tag", tag+".json", nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("GetProfileFeed", func(t *testing.T) {
+		name := fooUser.Username + ".rss"
+		resp := getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, feed.ContentTypeRSS, resp.Header.Get("Content-Type"))
+
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Contains(t, string(b), fmt.Sprintf("<guid isPermaLink=\"true\">%s</guid>", articleURL))
+
+		name = test.RandomString(t, 10) + ".rss"
+		resp = getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "user not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
+
+	t.Run("ConditionalRequests", func(t *testing.T) {
+		name := fooUser.Username + ".atom"
+		resp := getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		etag := resp.Header.Get("ETag")
+		lastModified := resp.Header.Get("Last-Modified")
+		assert.NotEmpty(t, etag)
+		assert.Equal(t, fooArticle.UpdatedAt.UTC().Format(http.TimeFormat), lastModified)
+
+		resp = getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		resp = getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, http.Header{"If-Modified-Since": {lastModified}})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+		// a new article changes the feed
+		createRandomArticle(t, lct.DB(), fooUser.ID)
+
+		resp = getFeed(t, h.GetProfileFeed, "/api/v1/feeds/profiles/"+name, "username", name, http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	})
+
+	t.Run("PrivateFeed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/feed_token", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+
+		h.GetFeedToken(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		token := test.GetResponseBody[message.FeedTokenResponse](t, w.Result())
+		assert.Equal(t, fmt.Sprintf("%s/api/v1/feeds/private/%s.atom", lct.Environ().AppBaseURL, token.Token), token.AtomURL)
+		assert.Equal(t, fmt.Sprintf("%s/api/v1/feeds/private/%s.rss", lct.Environ().AppBaseURL, token.Token), token.RSSURL)
+
+		// the token is kept
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/me/feed_token", nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+
+		h.GetFeedToken(ctx)
+
+		assert.Equal(t, token, test.GetResponseBody[message.FeedTokenResponse](t, w.Result()))
+
+		name := token.Token + ".atom"
+		resp := getFeed(t, h.GetPrivateFeed, "/api/v1/feeds/private/"+name, "token", name, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "private", resp.Header.Get("Cache-Control"))
+
+		f := decodeAtom(t, resp)
+		if assert.NotEmpty(t, f.Entries) {
+			assert.Equal(t, articleURL, f.Entries[len(f.Entries)-1].ID)
+		}
+
+		// rotating revokes the previous url
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/me/feed_token", nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+
+		h.RotateFeedToken(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		rotated := test.GetResponseBody[message.FeedTokenResponse](t, w.Result())
+		assert.NotEqual(t, token.Token, rotated.Token)
+
+		resp = getFeed(t, h.GetPrivateFeed, "/api/v1/feeds/private/"+name, "token", name, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+		name = rotated.Token + ".rss"
+		resp = getFeed(t, h.GetPrivateFeed, "/api/v1/feeds/private/"+name, "token", name, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
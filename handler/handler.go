@@ -3,20 +3,50 @@ package handler
 import (
 	"github.com/nathanbizkit/article-management-go/auth"
 	"github.com/nathanbizkit/article-management-go/env"
+	"github.com/nathanbizkit/article-management-go/imaging"
+	"github.com/nathanbizkit/article-management-go/markdown"
+	"github.com/nathanbizkit/article-management-go/realtime"
+	"github.com/nathanbizkit/article-management-go/storage"
 	"github.com/nathanbizkit/article-management-go/store"
 	"github.com/rs/zerolog"
 )
 
 // Handler definition
 type Handler struct {
-	logger  *zerolog.Logger
-	environ *env.ENV
-	authen  *auth.Auth
-	us      *store.UserStore
-	as      *store.ArticleStore
+	logger   *zerolog.Logger
+	environ  *env.ENV
+	authen   *auth.Auth
+	us       *store.UserStore
+	as       *store.ArticleStore
+	ms       *store.MediaStore
+	ns       *store.NotificationStore
+	ws       *store.WebhookStore
+	ds       *store.DigestStore
+	rs       *store.RecommendationStore
+	ts       *store.TrendingStore
+	st       storage.Storage
+	ip       *imaging.Processor
+	hub      *realtime.Hub
+	renderer *markdown.Renderer
 }
 
-// New returns a new handler with logger, env, auth and stores
-func New(l *zerolog.Logger, environ *env.ENV, authen *auth.Auth, us *store.UserStore, as *store.ArticleStore) *Handler {
-	return &Handler{logger: l, environ: environ, authen: authen, us: us, as: as}
+// New returns a new handler with logger, env, auth, stores, media storage, media processor and stream hub
+func New(l *zerolog.Logger, environ *env.ENV, authen *auth.Auth, us *store.UserStore, as *store.ArticleStore, ms *store.MediaStore, ns *store.NotificationStore, ws *store.WebhookStore, ds *store.DigestStore, rs *store.RecommendationStore, ts *store.TrendingStore, st storage.Storage, ip *imaging.Processor, hub *realtime.Hub) *Handler {
+	return &Handler{
+		logger:   l,
+		environ:  environ,
+		authen:   authen,
+		us:       us,
+		as:       as,
+		ms:       ms,
+		ns:       ns,
+		ws:       ws,
+		ds:       ds,
+		rs:       rs,
+		ts:       ts,
+		st:       st,
+		ip:       ip,
+		hub:      hub,
+		renderer: markdown.NewRenderer(markdown.DefaultCacheSize),
+	}
 }
@@ -12,7 +12,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/nathanbizkit/article-management-go/auth"
 	"github.com/nathanbizkit/article-management-go/env"
+	"github.com/nathanbizkit/article-management-go/imaging"
 	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/outbox"
+	"github.com/nathanbizkit/article-management-go/realtime"
+	"github.com/nathanbizkit/article-management-go/storage"
 	"github.com/nathanbizkit/article-management-go/store"
 	"github.com/nathanbizkit/article-management-go/test"
 	"github.com/nathanbizkit/article-management-go/test/container"
@@ -20,7 +24,7 @@ import (
 
 const userPassword = "P@55w0rD!"
 
-func setup(t *testing.T) (*Handler, *container.LocalTestContainer) {
+func setup(t testing.TB) (*Handler, *container.LocalTestContainer) {
 	t.Helper()
 
 	l := test.NewTestLogger(t)
@@ -30,11 +34,27 @@ func setup(t *testing.T) (*Handler, *container.LocalTestContainer) {
 	authen := auth.New(environ)
 	as := store.NewArticleStore(lct.DB())
 	us := store.NewUserStore(lct.DB())
+	ms := store.NewMediaStore(lct.DB())
+	ns := store.NewNotificationStore(lct.DB())
+	ws := store.NewWebhookStore(lct.DB())
+	ds := store.NewDigestStore(lct.DB())
+	rs := store.NewRecommendationStore(lct.DB())
+	ts := store.NewTrendingStore(lct.DB())
+	ss := store.NewStreamStore(lct.DB())
+
+	st, err := storage.NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ip := imaging.NewProcessor(&l, ms, st, imaging.DefaultQueueSize)
+
+	hub := realtime.NewHub(&l, ss, environ.StreamRetention, realtime.DefaultBufferSize)
 
-	return New(&l, environ, authen, us, as), lct
+	return New(&l, environ, authen, us, as, ms, ns, ws, ds, rs, ts, st, ip, hub), lct
 }
 
-func ctxWithToken(t *testing.T, e *env.ENV, w http.ResponseWriter, req *http.Request, id uint, timeNow time.Time) (*gin.Context, *auth.AuthToken) {
+func ctxWithToken(t testing.TB, e *env.ENV, w http.ResponseWriter, req *http.Request, id uint, timeNow time.Time) (*gin.Context, *auth.AuthToken) {
 	t.Helper()
 
 	authen := auth.New(e)
@@ -55,7 +75,7 @@ func ctxWithToken(t *testing.T, e *env.ENV, w http.ResponseWriter, req *http.Req
 	return ctx, token
 }
 
-func createRandomUser(t *testing.T, db *sql.DB) *model.User {
+func createRandomUser(t testing.TB, db *sql.DB) *model.User {
 	t.Helper()
 
 	randStr := test.RandomString(t, 10)
@@ -82,7 +102,7 @@ func createRandomUser(t *testing.T, db *sql.DB) *model.User {
 	return user
 }
 
-func deleteUser(t *testing.T, db *sql.DB, id uint) {
+func deleteUser(t testing.TB, db *sql.DB, id uint) {
 	t.Helper()
 
 	queryString := `DELETE FROM article_management.users WHERE id = $1`
@@ -92,7 +112,7 @@ func deleteUser(t *testing.T, db *sql.DB, id uint) {
 	}
 }
 
-func createRandomArticle(t *testing.T, db *sql.DB, userID uint) *model.Article {
+func createRandomArticle(t testing.TB, db *sql.DB, userID uint) *model.Article {
 	t.Helper()
 
 	randStr := test.RandomString(t, 15)
@@ -120,7 +140,7 @@ func createRandomArticle(t *testing.T, db *sql.DB, userID uint) *model.Article {
 	return article
 }
 
-func deleteArticle(t *testing.T, db *sql.DB, id uint) {
+func deleteArticle(t testing.TB, db *sql.DB, id uint) {
 	t.Helper()
 
 	as := store.NewArticleStore(db)
@@ -130,7 +150,24 @@ func deleteArticle(t *testing.T, db *sql.DB, id uint) {
 	}
 }
 
-func createRandomComment(t *testing.T, db *sql.DB, articleID uint, userID uint) *model.Comment {
+// relayOutbox relays domain events written so far to their consumers, as the server does in the background
+func relayOutbox(t testing.TB, db *sql.DB) {
+	t.Helper()
+
+	l := test.NewTestLogger(t)
+
+	relay := outbox.NewRelay(&l, store.NewOutboxStore(db), outbox.DefaultRetention)
+	relay.Subscribe(outbox.ConsumerNotifications, store.NewNotificationStore(db).ConsumeEvent)
+	relay.Subscribe(outbox.ConsumerWebhooks, store.NewWebhookStore(db).ConsumeEvent)
+	relay.Subscribe(outbox.ConsumerTrending, store.NewTrendingStore(db).ConsumeEvent)
+
+	err := relay.RelayAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func createRandomComment(t testing.TB, db *sql.DB, articleID uint, userID uint) *model.Comment {
 	t.Helper()
 
 	randStr := test.RandomString(t, 20)
@@ -153,7 +190,32 @@ func createRandomComment(t *testing.T, db *sql.DB, articleID uint, userID uint)
 	return comment
 }
 
-func deleteComment(t *testing.T, db *sql.DB, id uint) {
+func createRandomReply(t testing.TB, db *sql.DB, parent *model.Comment, userID uint) *model.Comment {
+	t.Helper()
+
+	randStr := test.RandomString(t, 20)
+	m := model.Comment{
+		Body:      randStr,
+		ArticleID: parent.ArticleID,
+		UserID:    userID,
+		ParentID:  &parent.ID,
+		Depth:     parent.Depth + 1,
+	}
+
+	as := store.NewArticleStore(db)
+	comment, err := as.CreateComment(context.Background(), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		deleteComment(t, db, comment.ID)
+	})
+
+	return comment
+}
+
+func deleteComment(t testing.TB, db *sql.DB, id uint) {
 	t.Helper()
 
 	as := store.NewArticleStore(db)
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
 	"github.com/nathanbizkit/article-management-go/model"
 )
 
@@ -13,6 +15,11 @@ func (h *Handler) GetCurrentUserFromContext(ctx *gin.Context) (*model.User, erro
 	return h.us.GetByID(ctx.Request.Context(), h.authen.GetContextUserID(ctx))
 }
 
+// IsModerator returns whether user is one of the configured moderators
+func (h *Handler) IsModerator(user *model.User) bool {
+	return slices.Contains(h.environ.Moderators, user.Username)
+}
+
 // GetIDFromParam returns param value as uint id from url parameters or abort
 func (h *Handler) GetIDFromParam(ctx *gin.Context, key string) (uint, error) {
 	value := ctx.Param(key)
@@ -28,7 +35,8 @@ func (h *Handler) GetIDFromParam(ctx *gin.Context, key string) (uint, error) {
 	return uint(id), nil
 }
 
-// GetPaginationQuery returns limit and offset queries from url
+// GetPaginationQuery returns limit and offset queries from url,
+// where a limit above the maximum of a page is clamped to the maximum
 func (h *Handler) GetPaginationQuery(ctx *gin.Context, defaultLimit, defaultOffset int64) (limit, offset int64) {
 	limit = defaultLimit
 	offset = defaultOffset
@@ -37,7 +45,7 @@ func (h *Handler) GetPaginationQuery(ctx *gin.Context, defaultLimit, defaultOffs
 	if queryLimit != "" {
 		l, err := strconv.Atoi(queryLimit)
 		if err == nil && l != 0 {
-			limit = int64(l)
+			limit = min(int64(l), model.PageMaxLimit)
 		}
 	}
 
@@ -51,3 +59,97 @@ func (h *Handler) GetPaginationQuery(ctx *gin.Context, defaultLimit, defaultOffs
 
 	return
 }
+
+// GetPageQuery returns a page from limit, offset and cursor (after, before) queries from url
+func (h *Handler) GetPageQuery(ctx *gin.Context, defaultLimit, defaultOffset int64) (model.Page, error) {
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+	return model.ParsePage(limit, offset, ctx.Query("after"), ctx.Query("before"))
+}
+
+// GetPageLinks returns links to next and previous pages of current request url
+func (h *Handler) GetPageLinks(ctx *gin.Context, info *model.PageInfo) message.LinksResponse {
+	var links message.LinksResponse
+	if info == nil {
+		return links
+	}
+
+	link := func(key string, cursor *model.Cursor) string {
+		u := *ctx.Request.URL
+
+		q := u.Query()
+		q.Del("offset")
+		q.Del("after")
+		q.Del("before")
+		q.Set(key, cursor.Encode())
+
+		u.RawQuery = q.Encode()
+		return u.RequestURI()
+	}
+
+	if info.Next != nil {
+		links.Next = link("after", info.Next)
+	}
+
+	if info.Prev != nil {
+		links.Prev = link("before", info.Prev)
+	}
+
+	return links
+}
+
+// SetDetails sets image variants, reactions and mentions of articles and comments, where image
+// variants are of authors too and reactions are marked for those user reacted with
+//
+// The error tells which of them failed to be got.
+func (h *Handler) SetDetails(ctx *gin.Context, user *model.User, articles []*model.Article, comments []*model.Comment) error {
+	authors := make([]*model.User, 0, len(comments))
+	for _, c := range comments {
+		authors = append(authors, &c.Author)
+	}
+
+	err := h.SetImageVariants(ctx, authors, articles)
+	if err != nil {
+		return fmt.Errorf("failed to get image variants: %w", err)
+	}
+
+	err = h.SetReactions(ctx, user, articles, comments)
+	if err != nil {
+		return fmt.Errorf("failed to get reactions: %w", err)
+	}
+
+	err = h.SetMentions(ctx, articles, comments)
+	if err != nil {
+		return fmt.Errorf("failed to get mentions: %w", err)
+	}
+
+	return nil
+}
+
+// SetFeedSources sets viewer, followed users and followed tags of user to feed filter
+// for the sources chosen by the filter
+func (h *Handler) SetFeedSources(ctx *gin.Context, user *model.User, filter *model.FeedFilter) error {
+	filter.Viewer = user
+
+	if filter.FromUsers() {
+		userIDs, err := h.us.GetFeedUserIDs(ctx.Request.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		filter.UserIDs = userIDs
+	}
+
+	if filter.FromTags() {
+		tags, err := h.as.GetFollowedTags(ctx.Request.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		filter.TagIDs = make([]uint, 0, len(tags))
+		for _, t := range tags {
+			filter.TagIDs = append(filter.TagIDs, t.ID)
+		}
+	}
+
+	return nil
+}
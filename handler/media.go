@@ -0,0 +1,642 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/imaging"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/storage"
+)
+
+const (
+	// multipartOverhead allows room for multipart boundaries and headers on top of file size limit
+	multipartOverhead = 1 << 20
+)
+
+// UploadMedia uploads a file (multipart form field "file") to media storage
+func (h *Handler) UploadMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("upload media")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, h.environ.MediaMaxSize+multipartOverhead)
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			msg := "file is too large"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": msg})
+			return
+		}
+
+		h.logger.Error().Err(err).Msg("failed to read uploaded file")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid file"})
+		return
+	}
+
+	if fileHeader.Size > h.environ.MediaMaxSize {
+		msg := "file is too large"
+		h.logger.Error().Int64("size", fileHeader.Size).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": msg})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to open uploaded file")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid file"})
+		return
+	}
+	defer file.Close()
+
+	// files are small enough to be handled in memory, size is bound by the limit above
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read uploaded file")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid file"})
+		return
+	}
+
+	// content type declared by client is not trusted, sniff it from content instead
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(data))
+
+	media, err := model.NewMedia(currentUser.ID, fileHeader.Filename, contentType, int64(len(data)))
+	if err != nil {
+		msg := "failed to create media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = media.Validate(h.environ.MediaMaxSize)
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	data, err = imaging.StripMetadata(media.ContentType, data)
+	if err != nil {
+		msg := "invalid image"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	media.Size = int64(len(data))
+
+	err = h.st.Put(ctx.Request.Context(), media.StorageKey, bytes.NewReader(data), media.Size, media.ContentType)
+	if err != nil {
+		msg := "failed to store media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	createdMedia, err := h.ms.Create(ctx.Request.Context(), &media)
+	if err != nil {
+		// do not leave an orphan object behind
+		if err := h.st.Delete(ctx.Request.Context(), media.StorageKey); err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to delete orphan media object (key=%s)", media.StorageKey))
+		}
+
+		msg := "failed to create media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if createdMedia.IsImage() {
+		h.ip.Enqueue(createdMedia.ID)
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusCreated, createdMedia.ResponseMedia(h.GetMediaURL(createdMedia)))
+}
+
+// GetMedia serves content of a media
+func (h *Handler) GetMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("get media")
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid media id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	media, err := h.ms.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("media (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media not found"})
+		return
+	}
+
+	visible, err := h.canViewMedia(ctx, id)
+	if err != nil {
+		msg := "failed to get visibility of media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get media (id=%d) of article of private user", h.authen.GetContextUserID(ctx), id)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	r, err := h.st.Get(ctx.Request.Context(), media.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("media object (key=%s) not found", media.StorageKey))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media not found"})
+			return
+		}
+
+		msg := "failed to get media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	defer r.Close()
+
+	// stored objects are immutable, a new upload gets a new id, but only cached privately
+	// as media of articles of private users are not visible to everyone
+	ctx.DataFromReader(http.StatusOK, media.Size, media.ContentType, r, map[string]string{
+		"Cache-Control":          "private, max-age=31536000, immutable",
+		"Content-Disposition":    mime.FormatMediaType("inline", map[string]string{"filename": media.Filename}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// GetMediaVariant serves content of a variant of media, file name of variant
+// has a hash of its content so that it can be cached forever
+func (h *Handler) GetMediaVariant(ctx *gin.Context) {
+	h.logger.Info().Msg("get media variant")
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid media id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	// file name is in form of <name>-<hash>.<ext>
+	file := ctx.Param("file")
+	name, _, found := strings.Cut(file, "-")
+	if !found {
+		msg := "invalid media variant"
+		h.logger.Error().Str("file", file).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	variant, err := h.ms.GetVariant(ctx.Request.Context(), id, name)
+	if err != nil || variant.FileName() != file {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("media variant (id=%d, file=%s) not found", id, file))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media variant not found"})
+		return
+	}
+
+	visible, err := h.canViewMedia(ctx, id)
+	if err != nil {
+		msg := "failed to get visibility of media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get media (id=%d) of article of private user", h.authen.GetContextUserID(ctx), id)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	r, err := h.st.Get(ctx.Request.Context(), variant.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("media variant object (key=%s) not found", variant.StorageKey))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media variant not found"})
+			return
+		}
+
+		msg := "failed to get media variant"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+	defer r.Close()
+
+	ctx.DataFromReader(http.StatusOK, variant.Size, variant.ContentType, r, map[string]string{
+		"Cache-Control":          "private, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// canViewMedia returns whether a media (by id) is visible to current user (if any)
+func (h *Handler) canViewMedia(ctx *gin.Context, mediaID uint) (bool, error) {
+	var currentUser *model.User
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		var err error
+		currentUser, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return h.ms.CanViewMedia(ctx.Request.Context(), currentUser, mediaID)
+}
+
+// DeleteMedia deletes a media of current user
+func (h *Handler) DeleteMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("delete media")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid media id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	media, err := h.ms.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("media (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media not found"})
+		return
+	}
+
+	if media.UserID != currentUser.ID {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to delete user's media (id=%d)", currentUser.ID, id)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	variants, err := h.ms.GetVariants(ctx.Request.Context(), []uint{media.ID})
+	if err != nil {
+		msg := "failed to get media variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.ms.Delete(ctx.Request.Context(), media)
+	if err != nil {
+		msg := "failed to delete media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	keys := []string{media.StorageKey}
+	for _, v := range variants[media.ID] {
+		keys = append(keys, v.StorageKey)
+	}
+
+	for _, key := range keys {
+		err = h.st.Delete(ctx.Request.Context(), key)
+		if err != nil {
+			// metadata is gone, so the object is unreachable anyway
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("failed to delete media object (key=%s)", key))
+		}
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// GetArticleMedia gets media attached to an article
+func (h *Handler) GetArticleMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("get article media")
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		return
+	}
+
+	var currentUser *model.User
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		currentUser, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("current user (id=%d) not found", userID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "current user not found"})
+			return
+		}
+	}
+
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get media of article (id=%d) of private user", userID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	media, err := h.ms.GetArticleMedia(ctx.Request.Context(), article)
+	if err != nil {
+		msg := "failed to get article media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.MediaResponse, 0, len(media))
+	for _, m := range media {
+		resp = append(resp, m.ResponseMedia(h.GetMediaURL(&m)))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.MediaListResponse{Media: resp})
+}
+
+// AttachArticleMedia attaches a media of current user to current user's article
+func (h *Handler) AttachArticleMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("attach article media")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	var req message.MediaRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		return
+	}
+
+	media, err := h.ms.GetByID(ctx.Request.Context(), req.MediaID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("media (id=%d) not found", req.MediaID))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media not found"})
+		return
+	}
+
+	if article.Author.ID != currentUser.ID || media.UserID != currentUser.ID {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to attach media (id=%d) to article (id=%d)", currentUser.ID, media.ID, slug)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	err = h.ms.AttachToArticle(ctx.Request.Context(), article, media)
+	if err != nil {
+		msg := "failed to attach media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, media.ResponseMedia(h.GetMediaURL(media)))
+}
+
+// DetachArticleMedia detaches a media from current user's article
+func (h *Handler) DetachArticleMedia(ctx *gin.Context) {
+	h.logger.Info().Msg("detach article media")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid media id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		return
+	}
+
+	if article.Author.ID != currentUser.ID {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to detach media from user's article (id=%d)", currentUser.ID, slug)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	err = h.ms.DetachFromArticle(ctx.Request.Context(), article, &model.Media{ID: id})
+	if err != nil {
+		msg := "failed to detach media"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// SetAvatar sets an image media of current user as current user's avatar
+func (h *Handler) SetAvatar(ctx *gin.Context) {
+	h.logger.Info().Msg("set avatar")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	var req message.MediaRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	media, err := h.ms.GetByID(ctx.Request.Context(), req.MediaID)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("media (id=%d) not found", req.MediaID))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "media not found"})
+		return
+	}
+
+	if media.UserID != currentUser.ID {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to set user's media (id=%d) as avatar", currentUser.ID, media.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	if !media.IsImage() {
+		msg := "media is not an image"
+		h.logger.Error().Str("content_type", media.ContentType).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	updatedUser, err := h.us.UpdateAvatar(ctx.Request.Context(), currentUser, media, h.GetMediaURL(media))
+	if err != nil {
+		msg := "failed to update profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, []*model.User{updatedUser}, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following := false
+	ctx.AbortWithStatusJSON(http.StatusOK, updatedUser.ResponseProfile(following))
+}
+
+// GetMediaURL returns absolute url serving content of media on the app base url,
+// it is kept in profiles so it must not depend on the host a request was sent to
+func (h *Handler) GetMediaURL(m *model.Media) string {
+	return fmt.Sprintf("%s%s/media/%d", h.environ.AppBaseURL, APIGroupPath, m.ID)
+}
+
+// GetMediaVariantURL returns absolute content-addressed url serving content of media variant
+func (h *Handler) GetMediaVariantURL(v *model.MediaVariant) string {
+	return fmt.Sprintf("%s/variants/%s", h.GetMediaURL(&model.Media{ID: v.MediaID}), v.FileName())
+}
+
+// SetImageVariants sets variant urls of avatars of users and of authors
+// and thumbnails of articles, in a query for each
+func (h *Handler) SetImageVariants(ctx *gin.Context, users []*model.User, articles []*model.Article) error {
+	allUsers := make([]*model.User, 0, len(users)+len(articles))
+	allUsers = append(allUsers, users...)
+
+	articleIDs := make([]uint, 0, len(articles))
+	for _, a := range articles {
+		allUsers = append(allUsers, &a.Author)
+		articleIDs = append(articleIDs, a.ID)
+	}
+
+	userIDs := make([]uint, 0, len(allUsers))
+	for _, u := range allUsers {
+		userIDs = append(userIDs, u.ID)
+	}
+
+	avatars, err := h.ms.GetAvatarVariants(ctx.Request.Context(), userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range allUsers {
+		u.ImageVariants = h.variantURLs(avatars[u.ID])
+	}
+
+	thumbnails, err := h.ms.GetArticleThumbnailVariants(ctx.Request.Context(), articleIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range articles {
+		a.Thumbnails = h.variantURLs(thumbnails[a.ID])
+	}
+
+	return nil
+}
+
+// variantURLs maps names of variants to their urls, nil if there is none
+func (h *Handler) variantURLs(variants []model.MediaVariant) map[string]string {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	urls := make(map[string]string, len(variants))
+	for _, v := range variants {
+		urls[v.Name] = h.GetMediaVariantURL(&v)
+	}
+
+	return urls
+}
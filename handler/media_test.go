@@ -0,0 +1,607 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/env"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+// pngContent is a small png image
+var pngContent = func() []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 160, 120)))
+	return buf.Bytes()
+}()
+
+func TestIntegration_MediaHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	t.Run("UploadMedia", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqFilename        string
+			reqContent         []byte
+			expectedStatusCode int
+			expectedBody       message.MediaResponse
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"upload media: success",
+				fooUser,
+				"avatar.png",
+				pngContent,
+				http.StatusCreated,
+				message.MediaResponse{
+					Filename:    "avatar.png",
+					ContentType: "image/png",
+					Size:        int64(len(pngContent)),
+				},
+				nil,
+				false,
+			},
+			{
+				"upload media: wrong current user id",
+				&model.User{ID: 0},
+				"avatar.png",
+				pngContent,
+				http.StatusNotFound,
+				message.MediaResponse{},
+				map[string]interface{}{"error": "current user not found"},
+				true,
+			},
+			{
+				"upload media: content type is sniffed, not taken from filename",
+				fooUser,
+				"avatar.png",
+				[]byte("<html><script>alert(1)</script></html>"),
+				http.StatusBadRequest,
+				message.MediaResponse{},
+				map[string]interface{}{"error": "validation error: ContentType: must be an image (jpeg, png, gif, webp) or pdf."},
+				true,
+			},
+			{
+				"upload media: file is too large",
+				fooUser,
+				"large.png",
+				append(append([]byte{}, pngContent...), make([]byte, lct.Environ().MediaMaxSize)...),
+				http.StatusRequestEntityTooLarge,
+				message.MediaResponse{},
+				map[string]interface{}{"error": "file is too large"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			req := newUploadRequest(t, tt.reqFilename, tt.reqContent)
+			// urls are built from the app base url, not from the host of the request
+			req.Host = "attacker.example"
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+
+			h.UploadMedia(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.MediaResponse](t, w.Result())
+				assert.NotEmpty(t, actualBody.ID, tt.title)
+				assert.Equal(t, fmt.Sprintf("%s/api/v1/media/%d", lct.Environ().AppBaseURL, actualBody.ID), actualBody.URL, tt.title)
+				assert.Equal(t, tt.expectedBody.Filename, actualBody.Filename, tt.title)
+				assert.Equal(t, tt.expectedBody.ContentType, actualBody.ContentType, tt.title)
+				assert.Equal(t, tt.expectedBody.Size, actualBody.Size, tt.title)
+				assert.NotEmpty(t, actualBody.CreatedAt, tt.title)
+			}
+		}
+	})
+
+	t.Run("GetMedia", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		media := uploadMedia(t, h, lct.Environ(), fooUser, "avatar.png", pngContent)
+
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/media/%d", media.ID), nil)
+		w := httptest.NewRecorder()
+		ctx, _ := gin.CreateTestContext(w)
+		ctx.Request = req
+		ctx.AddParam("id", strconv.Itoa(int(media.ID)))
+
+		h.GetMedia(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		assert.Equal(t, "image/png", w.Result().Header.Get("Content-Type"))
+		assert.Equal(t, "nosniff", w.Result().Header.Get("X-Content-Type-Options"))
+		assert.Contains(t, w.Result().Header.Get("Cache-Control"), "immutable")
+
+		content, err := io.ReadAll(w.Result().Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, pngContent, content)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/media/0", nil)
+		w = httptest.NewRecorder()
+		ctx, _ = gin.CreateTestContext(w)
+		ctx.Request = req
+		ctx.AddParam("id", "0")
+
+		h.GetMedia(ctx)
+
+		assert.Equal(t, http.StatusNotFound, w.Result().StatusCode)
+	})
+
+	t.Run("GetMediaVariant", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		media := uploadMedia(t, h, lct.Environ(), fooUser, "avatar.png", pngContent)
+
+		err := h.ip.Process(context.Background(), media.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		variants, err := h.ms.GetVariants(context.Background(), []uint{media.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		thumb := variants[media.ID][0]
+		assert.Equal(t, model.MediaVariantSpecs[0].Name, thumb.Name)
+		assert.Equal(t, 120, thumb.Width)
+		assert.Equal(t, 120, thumb.Height)
+
+		tests := []struct {
+			title              string
+			reqFile            string
+			expectedStatusCode int
+		}{
+			{
+				"get media variant: success",
+				thumb.FileName(),
+				http.StatusOK,
+			},
+			{
+				"get media variant: stale hash",
+				fmt.Sprintf("%s-0000000000000000.png", thumb.Name),
+				http.StatusNotFound,
+			},
+			{
+				"get media variant: unknown variant",
+				"huge-0000000000000000.png",
+				http.StatusNotFound,
+			},
+			{
+				"get media variant: invalid file",
+				"thumb.png",
+				http.StatusBadRequest,
+			},
+		}
+
+		for _, tt := range tests {
+			apiUrl := fmt.Sprintf("/api/v1/media/%d/variants/%s", media.ID, tt.reqFile)
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Request = req
+			ctx.AddParam("id", strconv.Itoa(int(media.ID)))
+			ctx.AddParam("file", tt.reqFile)
+
+			h.GetMediaVariant(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Equal(t, "image/png", w.Result().Header.Get("Content-Type"), tt.title)
+				assert.Contains(t, w.Result().Header.Get("Cache-Control"), "immutable", tt.title)
+
+				cfg, err := png.DecodeConfig(w.Result().Body)
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, thumb.Width, cfg.Width, tt.title)
+			}
+		}
+	})
+
+	t.Run("DeleteMedia", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		media := uploadMedia(t, h, lct.Environ(), fooUser, "avatar.png", pngContent)
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqID              string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"delete media: not owner",
+				barUser,
+				strconv.Itoa(int(media.ID)),
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"delete media: invalid id",
+				fooUser,
+				"invalid_id",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid media id"},
+				true,
+			},
+			{
+				"delete media: success",
+				fooUser,
+				strconv.Itoa(int(media.ID)),
+				http.StatusNoContent,
+				nil,
+				false,
+			},
+			{
+				"delete media: already deleted",
+				fooUser,
+				strconv.Itoa(int(media.ID)),
+				http.StatusNotFound,
+				map[string]interface{}{"error": "media not found"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			apiUrl := fmt.Sprintf("/api/v1/media/%v", tt.reqID)
+			req := httptest.NewRequest(http.MethodDelete, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", tt.reqID)
+
+			h.DeleteMedia(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			}
+		}
+	})
+
+	t.Run("ArticleMedia", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		fooMedia := uploadMedia(t, h, lct.Environ(), fooUser, "figure.png", pngContent)
+		barMedia := uploadMedia(t, h, lct.Environ(), barUser, "figure.png", pngContent)
+
+		slug := strconv.Itoa(int(fooArticle.ID))
+
+		attachTests := []struct {
+			title              string
+			reqUser            *model.User
+			reqMediaID         uint
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"attach article media: success",
+				fooUser,
+				fooMedia.ID,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"attach article media: not article owner",
+				barUser,
+				barMedia.ID,
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"attach article media: not media owner",
+				fooUser,
+				barMedia.ID,
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"attach article media: media not found",
+				fooUser,
+				0,
+				http.StatusNotFound,
+				map[string]interface{}{"error": "media not found"},
+				true,
+			},
+		}
+
+		for _, tt := range attachTests {
+			body, err := json.Marshal(message.MediaRequest{MediaID: tt.reqMediaID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			apiUrl := fmt.Sprintf("/api/v1/articles/%v/media", slug)
+			req := httptest.NewRequest(http.MethodPost, apiUrl, bytes.NewReader(body))
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("slug", slug)
+
+			h.AttachArticleMedia(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			}
+		}
+
+		getArticleMedia := func(t *testing.T) message.MediaListResponse {
+			t.Helper()
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/articles/%v/media", slug), nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, 0, time.Now())
+			ctx.AddParam("slug", slug)
+
+			h.GetArticleMedia(ctx)
+
+			assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+			return test.GetResponseBody[message.MediaListResponse](t, w.Result())
+		}
+
+		actual := getArticleMedia(t)
+		if assert.Len(t, actual.Media, 1) {
+			assert.Equal(t, fooMedia.ID, actual.Media[0].ID)
+		}
+
+		apiUrl := fmt.Sprintf("/api/v1/articles/%v/media/%d", slug, fooMedia.ID)
+
+		req := httptest.NewRequest(http.MethodDelete, apiUrl, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+		ctx.AddParam("id", strconv.Itoa(int(fooMedia.ID)))
+
+		h.DetachArticleMedia(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Result().StatusCode)
+
+		req = httptest.NewRequest(http.MethodDelete, apiUrl, nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+		ctx.AddParam("id", strconv.Itoa(int(fooMedia.ID)))
+
+		h.DetachArticleMedia(ctx)
+
+		assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+		assert.Empty(t, getArticleMedia(t).Media)
+	})
+
+	t.Run("PrivateArticleMedia", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooUser.Private = true
+		fooUser, err := h.us.Update(context.Background(), fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		fooMedia := uploadMedia(t, h, lct.Environ(), fooUser, "figure.png", pngContent)
+
+		err = h.ms.AttachToArticle(context.Background(), fooArticle, &model.Media{ID: fooMedia.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.ip.Process(context.Background(), fooMedia.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		variants, err := h.ms.GetVariants(context.Background(), []uint{fooMedia.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			expectedStatusCode int
+		}{
+			{
+				"get private article media: author",
+				fooUser,
+				http.StatusOK,
+			},
+			{
+				"get private article media: not follower",
+				barUser,
+				http.StatusForbidden,
+			},
+			{
+				"get private article media: anonymous",
+				&model.User{},
+				http.StatusForbidden,
+			},
+		}
+
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/media/%d", fooMedia.ID), nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", strconv.Itoa(int(fooMedia.ID)))
+
+			h.GetMedia(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+			if tt.expectedStatusCode == http.StatusOK {
+				assert.Contains(t, w.Result().Header.Get("Cache-Control"), "private", tt.title)
+			}
+
+			file := variants[fooMedia.ID][0].FileName()
+			req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/media/%d/variants/%s", fooMedia.ID, file), nil)
+			w = httptest.NewRecorder()
+			ctx, _ = ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", strconv.Itoa(int(fooMedia.ID)))
+			ctx.AddParam("file", file)
+
+			h.GetMediaVariant(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+		}
+	})
+
+	t.Run("SetAvatar", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooImage := uploadMedia(t, h, lct.Environ(), fooUser, "avatar.png", pngContent)
+		err := h.ip.Process(context.Background(), fooImage.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fooDocument := uploadMedia(t, h, lct.Environ(), fooUser, "paper.pdf", []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n"))
+		barImage := uploadMedia(t, h, lct.Environ(), barUser, "avatar.png", pngContent)
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqMediaID         uint
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"set avatar: success",
+				fooUser,
+				fooImage.ID,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"set avatar: not an image",
+				fooUser,
+				fooDocument.ID,
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "media is not an image"},
+				true,
+			},
+			{
+				"set avatar: not media owner",
+				fooUser,
+				barImage.ID,
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(message.MediaRequest{MediaID: tt.reqMediaID})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/me/avatar", bytes.NewReader(body))
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+
+			h.SetAvatar(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.ProfileResponse](t, w.Result())
+				assert.Equal(t, fooImage.URL, actualBody.Image, tt.title)
+				assert.Len(t, actualBody.ImageVariants, len(model.MediaVariantSpecs), tt.title)
+				assert.Contains(t, actualBody.ImageVariants["thumb"], fmt.Sprintf("%s/variants/thumb-", fooImage.URL), tt.title)
+			}
+		}
+	})
+}
+
+func newUploadRequest(t testing.TB, filename string, content []byte) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	fw, err := mw.CreateFormFile("file", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = fw.Write(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/media", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	return req
+}
+
+func uploadMedia(t testing.TB, h *Handler, e *env.ENV, user *model.User, filename string, content []byte) message.MediaResponse {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	ctx, _ := ctxWithToken(t, e, w, newUploadRequest(t, filename, content), user.ID, time.Now())
+
+	h.UploadMedia(ctx)
+
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("failed to upload media: status %d", w.Result().StatusCode)
+	}
+
+	return test.GetResponseBody[message.MediaResponse](t, w.Result())
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// SetMentions sets users mentioned by articles and comments
+func (h *Handler) SetMentions(ctx *gin.Context, articles []*model.Article, comments []*model.Comment) error {
+	if len(articles) != 0 {
+		articleIDs := make([]uint, 0, len(articles))
+		for _, a := range articles {
+			articleIDs = append(articleIDs, a.ID)
+		}
+
+		mentions, err := h.as.GetArticlesMentions(ctx.Request.Context(), articleIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range articles {
+			a.Mentions = mentions[a.ID]
+		}
+	}
+
+	if len(comments) != 0 {
+		commentIDs := make([]uint, 0, len(comments))
+		for _, c := range comments {
+			commentIDs = append(commentIDs, c.ID)
+		}
+
+		mentions, err := h.as.GetCommentsMentions(ctx.Request.Context(), commentIDs)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range comments {
+			c.Mentions = mentions[c.ID]
+		}
+	}
+
+	return nil
+}
+
+// resolveMentions returns existing users mentioned in body, unknown usernames are skipped,
+// they are stored with the article or comment of body in the same transaction
+func (h *Handler) resolveMentions(ctx *gin.Context, body string) ([]model.User, error) {
+	usernames := model.ParseMentions(body)
+
+	users := make([]model.User, 0, len(usernames))
+	for _, username := range usernames {
+		user, err := h.us.GetByUsername(ctx.Request.Context(), username)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			return nil, err
+		}
+
+		users = append(users, *user)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_MentionHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	countMentionNotifications := func(t *testing.T, db *sql.DB, userID uint) int {
+		t.Helper()
+
+		var count int
+		err := db.QueryRow(
+			`SELECT COUNT(*) FROM article_management.notifications WHERE user_id = $1 AND type = $2`,
+			userID, model.NotificationTypeMention,
+		).Scan(&count)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return count
+	}
+
+	t.Run("ArticleMentions", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		randStr := test.RandomString(t, 20)
+
+		// create article mentioning bar, an unknown user and its author
+		body, err := json.Marshal(message.CreateArticleRequest{
+			Title: randStr,
+			Body:  fmt.Sprintf("Hello @%s, @unknown_%s and @%s.", barUser.Username, randStr, fooUser.Username),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/articles", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.CreateArticle(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		created := test.GetResponseBody[message.ArticleResponse](t, w.Result())
+		assert.ElementsMatch(t, []string{barUser.Username, fooUser.Username}, created.Mentions)
+
+		// mentioned users are notified once the outbox is relayed
+		relayOutbox(t, lct.DB())
+		assert.Equal(t, 1, countMentionNotifications(t, lct.DB(), barUser.ID))
+		assert.Equal(t, 0, countMentionNotifications(t, lct.DB(), fooUser.ID))
+
+		// update article keeping bar and adding baz, only baz is notified
+		slug := strconv.Itoa(int(created.ID))
+		body, err = json.Marshal(message.UpdateArticleRequest{
+			Body: fmt.Sprintf("Hello @%s and @%s.", barUser.Username, bazUser.Username),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req = httptest.NewRequest(http.MethodPut, "/api/v1/articles/"+slug, bytes.NewReader(body))
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+
+		h.UpdateArticle(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		updated := test.GetResponseBody[message.ArticleResponse](t, w.Result())
+		assert.ElementsMatch(t, []string{barUser.Username, bazUser.Username}, updated.Mentions)
+
+		relayOutbox(t, lct.DB())
+		assert.Equal(t, 1, countMentionNotifications(t, lct.DB(), barUser.ID))
+		assert.Equal(t, 1, countMentionNotifications(t, lct.DB(), bazUser.ID))
+
+		// mentions are shown on the article
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/articles/"+slug, nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+
+		h.GetArticle(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		actual := test.GetResponseBody[message.ArticleResponse](t, w.Result())
+		assert.ElementsMatch(t, []string{barUser.Username, bazUser.Username}, actual.Mentions)
+	})
+
+	t.Run("PrivateArticleMentions", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooUser.Private = true
+		fooUser, err := h.us.Update(context.Background(), fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		body, err := json.Marshal(message.CreateArticleRequest{
+			Title: test.RandomString(t, 20),
+			Body:  fmt.Sprintf("Hello @%s.", barUser.Username),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/articles", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.CreateArticle(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+
+		// barUser does not follow private fooUser, so cannot see the article
+		relayOutbox(t, lct.DB())
+		assert.Equal(t, 0, countMentionNotifications(t, lct.DB(), barUser.ID))
+	})
+
+	t.Run("CommentMentions", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		slug := strconv.Itoa(int(fooArticle.ID))
+
+		body, err := json.Marshal(message.CreateCommentRequest{
+			Body: fmt.Sprintf("Thanks @%s, see `@%s`.", fooUser.Username, barUser.Username),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		apiUrl := fmt.Sprintf("/api/v1/articles/%s/comments", slug)
+		req := httptest.NewRequest(http.MethodPost, apiUrl, bytes.NewReader(body))
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+
+		h.CreateComment(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		created := test.GetResponseBody[message.CommentResponse](t, w.Result())
+		assert.Equal(t, []string{fooUser.Username}, created.Mentions)
+
+		relayOutbox(t, lct.DB())
+		assert.Equal(t, 1, countMentionNotifications(t, lct.DB(), fooUser.ID))
+		assert.Equal(t, 0, countMentionNotifications(t, lct.DB(), barUser.ID))
+
+		// mentions are shown on comments of the article
+		req = httptest.NewRequest(http.MethodGet, apiUrl, nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+
+		h.GetComments(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		actual := test.GetResponseBody[message.CommentsResponse](t, w.Result())
+		if assert.Len(t, actual.Comments, 1) {
+			assert.Equal(t, []string{fooUser.Username}, actual.Comments[0].Mentions)
+		}
+	})
+}
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// GetNotifications gets notifications of current user, newest first, along with unread count
+func (h *Handler) GetNotifications(ctx *gin.Context) {
+	h.logger.Info().Msg("get notifications")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	unreadOnly := false
+	if unread := ctx.Query("unread"); unread != "" {
+		unreadOnly, err = strconv.ParseBool(unread)
+		if err != nil {
+			msg := "invalid unread"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err = model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifications, notificationsCount, err := h.ns.GetNotifications(ctx.Request.Context(), currentUser, unreadOnly, limit, offset)
+	if err != nil {
+		msg := "failed to get notifications"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	unreadCount, err := h.ns.CountUnread(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to count unread notifications"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	actors := make([]*model.User, 0, len(notifications))
+	for i := range notifications {
+		actors = append(actors, &notifications[i].Actor)
+	}
+
+	err = h.SetImageVariants(ctx, actors, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.NotificationResponse, 0, len(notifications))
+	for _, n := range notifications {
+		resp = append(resp, n.ResponseNotification())
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.NotificationsResponse{
+		Notifications:      resp,
+		NotificationsCount: notificationsCount,
+		UnreadCount:        unreadCount,
+	})
+}
+
+// MarkNotificationRead marks a notification of current user read
+func (h *Handler) MarkNotificationRead(ctx *gin.Context) {
+	h.logger.Info().Msg("mark notification read")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid notification id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	notification, err := h.ns.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("notification (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+		return
+	}
+
+	if notification.UserID != currentUser.ID {
+		err := fmt.Errorf(
+			"current user (id=%d) is forbidden to read this notification (id=%d)",
+			currentUser.ID, notification.ID,
+		)
+		msg := "forbidden"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	err = h.ns.MarkRead(ctx.Request.Context(), notification)
+	if err != nil {
+		msg := "failed to mark notification read"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, []*model.User{&notification.Actor}, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, notification.ResponseNotification())
+}
+
+// MarkAllNotificationsRead marks all notifications of current user read
+func (h *Handler) MarkAllNotificationsRead(ctx *gin.Context) {
+	h.logger.Info().Msg("mark all notifications read")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	err = h.ns.MarkAllRead(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to mark notifications read"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// GetNotificationPreferences gets which notification types current user receives
+func (h *Handler) GetNotificationPreferences(ctx *gin.Context) {
+	h.logger.Info().Msg("get notification preferences")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	prefs, err := h.ns.GetPreferences(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get notification preferences"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.NotificationPreferencesResponse{Preferences: prefs})
+}
+
+// UpdateNotificationPreferences opts current user in or out of notification types
+func (h *Handler) UpdateNotificationPreferences(ctx *gin.Context) {
+	h.logger.Info().Msg("update notification preferences")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	var req message.UpdateNotificationPreferencesRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	prefs := model.NotificationPreferences(req.Preferences)
+
+	err = prefs.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.ns.UpdatePreferences(ctx.Request.Context(), currentUser, prefs)
+	if err != nil {
+		msg := "failed to update notification preferences"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	prefs, err = h.ns.GetPreferences(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get notification preferences"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.NotificationPreferencesResponse{Preferences: prefs})
+}
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_NotificationHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	getNotifications := func(t *testing.T, user *model.User, query string) (int, message.NotificationsResponse) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/notifications"+query, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+
+		h.GetNotifications(ctx)
+
+		if w.Result().StatusCode != http.StatusOK {
+			return w.Result().StatusCode, message.NotificationsResponse{}
+		}
+
+		return w.Result().StatusCode, test.GetResponseBody[message.NotificationsResponse](t, w.Result())
+	}
+
+	types := func(resp message.NotificationsResponse) []string {
+		out := []string{}
+		for _, n := range resp.Notifications {
+			out = append(out, n.Type)
+		}
+		return out
+	}
+
+	t.Run("GetNotifications", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+
+		err := h.us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = h.as.AddFavorite(context.Background(), fooArticle, barUser, func(int64, time.Time) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		barComment := createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+
+		// foo replying to bar on own article is only notified to bar
+		createRandomReply(t, lct.DB(), barComment, fooUser.ID)
+
+		// baz replying to bar notifies bar of the reply and foo of the comment
+		createRandomReply(t, lct.DB(), barComment, bazUser.ID)
+
+		// users are notified once events are relayed
+		relayOutbox(t, lct.DB())
+
+		statusCode, fooResp := getNotifications(t, fooUser, "")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, []string{
+			model.NotificationTypeComment,
+			model.NotificationTypeComment,
+			model.NotificationTypeFavorite,
+			model.NotificationTypeFollow,
+		}, types(fooResp))
+		assert.Equal(t, int64(4), fooResp.NotificationsCount)
+		assert.Equal(t, int64(4), fooResp.UnreadCount)
+		assert.Equal(t, bazUser.Username, fooResp.Notifications[0].Actor.Username)
+		assert.Equal(t, &fooArticle.ID, fooResp.Notifications[0].ArticleID)
+		assert.NotNil(t, fooResp.Notifications[0].CommentID)
+		assert.Nil(t, fooResp.Notifications[3].ArticleID)
+
+		statusCode, barResp := getNotifications(t, barUser, "")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, []string{model.NotificationTypeReply, model.NotificationTypeReply}, types(barResp))
+
+		statusCode, pageResp := getNotifications(t, fooUser, "?limit=1&offset=1")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, []string{model.NotificationTypeComment}, types(pageResp))
+		assert.Equal(t, int64(4), pageResp.NotificationsCount)
+
+		statusCode, _ = getNotifications(t, fooUser, "?unread=maybe")
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+
+		// limits above the maximum are clamped
+		statusCode, pageResp = getNotifications(t, fooUser, "?limit=101")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Len(t, pageResp.Notifications, 4)
+	})
+
+	t.Run("MarkNotificationRead", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		err := h.us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		relayOutbox(t, lct.DB())
+
+		_, fooResp := getNotifications(t, fooUser, "")
+		if !assert.Len(t, fooResp.Notifications, 1) {
+			return
+		}
+
+		id := strconv.Itoa(int(fooResp.Notifications[0].ID))
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqID              string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"mark notification read: success",
+				fooUser,
+				id,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"mark notification read: already read",
+				fooUser,
+				id,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"mark notification read: invalid notification id",
+				fooUser,
+				"invalid_id",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid notification id"},
+				true,
+			},
+			{
+				"mark notification read: wrong notification id",
+				fooUser,
+				"0",
+				http.StatusNotFound,
+				map[string]interface{}{"error": "notification not found"},
+				true,
+			},
+			{
+				"mark notification read: forbidden to read other user's notification",
+				barUser,
+				id,
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+		}
+
+		var readAt *string
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/me/notifications/"+tt.reqID+"/read", nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", tt.reqID)
+
+			h.MarkNotificationRead(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.NotificationResponse](t, w.Result())
+				assert.True(t, actualBody.Read, tt.title)
+				assert.NotNil(t, actualBody.ReadAt, tt.title)
+
+				// first read time is kept
+				if readAt != nil {
+					assert.Equal(t, readAt, actualBody.ReadAt, tt.title)
+				}
+				readAt = actualBody.ReadAt
+			}
+		}
+
+		_, fooResp = getNotifications(t, fooUser, "?unread=true")
+		assert.Empty(t, fooResp.Notifications)
+		assert.Equal(t, int64(0), fooResp.UnreadCount)
+	})
+
+	t.Run("MarkAllNotificationsRead", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+		createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+
+		relayOutbox(t, lct.DB())
+
+		_, fooResp := getNotifications(t, fooUser, "?unread=true")
+		assert.Len(t, fooResp.Notifications, 2)
+		assert.Equal(t, int64(2), fooResp.UnreadCount)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/me/notifications/read", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.MarkAllNotificationsRead(ctx)
+
+		assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+		_, fooResp = getNotifications(t, fooUser, "")
+		assert.Len(t, fooResp.Notifications, 2)
+		assert.Equal(t, int64(0), fooResp.UnreadCount)
+		for _, n := range fooResp.Notifications {
+			assert.True(t, n.Read)
+		}
+	})
+
+	t.Run("NotificationPreferences", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		tests := []struct {
+			title              string
+			reqBody            *message.UpdateNotificationPreferencesRequest
+			expectedStatusCode int
+			expectedBody       map[string]bool
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"update notification preferences: opt out",
+				&message.UpdateNotificationPreferencesRequest{
+					Preferences: map[string]bool{
+						model.NotificationTypeFollow:  false,
+						model.NotificationTypeComment: false,
+					},
+				},
+				http.StatusOK,
+				map[string]bool{
+					model.NotificationTypeFollow:   false,
+					model.NotificationTypeFavorite: true,
+					model.NotificationTypeComment:  false,
+					model.NotificationTypeReply:    true,
+					model.NotificationTypeMention:  true,
+				},
+				nil,
+				false,
+			},
+			{
+				"update notification preferences: opt in, others kept",
+				&message.UpdateNotificationPreferencesRequest{
+					Preferences: map[string]bool{
+						model.NotificationTypeComment: true,
+					},
+				},
+				http.StatusOK,
+				map[string]bool{
+					model.NotificationTypeFollow:   false,
+					model.NotificationTypeFavorite: true,
+					model.NotificationTypeComment:  true,
+					model.NotificationTypeReply:    true,
+					model.NotificationTypeMention:  true,
+				},
+				nil,
+				false,
+			},
+			{
+				"update notification preferences: unknown type",
+				&message.UpdateNotificationPreferencesRequest{
+					Preferences: map[string]bool{"newsletter": false},
+				},
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{
+					"error": "validation error: newsletter: must be one of follow, favorite, comment, reply, mention.",
+				},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(tt.reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/me/notification_preferences", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+			h.UpdateNotificationPreferences(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.NotificationPreferencesResponse](t, w.Result())
+				assert.Equal(t, tt.expectedBody, actualBody.Preferences, tt.title)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/me/notification_preferences", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.GetNotificationPreferences(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		actualBody := test.GetResponseBody[message.NotificationPreferencesResponse](t, w.Result())
+		assert.False(t, actualBody.Preferences[model.NotificationTypeFollow])
+
+		// opted out types are not notified
+		err := h.us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		relayOutbox(t, lct.DB())
+
+		_, fooResp := getNotifications(t, fooUser, "")
+		assert.Empty(t, fooResp.Notifications)
+	})
+
+	t.Run("MutedActors", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+
+		err := h.us.Mute(context.Background(), fooUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// only baz is not muted by foo
+		createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+		createRandomComment(t, lct.DB(), fooArticle.ID, bazUser.ID)
+
+		relayOutbox(t, lct.DB())
+
+		_, fooResp := getNotifications(t, fooUser, "")
+		if assert.Len(t, fooResp.Notifications, 1) {
+			assert.Equal(t, bazUser.Username, fooResp.Notifications[0].Actor.Username)
+		}
+	})
+}
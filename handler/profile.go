@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
 )
 
 // ShowProfile gets a user profile
@@ -36,10 +39,18 @@ func (h *Handler) ShowProfile(ctx *gin.Context) {
 		return
 	}
 
-	ctx.AbortWithStatusJSON(http.StatusOK, user.ResponseProfile(following))
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, resp)
 }
 
-// FollowUser follows a user
+// FollowUser follows a user, or requests to follow a private user
 func (h *Handler) FollowUser(ctx *gin.Context) {
 	h.logger.Info().Msg("follow user")
 
@@ -69,6 +80,22 @@ func (h *Handler) FollowUser(ctx *gin.Context) {
 		return
 	}
 
+	blocked, err := h.us.IsBlockedBetween(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		msg := "failed to get blocked status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if blocked {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to follow user (id=%d) with a block between them", currentUser.ID, user.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
 	following, err := h.us.IsFollowing(ctx.Request.Context(), currentUser, user)
 	if err != nil {
 		msg := "failed to get following status"
@@ -84,6 +111,11 @@ func (h *Handler) FollowUser(ctx *gin.Context) {
 		return
 	}
 
+	if user.Private {
+		h.requestFollow(ctx, currentUser, user)
+		return
+	}
+
 	err = h.us.Follow(ctx.Request.Context(), currentUser, user)
 	if err != nil {
 		h.logger.Error().Err(err).
@@ -93,10 +125,18 @@ func (h *Handler) FollowUser(ctx *gin.Context) {
 	}
 
 	following = true
-	ctx.AbortWithStatusJSON(http.StatusOK, user.ResponseProfile(following))
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, resp)
 }
 
-// UnfollowUser unfollows a user
+// UnfollowUser unfollows a user, or cancels a pending request to follow a private user
 func (h *Handler) UnfollowUser(ctx *gin.Context) {
 	h.logger.Info().Msg("unfollow user")
 
@@ -135,7 +175,29 @@ func (h *Handler) UnfollowUser(ctx *gin.Context) {
 	}
 
 	if !following {
-		err := fmt.Errorf("current user (ID: %d) is not following user (ID: %d)", currentUser.ID, user.ID)
+		// a pending follow request is cancelled instead
+		deleted, err := h.us.DeleteFollowRequest(ctx.Request.Context(), currentUser, user)
+		if err != nil {
+			msg := "failed to cancel follow request"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		if deleted {
+			resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+			if err != nil {
+				msg := "failed to get profile"
+				h.logger.Error().Err(err).Msg(msg)
+				ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusOK, resp)
+			return
+		}
+
+		err = fmt.Errorf("current user (ID: %d) is not following user (ID: %d)", currentUser.ID, user.ID)
 		h.logger.Error().Err(err).Msg("current user is not following the user")
 		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "you are not following this user"})
 		return
@@ -150,5 +212,279 @@ func (h *Handler) UnfollowUser(ctx *gin.Context) {
 	}
 
 	following = false
-	ctx.AbortWithStatusJSON(http.StatusOK, user.ResponseProfile(following))
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// requestFollow requests current user to follow the private user, who approves or rejects it
+func (h *Handler) requestFollow(ctx *gin.Context, currentUser, user *model.User) {
+	requested, err := h.us.HasFollowRequest(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		msg := "failed to get follow request status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if requested {
+		err := fmt.Errorf("current user (ID: %d) already requested to follow user (ID: %d)", currentUser.ID, user.ID)
+		h.logger.Error().Err(err).Msg("current user already requested to follow the user")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "you already requested to follow this user"})
+		return
+	}
+
+	err = h.us.RequestFollow(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		h.logger.Error().Err(err).
+			Msg(fmt.Sprintf("failed to request to follow user: (ID: %d) -> (ID: %d)", currentUser.ID, user.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to request to follow user"})
+		return
+	}
+
+	following := false
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusAccepted, resp)
+}
+
+// GetFollowRequests gets users requesting to follow current user
+func (h *Handler) GetFollowRequests(ctx *gin.Context) {
+	h.logger.Info().Msg("get follow requests")
+	h.getRelatedUsers(ctx, "follow requests", h.us.GetFollowRequests)
+}
+
+// ApproveFollowRequest approves a request of a user to follow current user
+func (h *Handler) ApproveFollowRequest(ctx *gin.Context) {
+	h.logger.Info().Msg("approve follow request")
+	h.answerFollowRequest(ctx, "approve", func(c context.Context, requester, currentUser *model.User) error {
+		return h.us.Follow(c, requester, currentUser)
+	})
+}
+
+// RejectFollowRequest rejects a request of a user to follow current user
+func (h *Handler) RejectFollowRequest(ctx *gin.Context) {
+	h.logger.Info().Msg("reject follow request")
+	h.answerFollowRequest(ctx, "reject", func(c context.Context, requester, currentUser *model.User) error {
+		_, err := h.us.DeleteFollowRequest(c, requester, currentUser)
+		return err
+	})
+}
+
+// answerFollowRequest applies answer of action to the request of the user of username param
+// to follow current user, and responds with profile of the user
+func (h *Handler) answerFollowRequest(ctx *gin.Context, action string, answer func(context.Context, *model.User, *model.User) error) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	username := ctx.Param("username")
+	user, err := h.us.GetByUsername(ctx.Request.Context(), username)
+	if err != nil {
+		msg := "user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	requested, err := h.us.HasFollowRequest(ctx.Request.Context(), user, currentUser)
+	if err != nil {
+		msg := "failed to get follow request status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !requested {
+		msg := "follow request not found"
+		err := fmt.Errorf("user (ID: %d) has not requested to follow current user (ID: %d)", user.ID, currentUser.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	err = answer(ctx.Request.Context(), user, currentUser)
+	if err != nil {
+		msg := fmt.Sprintf("failed to %s follow request", action)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following, err := h.us.IsFollowing(ctx.Request.Context(), currentUser, user)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp, err := h.GetProfileResponse(ctx, currentUser, user, following)
+	if err != nil {
+		msg := "failed to get profile"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, resp)
+}
+
+// GetFollowers gets users following a user
+func (h *Handler) GetFollowers(ctx *gin.Context) {
+	h.logger.Info().Msg("get followers")
+	h.getFollows(ctx, "followers", h.us.GetFollowers)
+}
+
+// GetFollowing gets users a user follows
+func (h *Handler) GetFollowing(ctx *gin.Context) {
+	h.logger.Info().Msg("get following")
+	h.getFollows(ctx, "following", h.us.GetFollowing)
+}
+
+// getFollows gets a page of users listed by list for the user of username param (only for approved
+// followers if the user is private), except those blocked by or blocking current user,
+// with whether current user follows each of them and whether they follow current user
+func (h *Handler) getFollows(ctx *gin.Context, name string, list func(context.Context, *model.User, *model.User, int64, int64) ([]model.User, int64, error)) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	username := ctx.Param("username")
+	user, err := h.us.GetByUsername(ctx.Request.Context(), username)
+	if err != nil {
+		msg := "user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	if user.Private {
+		visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, user)
+		if err != nil {
+			msg := "failed to get visibility of user"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		if !visible {
+			msg := "forbidden"
+			err := fmt.Errorf("user (id=%d) attempted to get %s of private user (id=%d)", currentUser.ID, name, user.ID)
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err = model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	users, count, err := list(ctx.Request.Context(), user, currentUser, limit, offset)
+	if err != nil {
+		msg := fmt.Sprintf("failed to get %s", name)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	userIDs := make([]uint, 0, len(users))
+	refs := make([]*model.User, 0, len(users))
+	for i := range users {
+		userIDs = append(userIDs, users[i].ID)
+		refs = append(refs, &users[i])
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, userIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	followedBy, err := h.us.AreFollowedBy(ctx.Request.Context(), currentUser, userIDs)
+	if err != nil {
+		msg := "failed to get followed by status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, refs, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.ProfileResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, u.ResponseProfileOfViewer(following[u.ID], followedBy[u.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ProfilesResponse{
+		Profiles:      resp,
+		ProfilesCount: count,
+	})
+}
+
+// GetProfileResponse returns profile of user as seen by current user, with its stats,
+// whether the user follows current user and whether current user requested to follow the private user
+func (h *Handler) GetProfileResponse(ctx *gin.Context, currentUser, user *model.User, following bool) (message.ProfileResponse, error) {
+	followedBy, err := h.us.IsFollowing(ctx.Request.Context(), user, currentUser)
+	if err != nil {
+		return message.ProfileResponse{}, err
+	}
+
+	stats, err := h.us.GetProfileStats(ctx.Request.Context(), user)
+	if err != nil {
+		return message.ProfileResponse{}, err
+	}
+
+	err = h.SetImageVariants(ctx, []*model.User{user}, nil)
+	if err != nil {
+		return message.ProfileResponse{}, err
+	}
+
+	resp := user.ResponseProfileWithStats(following, followedBy, stats)
+
+	if user.Private && user.ID != currentUser.ID {
+		requested, err := h.us.HasFollowRequest(ctx.Request.Context(), currentUser, user)
+		if err != nil {
+			return message.ProfileResponse{}, err
+		}
+
+		resp.FollowRequested = &requested
+	}
+
+	return resp, nil
 }
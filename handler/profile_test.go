@@ -26,6 +26,14 @@ func TestIntegration_ProfileHandler(t *testing.T) {
 	t.Run("ShowProfile", func(t *testing.T) {
 		fooUser := createRandomUser(t, lct.DB())
 		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		err := h.us.Follow(context.Background(), bazUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		createRandomArticle(t, lct.DB(), bazUser.ID)
 
 		tests := []struct {
 			title              string
@@ -41,7 +49,19 @@ func TestIntegration_ProfileHandler(t *testing.T) {
 				fooUser,
 				barUser.Username,
 				http.StatusOK,
-				barUser.ResponseProfile(false),
+				barUser.ResponseProfileWithStats(false, false, &model.ProfileStats{}),
+				nil,
+				false,
+			},
+			{
+				"show profile: bazUser profile follows fooUser",
+				fooUser,
+				bazUser.Username,
+				http.StatusOK,
+				bazUser.ResponseProfileWithStats(false, true, &model.ProfileStats{
+					FollowingCount: 1,
+					ArticlesCount:  1,
+				}),
 				nil,
 				false,
 			},
@@ -111,7 +131,7 @@ func TestIntegration_ProfileHandler(t *testing.T) {
 				fooUser,
 				barUser.Username,
 				http.StatusOK,
-				barUser.ResponseProfile(true),
+				barUser.ResponseProfileWithStats(true, false, &model.ProfileStats{FollowersCount: 1}),
 				nil,
 				false,
 			},
@@ -206,7 +226,7 @@ func TestIntegration_ProfileHandler(t *testing.T) {
 				fooUser,
 				barUser.Username,
 				http.StatusOK,
-				barUser.ResponseProfile(false),
+				barUser.ResponseProfileWithStats(false, false, &model.ProfileStats{}),
 				nil,
 				false,
 			},
@@ -276,4 +296,276 @@ func TestIntegration_ProfileHandler(t *testing.T) {
 			}
 		}
 	})
+	t.Run("GetFollowersAndFollowing", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		// barUser and bazUser follow fooUser, fooUser follows bazUser back
+		for _, follow := range [][2]*model.User{{barUser, fooUser}, {bazUser, fooUser}, {fooUser, bazUser}} {
+			err := h.us.Follow(context.Background(), follow[0], follow[1])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tests := []struct {
+			title              string
+			handle             gin.HandlerFunc
+			reqUser            *model.User
+			reqUsername        string
+			reqQuery           string
+			expectedStatusCode int
+			expectedBody       message.ProfilesResponse
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"get followers: newest follow first, as seen by fooUser",
+				h.GetFollowers,
+				fooUser,
+				fooUser.Username,
+				"",
+				http.StatusOK,
+				message.ProfilesResponse{
+					Profiles: []message.ProfileResponse{
+						bazUser.ResponseProfileOfViewer(true, true),
+						barUser.ResponseProfileOfViewer(false, true),
+					},
+					ProfilesCount: 2,
+				},
+				nil,
+				false,
+			},
+			{
+				"get followers: paginated, as seen by bazUser",
+				h.GetFollowers,
+				bazUser,
+				fooUser.Username,
+				"?limit=1&offset=1",
+				http.StatusOK,
+				message.ProfilesResponse{
+					Profiles: []message.ProfileResponse{
+						barUser.ResponseProfileOfViewer(false, false),
+					},
+					ProfilesCount: 2,
+				},
+				nil,
+				false,
+			},
+			{
+				"get following: as seen by barUser",
+				h.GetFollowing,
+				barUser,
+				fooUser.Username,
+				"",
+				http.StatusOK,
+				message.ProfilesResponse{
+					Profiles: []message.ProfileResponse{
+						bazUser.ResponseProfileOfViewer(false, false),
+					},
+					ProfilesCount: 1,
+				},
+				nil,
+				false,
+			},
+			{
+				"get following: no one",
+				h.GetFollowing,
+				fooUser,
+				barUser.Username,
+				"",
+				http.StatusOK,
+				message.ProfilesResponse{
+					Profiles:      []message.ProfileResponse{},
+					ProfilesCount: 0,
+				},
+				nil,
+				false,
+			},
+			{
+				"get followers: wrong profile username",
+				h.GetFollowers,
+				fooUser,
+				"unknown_user",
+				"",
+				http.StatusNotFound,
+				message.ProfilesResponse{},
+				map[string]interface{}{"error": "user not found"},
+				true,
+			},
+			{
+				"get followers: limit above maximum is clamped",
+				h.GetFollowers,
+				fooUser,
+				fooUser.Username,
+				"?limit=1000",
+				http.StatusOK,
+				message.ProfilesResponse{
+					Profiles: []message.ProfileResponse{
+						bazUser.ResponseProfileOfViewer(true, true),
+						barUser.ResponseProfileOfViewer(false, true),
+					},
+					ProfilesCount: 2,
+				},
+				nil,
+				false,
+			},
+		}
+
+		for _, tt := range tests {
+			apiUrl := fmt.Sprintf("/api/v1/profiles/%s/followers%s", tt.reqUsername, tt.reqQuery)
+			req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("username", tt.reqUsername)
+
+			tt.handle(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.ProfilesResponse](t, w.Result())
+				assert.Equal(t, tt.expectedBody, actualBody, tt.title)
+			}
+		}
+	})
+
+	t.Run("PrivateAccount", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		fooUser.Private = true
+		fooUser, err := h.us.Update(context.Background(), fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		fooSlug := fmt.Sprintf("%d", fooArticle.ID)
+
+		request := func(t *testing.T, handle gin.HandlerFunc, method, apiUrl string, user *model.User, params gin.Params) *http.Response {
+			t.Helper()
+
+			req := httptest.NewRequest(method, apiUrl, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+			for _, p := range params {
+				ctx.AddParam(p.Key, p.Value)
+			}
+
+			handle(ctx)
+
+			return w.Result()
+		}
+
+		fooParams := gin.Params{{Key: "username", Value: fooUser.Username}}
+		barParams := gin.Params{{Key: "username", Value: barUser.Username}}
+		bazParams := gin.Params{{Key: "username", Value: bazUser.Username}}
+		slugParams := gin.Params{{Key: "slug", Value: fooSlug}}
+
+		resp := request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", barUser, fooParams)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		profile := test.GetResponseBody[message.ProfileResponse](t, resp)
+		assert.False(t, profile.Following)
+		if assert.NotNil(t, profile.Private) && assert.NotNil(t, profile.FollowRequested) {
+			assert.True(t, *profile.Private)
+			assert.True(t, *profile.FollowRequested)
+		}
+
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", barUser, fooParams)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "you already requested to follow this user"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		// articles of fooUser are hidden until the request is approved
+		resp = request(t, h.GetArticles, http.MethodGet, "/api/v1/articles?username="+fooUser.Username, barUser, nil)
+		assert.Equal(t, int64(0), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.GetArticle, http.MethodGet, "/api/v1/articles/"+fooSlug, barUser, slugParams)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "forbidden"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		reaction := lct.Environ().Reactions[0]
+		reactionParams := gin.Params{{Key: "slug", Value: fooSlug}, {Key: "reaction", Value: reaction}}
+
+		for _, r := range []struct {
+			handle gin.HandlerFunc
+			method string
+			apiUrl string
+			params gin.Params
+		}{
+			{h.FavoriteArticle, http.MethodPost, "/api/v1/articles/" + fooSlug + "/favorite", slugParams},
+			{h.UnfavoriteArticle, http.MethodDelete, "/api/v1/articles/" + fooSlug + "/favorite", slugParams},
+			{h.AddArticleReaction, http.MethodPut, "/api/v1/articles/" + fooSlug + "/reactions/" + reaction, reactionParams},
+			{h.DeleteArticleReaction, http.MethodDelete, "/api/v1/articles/" + fooSlug + "/reactions/" + reaction, reactionParams},
+			{h.GetArticleMedia, http.MethodGet, "/api/v1/articles/" + fooSlug + "/media", slugParams},
+			{h.Stream, http.MethodGet, "/api/v1/stream?article=" + fooSlug, nil},
+			{h.GetFollowers, http.MethodGet, "/api/v1/profiles/" + fooUser.Username + "/followers", fooParams},
+			{h.GetFollowing, http.MethodGet, "/api/v1/profiles/" + fooUser.Username + "/following", fooParams},
+		} {
+			resp = request(t, r.handle, r.method, r.apiUrl, barUser, r.params)
+			assert.Equal(t, http.StatusForbidden, resp.StatusCode, r.apiUrl)
+		}
+
+		resp = request(t, h.GetArticle, http.MethodGet, "/api/v1/articles/"+fooSlug, fooUser, slugParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = request(t, h.GetFollowRequests, http.MethodGet, "/api/v1/me/follow_requests", fooUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.ProfilesResponse{
+			Profiles:      []message.ProfileResponse{barUser.ResponseProfile(false)},
+			ProfilesCount: 1,
+		}, test.GetResponseBody[message.ProfilesResponse](t, resp))
+
+		resp = request(t, h.ApproveFollowRequest, http.MethodPost, "/api/v1/me/follow_requests/"+barUser.Username+"/approve", fooUser, barParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profile = test.GetResponseBody[message.ProfileResponse](t, resp)
+		if assert.NotNil(t, profile.FollowedBy) {
+			assert.True(t, *profile.FollowedBy)
+		}
+
+		resp = request(t, h.GetArticle, http.MethodGet, "/api/v1/articles/"+fooSlug, barUser, slugParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		resp = request(t, h.GetArticles, http.MethodGet, "/api/v1/articles?username="+fooUser.Username, barUser, nil)
+		assert.Equal(t, int64(1), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.GetFollowers, http.MethodGet, "/api/v1/profiles/"+fooUser.Username+"/followers", barUser, fooParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int64(1), test.GetResponseBody[message.ProfilesResponse](t, resp).ProfilesCount)
+
+		// unfollowing cancels a pending request
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", bazUser, fooParams)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		resp = request(t, h.UnfollowUser, http.MethodDelete, "/api/v1/profiles/"+fooUser.Username+"/follow", bazUser, fooParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profile = test.GetResponseBody[message.ProfileResponse](t, resp)
+		if assert.NotNil(t, profile.FollowRequested) {
+			assert.False(t, *profile.FollowRequested)
+		}
+
+		resp = request(t, h.RejectFollowRequest, http.MethodPost, "/api/v1/me/follow_requests/"+bazUser.Username+"/reject", fooUser, bazParams)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "follow request not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+
+		resp = request(t, h.FollowUser, http.MethodPost, "/api/v1/profiles/"+fooUser.Username+"/follow", bazUser, fooParams)
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+		resp = request(t, h.RejectFollowRequest, http.MethodPost, "/api/v1/me/follow_requests/"+bazUser.Username+"/reject", fooUser, bazParams)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		following, err := h.us.IsFollowing(context.Background(), bazUser, fooUser)
+		assert.NoError(t, err)
+		assert.False(t, following)
+
+		requested, err := h.us.HasFollowRequest(context.Background(), bazUser, fooUser)
+		assert.NoError(t, err)
+		assert.False(t, requested)
+	})
 }
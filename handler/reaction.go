@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// AddArticleReaction adds a reaction of current user to an article
+func (h *Handler) AddArticleReaction(ctx *gin.Context) {
+	h.logger.Info().Msg("add article reaction")
+	h.reactToArticle(ctx, true)
+}
+
+// DeleteArticleReaction removes a reaction of current user from an article
+func (h *Handler) DeleteArticleReaction(ctx *gin.Context) {
+	h.logger.Info().Msg("delete article reaction")
+	h.reactToArticle(ctx, false)
+}
+
+// AddCommentReaction adds a reaction of current user to a comment
+func (h *Handler) AddCommentReaction(ctx *gin.Context) {
+	h.logger.Info().Msg("add comment reaction")
+	h.reactToComment(ctx, true)
+}
+
+// DeleteCommentReaction removes a reaction of current user from a comment
+func (h *Handler) DeleteCommentReaction(ctx *gin.Context) {
+	h.logger.Info().Msg("delete comment reaction")
+	h.reactToComment(ctx, false)
+}
+
+// reactToArticle adds or removes a reaction of current user and responds with reactions of the article
+func (h *Handler) reactToArticle(ctx *gin.Context, add bool) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	reaction := ctx.Param("reaction")
+	err = model.ValidateReaction(reaction, h.environ.Reactions)
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		return
+	}
+
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to react to article (id=%d) of private user", currentUser.ID, article.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	if add {
+		err = h.as.AddArticleReaction(ctx.Request.Context(), article, currentUser, reaction)
+	} else {
+		err = h.as.DeleteArticleReaction(ctx.Request.Context(), article, currentUser, reaction)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Msg(fmt.Sprintf("failed to update reaction (%s) of user (id=%d) to article (id=%d)", reaction, currentUser.ID, article.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to update reaction"})
+		return
+	}
+
+	err = h.SetReactions(ctx, currentUser, []*model.Article{article}, nil)
+	if err != nil {
+		msg := "failed to get reactions"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ReactionsResponse{
+		Reactions: model.ResponseReactions(article.Reactions),
+	})
+}
+
+// reactToComment adds or removes a reaction of current user and responds with reactions of the comment
+func (h *Handler) reactToComment(ctx *gin.Context, add bool) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid comment id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	reaction := ctx.Param("reaction")
+	err = model.ValidateReaction(reaction, h.environ.Reactions)
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.as.GetCommentByID(ctx.Request.Context(), id)
+	if err != nil || comment.IsDeleted() {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("comment (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+
+	if slug != comment.ArticleID {
+		msg := "the comment is not from this article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	blocked, err := h.us.IsBlockedBetween(ctx.Request.Context(), currentUser, &comment.Author)
+	if err != nil {
+		msg := "failed to get blocked status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if blocked {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to react to comment (id=%d) with a block between them and its author", currentUser.ID, comment.ID)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	if add {
+		err = h.as.AddCommentReaction(ctx.Request.Context(), comment, currentUser, reaction)
+	} else {
+		err = h.as.DeleteCommentReaction(ctx.Request.Context(), comment, currentUser, reaction)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).
+			Msg(fmt.Sprintf("failed to update reaction (%s) of user (id=%d) to comment (id=%d)", reaction, currentUser.ID, comment.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to update reaction"})
+		return
+	}
+
+	err = h.SetReactions(ctx, currentUser, nil, []*model.Comment{comment})
+	if err != nil {
+		msg := "failed to get reactions"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ReactionsResponse{
+		Reactions: model.ResponseReactions(comment.Reactions),
+	})
+}
+
+// SetReactions sets reactions of articles and comments, marking those user reacted with
+func (h *Handler) SetReactions(ctx *gin.Context, user *model.User, articles []*model.Article, comments []*model.Comment) error {
+	if len(articles) != 0 {
+		articleIDs := make([]uint, 0, len(articles))
+		for _, a := range articles {
+			articleIDs = append(articleIDs, a.ID)
+		}
+
+		reactions, err := h.as.GetArticlesReactions(ctx.Request.Context(), articleIDs, user)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range articles {
+			a.Reactions = reactions[a.ID]
+		}
+	}
+
+	if len(comments) != 0 {
+		commentIDs := make([]uint, 0, len(comments))
+		for _, c := range comments {
+			commentIDs = append(commentIDs, c.ID)
+		}
+
+		reactions, err := h.as.GetCommentsReactions(ctx.Request.Context(), commentIDs, user)
+		if err != nil {
+			return err
+		}
+
+		for _, c := range comments {
+			c.Reactions = reactions[c.ID]
+		}
+	}
+
+	return nil
+}
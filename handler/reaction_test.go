@@ -0,0 +1,363 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_ReactionHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	t.Run("ArticleReaction", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		slug := strconv.Itoa(int(fooArticle.ID))
+
+		err := h.as.AddArticleReaction(context.Background(), fooArticle, fooUser, "heart")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests := []struct {
+			title              string
+			add                bool
+			reqUser            *model.User
+			reqSlug            string
+			reqReaction        string
+			expectedStatusCode int
+			expectedReactions  []message.ReactionResponse
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"add article reaction: success",
+				true,
+				barUser,
+				slug,
+				"heart",
+				http.StatusOK,
+				[]message.ReactionResponse{{Reaction: "heart", Count: 2, Reacted: true}},
+				nil,
+				false,
+			},
+			{
+				"add article reaction: already reacted",
+				true,
+				barUser,
+				slug,
+				"heart",
+				http.StatusOK,
+				[]message.ReactionResponse{{Reaction: "heart", Count: 2, Reacted: true}},
+				nil,
+				false,
+			},
+			{
+				"add article reaction: another reaction",
+				true,
+				barUser,
+				slug,
+				"laugh",
+				http.StatusOK,
+				[]message.ReactionResponse{
+					{Reaction: "heart", Count: 2, Reacted: true},
+					{Reaction: "laugh", Count: 1, Reacted: true},
+				},
+				nil,
+				false,
+			},
+			{
+				"delete article reaction: success",
+				false,
+				barUser,
+				slug,
+				"heart",
+				http.StatusOK,
+				[]message.ReactionResponse{
+					{Reaction: "heart", Count: 1, Reacted: false},
+					{Reaction: "laugh", Count: 1, Reacted: true},
+				},
+				nil,
+				false,
+			},
+			{
+				"delete article reaction: not reacted",
+				false,
+				barUser,
+				slug,
+				"heart",
+				http.StatusOK,
+				[]message.ReactionResponse{
+					{Reaction: "heart", Count: 1, Reacted: false},
+					{Reaction: "laugh", Count: 1, Reacted: true},
+				},
+				nil,
+				false,
+			},
+			{
+				"add article reaction: wrong current user id",
+				true,
+				&model.User{ID: 0},
+				slug,
+				"heart",
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "current user not found"},
+				true,
+			},
+			{
+				"add article reaction: invalid slug",
+				true,
+				barUser,
+				"invalid_slug",
+				"heart",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "invalid slug"},
+				true,
+			},
+			{
+				"add article reaction: wrong slug",
+				true,
+				barUser,
+				"0",
+				"heart",
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "article not found"},
+				true,
+			},
+			{
+				"add article reaction: unknown reaction",
+				true,
+				barUser,
+				slug,
+				"rocket",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "validation error: reaction: must be one of thumbs_up, heart, laugh."},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			method := http.MethodPut
+			if !tt.add {
+				method = http.MethodDelete
+			}
+
+			apiUrl := fmt.Sprintf("/api/v1/articles/%s/reactions/%s", tt.reqSlug, tt.reqReaction)
+			req := httptest.NewRequest(method, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("slug", tt.reqSlug)
+			ctx.AddParam("reaction", tt.reqReaction)
+
+			if tt.add {
+				h.AddArticleReaction(ctx)
+			} else {
+				h.DeleteArticleReaction(ctx)
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.ReactionsResponse](t, w.Result())
+				assert.Equal(t, tt.expectedReactions, actualBody.Reactions, tt.title)
+			}
+		}
+
+		// reactions are shown on the article, marked for current user only
+		req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/articles/%s", slug), nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("slug", slug)
+
+		h.GetArticle(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		actualBody := test.GetResponseBody[message.ArticleResponse](t, w.Result())
+		assert.Equal(t, []message.ReactionResponse{
+			{Reaction: "heart", Count: 1, Reacted: true},
+			{Reaction: "laugh", Count: 1, Reacted: false},
+		}, actualBody.Reactions)
+	})
+
+	t.Run("CommentReaction", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+
+		fooComment := createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+		deletedComment := createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+		deleteComment(t, lct.DB(), deletedComment.ID)
+
+		slug := strconv.Itoa(int(fooArticle.ID))
+		id := strconv.Itoa(int(fooComment.ID))
+
+		tests := []struct {
+			title              string
+			add                bool
+			reqUser            *model.User
+			reqSlug            string
+			reqID              string
+			reqReaction        string
+			expectedStatusCode int
+			expectedReactions  []message.ReactionResponse
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"add comment reaction: success",
+				true,
+				fooUser,
+				slug,
+				id,
+				"thumbs_up",
+				http.StatusOK,
+				[]message.ReactionResponse{{Reaction: "thumbs_up", Count: 1, Reacted: true}},
+				nil,
+				false,
+			},
+			{
+				"add comment reaction: already reacted",
+				true,
+				fooUser,
+				slug,
+				id,
+				"thumbs_up",
+				http.StatusOK,
+				[]message.ReactionResponse{{Reaction: "thumbs_up", Count: 1, Reacted: true}},
+				nil,
+				false,
+			},
+			{
+				"delete comment reaction: success",
+				false,
+				fooUser,
+				slug,
+				id,
+				"thumbs_up",
+				http.StatusOK,
+				[]message.ReactionResponse{},
+				nil,
+				false,
+			},
+			{
+				"add comment reaction: invalid comment id",
+				true,
+				fooUser,
+				slug,
+				"invalid_id",
+				"thumbs_up",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "invalid comment id"},
+				true,
+			},
+			{
+				"add comment reaction: wrong comment id",
+				true,
+				fooUser,
+				slug,
+				"0",
+				"thumbs_up",
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "comment not found"},
+				true,
+			},
+			{
+				"add comment reaction: deleted comment",
+				true,
+				fooUser,
+				slug,
+				strconv.Itoa(int(deletedComment.ID)),
+				"thumbs_up",
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "comment not found"},
+				true,
+			},
+			{
+				"add comment reaction: comment is not from the article",
+				true,
+				fooUser,
+				strconv.Itoa(int(barArticle.ID)),
+				id,
+				"thumbs_up",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "the comment is not from this article"},
+				true,
+			},
+			{
+				"add comment reaction: unknown reaction",
+				true,
+				fooUser,
+				slug,
+				id,
+				"Thumbs Up",
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "validation error: reaction: must be one of thumbs_up, heart, laugh."},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			method := http.MethodPut
+			if !tt.add {
+				method = http.MethodDelete
+			}
+
+			apiUrl := fmt.Sprintf("/api/v1/articles/%s/comments/%s/reactions/%s", tt.reqSlug, tt.reqID, tt.reqReaction)
+			req := httptest.NewRequest(method, apiUrl, nil)
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("slug", tt.reqSlug)
+			ctx.AddParam("id", tt.reqID)
+			ctx.AddParam("reaction", tt.reqReaction)
+
+			if tt.add {
+				h.AddCommentReaction(ctx)
+			} else {
+				h.DeleteCommentReaction(ctx)
+			}
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.ReactionsResponse](t, w.Result())
+				assert.Equal(t, tt.expectedReactions, actualBody.Reactions, tt.title)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// GetRelatedArticles gets articles related to an article by shared tags and co-favorites
+func (h *Handler) GetRelatedArticles(ctx *gin.Context) {
+	h.logger.Info().Msg("get related articles")
+
+	slug, err := h.GetIDFromParam(ctx, "slug")
+	if err != nil {
+		msg := "invalid slug"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err = model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	article, err := h.as.GetByID(ctx.Request.Context(), slug)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+		return
+	}
+
+	var currentUser *model.User
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		currentUser, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("current user (id=%d) not found", userID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "current user not found"})
+			return
+		}
+	}
+
+	visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+	if err != nil {
+		msg := "failed to get visibility of article"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	if !visible {
+		msg := "forbidden"
+		err := fmt.Errorf("user (id=%d) attempted to get related articles of article (id=%d) of private user", userID, slug)
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	articles, count, err := h.rs.GetRelatedArticles(ctx.Request.Context(), article, currentUser, limit, offset)
+	if err != nil {
+		msg := "failed to get related articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	articleIDs := make([]uint, 0, len(articles))
+	authorIDs := make([]uint, 0, len(articles))
+	refs := make([]*model.Article, 0, len(articles))
+	for i := range articles {
+		articleIDs = append(articleIDs, articles[i].ID)
+		authorIDs = append(authorIDs, articles[i].Author.ID)
+		refs = append(refs, &articles[i])
+	}
+
+	favorited, err := h.as.AreFavorited(ctx.Request.Context(), articleIDs, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, refs, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		resp = append(resp, article.ResponseArticle(favorited[article.ID], following[article.Author.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{
+		Articles:      resp,
+		ArticlesCount: count,
+	})
+}
+
+// GetSuggestedFollows gets users suggested for current user to follow, which are users followed by
+// users current user follows and authors of articles current user favorited
+func (h *Handler) GetSuggestedFollows(ctx *gin.Context) {
+	h.logger.Info().Msg("get suggested follows")
+	h.getRelatedUsers(ctx, "suggested users", h.rs.GetSuggestedUsers)
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_RecommendationHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	fooUser := createRandomUser(t, lct.DB())
+	barUser := createRandomUser(t, lct.DB())
+	bazUser := createRandomUser(t, lct.DB())
+	quxUser := createRandomUser(t, lct.DB())
+
+	tag := model.Tag{Name: test.RandomString(t, 10)}
+
+	createArticle := func(t *testing.T, author *model.User, tags []model.Tag) *model.Article {
+		t.Helper()
+
+		randStr := test.RandomString(t, 10)
+		article, err := h.as.Create(context.Background(), &model.Article{
+			Title:       randStr,
+			Description: randStr,
+			Body:        randStr,
+			UserID:      author.ID,
+			Author:      *author,
+			Tags:        tags,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return article
+	}
+
+	fooArticle := createArticle(t, fooUser, []model.Tag{tag})
+	barArticle := createArticle(t, barUser, []model.Tag{tag})
+	bazArticle := createArticle(t, bazUser, []model.Tag{{Name: test.RandomString(t, 10)}})
+
+	// articles favorited by the same user are related, as are articles sharing a tag
+	for _, article := range []*model.Article{fooArticle, barArticle, bazArticle} {
+		err := h.as.AddFavorite(context.Background(), article, quxUser, func(int64, time.Time) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, follow := range [][2]*model.User{{quxUser, fooUser}, {fooUser, bazUser}} {
+		err := h.us.Follow(context.Background(), follow[0], follow[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := h.rs.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := func(t *testing.T, handle gin.HandlerFunc, apiUrl string, user *model.User, params gin.Params) *http.Response {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+		for _, p := range params {
+			ctx.AddParam(p.Key, p.Value)
+		}
+
+		handle(ctx)
+
+		return w.Result()
+	}
+
+	t.Run("GetRelatedArticles", func(t *testing.T) {
+		slug := fmt.Sprintf("%d", fooArticle.ID)
+		params := gin.Params{{Key: "slug", Value: slug}}
+
+		resp := request(t, h.GetRelatedArticles, "/api/v1/articles/"+slug+"/related", &model.User{ID: 0}, params)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		articles := test.GetResponseBody[message.ArticlesResponse](t, resp)
+		assert.Equal(t, int64(2), articles.ArticlesCount)
+		if assert.Len(t, articles.Articles, 2) {
+			assert.Equal(t, barArticle.ID, articles.Articles[0].ID)
+			assert.Equal(t, bazArticle.ID, articles.Articles[1].ID)
+		}
+
+		resp = request(t, h.GetRelatedArticles, "/api/v1/articles/"+slug+"/related?limit=1&offset=1", fooUser, params)
+		articles = test.GetResponseBody[message.ArticlesResponse](t, resp)
+		if assert.Len(t, articles.Articles, 1) {
+			assert.Equal(t, bazArticle.ID, articles.Articles[0].ID)
+			assert.True(t, articles.Articles[0].Author.Following)
+		}
+
+		// articles of blocked users are left out
+		err := h.us.Block(context.Background(), bazUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = request(t, h.GetRelatedArticles, "/api/v1/articles/"+slug+"/related", bazUser, params)
+		articles = test.GetResponseBody[message.ArticlesResponse](t, resp)
+		assert.Equal(t, int64(1), articles.ArticlesCount)
+
+		resp = request(t, h.GetRelatedArticles, "/api/v1/articles/0/related", fooUser, gin.Params{{Key: "slug", Value: "0"}})
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "article not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
+
+	t.Run("GetSuggestedFollows", func(t *testing.T) {
+		resp := request(t, h.GetSuggestedFollows, "/api/v1/me/suggested_follows", quxUser, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profiles := test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(2), profiles.ProfilesCount)
+		if assert.Len(t, profiles.Profiles, 2) {
+			assert.Equal(t, bazUser.Username, profiles.Profiles[0].Username)
+			assert.Equal(t, barUser.Username, profiles.Profiles[1].Username)
+		}
+
+		// users followed since the last refresh are left out
+		err := h.us.Follow(context.Background(), quxUser, bazUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = request(t, h.GetSuggestedFollows, "/api/v1/me/suggested_follows", quxUser, nil)
+		profiles = test.GetResponseBody[message.ProfilesResponse](t, resp)
+		if assert.Len(t, profiles.Profiles, 1) {
+			assert.Equal(t, barUser.ResponseProfile(false), profiles.Profiles[0])
+		}
+
+		resp = request(t, h.GetSuggestedFollows, "/api/v1/me/suggested_follows", &model.User{ID: 0}, nil)
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -18,6 +18,15 @@ func LinkRouter(router *gin.Engine, h *Handler) {
 		public.POST("/refresh_token", h.RefreshToken)
 
 		public.GET("/tags", h.GetTags)
+
+		public.GET("/feeds/articles.atom", h.GetArticlesFeed)
+		public.GET("/feeds/articles.rss", h.GetArticlesFeed)
+		public.GET("/feeds/tags/:tag", h.GetTagFeed)
+		public.GET("/feeds/profiles/:username", h.GetProfileFeed)
+		public.GET("/feeds/private/:token", h.GetPrivateFeed)
+
+		public.GET("/digest/unsubscribe", h.ConfirmUnsubscribeDigest)
+		public.POST("/digest/unsubscribe", h.UnsubscribeDigest)
 	}
 
 	{
@@ -27,8 +36,17 @@ func LinkRouter(router *gin.Engine, h *Handler) {
 		privateOptional.Use(middleware.Auth(h.logger, h.authen, strictCookie))
 
 		privateOptional.GET("/articles", h.GetArticles)
+		privateOptional.GET("/articles/trending", h.GetTrendingArticles)
 		privateOptional.GET("/articles/:slug", h.GetArticle)
 		privateOptional.GET("/articles/:slug/comments", h.GetComments)
+		privateOptional.GET("/articles/:slug/media", h.GetArticleMedia)
+		privateOptional.GET("/articles/:slug/related", h.GetRelatedArticles)
+
+		privateOptional.GET("/media/:id", h.GetMedia)
+		privateOptional.GET("/media/:id/variants/:file", h.GetMediaVariant)
+
+		privateOptional.GET("/search/articles", h.SearchArticles)
+		privateOptional.GET("/search/profiles", h.SearchProfiles)
 	}
 
 	{
@@ -40,9 +58,51 @@ func LinkRouter(router *gin.Engine, h *Handler) {
 		private.GET("/me", h.GetCurrentUser)
 		private.PUT("/me", h.UpdateCurrentUser)
 
+		private.GET("/me/notifications", h.GetNotifications)
+		private.POST("/me/notifications/read", h.MarkAllNotificationsRead)
+		private.POST("/me/notifications/:id/read", h.MarkNotificationRead)
+		private.GET("/me/notification_preferences", h.GetNotificationPreferences)
+		private.PUT("/me/notification_preferences", h.UpdateNotificationPreferences)
+
+		private.GET("/me/feed_token", h.GetFeedToken)
+		private.POST("/me/feed_token", h.RotateFeedToken)
+
+		private.GET("/me/digest", h.GetDigestSubscription)
+		private.PUT("/me/digest", h.UpdateDigestSubscription)
+
+		private.GET("/me/blocks", h.GetBlocks)
+		private.PUT("/me/blocks/:username", h.BlockUser)
+		private.DELETE("/me/blocks/:username", h.UnblockUser)
+		private.GET("/me/mutes", h.GetMutes)
+		private.PUT("/me/mutes/:username", h.MuteUser)
+		private.DELETE("/me/mutes/:username", h.UnmuteUser)
+
+		private.GET("/me/follow_requests", h.GetFollowRequests)
+		private.POST("/me/follow_requests/:username/approve", h.ApproveFollowRequest)
+		private.POST("/me/follow_requests/:username/reject", h.RejectFollowRequest)
+
+		private.GET("/me/suggested_follows", h.GetSuggestedFollows)
+
+		private.GET("/me/tags", h.GetFollowedTags)
+		private.POST("/tags/:name/follow", h.FollowTag)
+		private.DELETE("/tags/:name/follow", h.UnfollowTag)
+
+		private.GET("/stream", h.Stream)
+
+		private.POST("/webhooks", h.CreateWebhook)
+		private.GET("/webhooks", h.GetWebhooks)
+		private.GET("/webhooks/:id", h.GetWebhook)
+		private.PUT("/webhooks/:id", h.UpdateWebhook)
+		private.DELETE("/webhooks/:id", h.DeleteWebhook)
+		private.GET("/webhooks/:id/deliveries", h.GetWebhookDeliveries)
+		private.GET("/webhooks/:id/deliveries/:delivery_id", h.GetWebhookDelivery)
+		private.POST("/webhooks/:id/deliveries/:delivery_id/redeliver", h.RedeliverWebhookDelivery)
+
 		private.GET("/profiles/:username", h.ShowProfile)
 		private.POST("/profiles/:username/follow", h.FollowUser)
 		private.DELETE("/profiles/:username/follow", h.UnfollowUser)
+		private.GET("/profiles/:username/followers", h.GetFollowers)
+		private.GET("/profiles/:username/following", h.GetFollowing)
 
 		private.GET("/articles/feed", h.GetFeedArticles)
 		private.POST("/articles", h.CreateArticle)
@@ -50,9 +110,23 @@ func LinkRouter(router *gin.Engine, h *Handler) {
 		private.DELETE("/articles/:slug", h.DeleteArticle)
 
 		private.POST("/articles/:slug/comments", h.CreateComment)
+		private.PUT("/articles/:slug/comments/:id", h.UpdateComment)
 		private.DELETE("/articles/:slug/comments/:id", h.DeleteComment)
+		private.GET("/articles/:slug/comments/:id/revisions", h.GetCommentRevisions)
+
+		private.PUT("/articles/:slug/reactions/:reaction", h.AddArticleReaction)
+		private.DELETE("/articles/:slug/reactions/:reaction", h.DeleteArticleReaction)
+		private.PUT("/articles/:slug/comments/:id/reactions/:reaction", h.AddCommentReaction)
+		private.DELETE("/articles/:slug/comments/:id/reactions/:reaction", h.DeleteCommentReaction)
 
 		private.POST("/articles/:slug/favorite", h.FavoriteArticle)
 		private.DELETE("/articles/:slug/favorite", h.UnfavoriteArticle)
+
+		private.POST("/articles/:slug/media", h.AttachArticleMedia)
+		private.DELETE("/articles/:slug/media/:id", h.DetachArticleMedia)
+
+		private.POST("/media", h.UploadMedia)
+		private.DELETE("/media/:id", h.DeleteMedia)
+		private.PUT("/me/avatar", h.SetAvatar)
 	}
 }
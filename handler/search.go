@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// SearchArticles searches articles by full-text query
+func (h *Handler) SearchArticles(ctx *gin.Context) {
+	h.logger.Info().Msg("search articles")
+
+	query := model.ParseSearchQuery(ctx.Query("q"))
+
+	err := query.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tagName := ctx.Query("tag")
+	author := ctx.Query("username")
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	var currentUser *model.User
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		currentUser, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("current user (id=%d) not found", userID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "current user not found"})
+			return
+		}
+	}
+
+	results, count, err := h.as.SearchArticles(ctx.Request.Context(), query, currentUser, tagName, author, limit, offset)
+	if err != nil {
+		msg := "failed to search articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	articleIDs := make([]uint, 0, len(results))
+	authorIDs := make([]uint, 0, len(results))
+	for _, result := range results {
+		articleIDs = append(articleIDs, result.Article.ID)
+		authorIDs = append(authorIDs, result.Article.Author.ID)
+	}
+
+	favorited, err := h.as.AreFavorited(ctx.Request.Context(), articleIDs, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	refs := make([]*model.Article, 0, len(results))
+	for i := range results {
+		refs = append(refs, &results[i].Article)
+	}
+
+	err = h.SetDetails(ctx, currentUser, refs, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.SearchArticleResponse, 0, len(results))
+	for _, result := range results {
+		resp = append(resp, result.ResponseSearchArticle(favorited[result.Article.ID], following[result.Article.Author.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.SearchArticlesResponse{Articles: resp, ArticlesCount: count})
+}
+
+// SearchProfiles searches profiles by similar words of username, name and bio
+func (h *Handler) SearchProfiles(ctx *gin.Context) {
+	h.logger.Info().Msg("search profiles")
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	search, err := model.ParseProfileSearch(ctx.Request.URL.Query())
+	if err == nil {
+		err = model.Page{Limit: limit, Offset: offset}.Validate()
+	}
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		search.Viewer, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("current user (id=%d) not found", userID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "current user not found"})
+			return
+		}
+	}
+
+	users, count, err := h.us.SearchProfiles(ctx.Request.Context(), search, limit, offset)
+	if err != nil {
+		msg := "failed to search profiles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	userIDs := make([]uint, 0, len(users))
+	refs := make([]*model.User, 0, len(users))
+	for i := range users {
+		userIDs = append(userIDs, users[i].ID)
+		refs = append(refs, &users[i])
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), search.Viewer, userIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetImageVariants(ctx, refs, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.ProfileResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, u.ResponseProfile(following[u.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ProfilesResponse{
+		Profiles:      resp,
+		ProfilesCount: count,
+	})
+}
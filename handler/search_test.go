@@ -0,0 +1,366 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_SearchHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	t.Run("SearchArticles", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		tag := model.Tag{Name: test.RandomString(t, 10)}
+
+		inputs := []model.Article{
+			{
+				Title:       "Postgres full text search",
+				Description: "Searching documents with tsvector",
+				Body:        "Full text search ranks documents by relevance.",
+				UserID:      fooUser.ID,
+				Author:      *fooUser,
+				Tags:        []model.Tag{tag},
+			},
+			{
+				Title:       "Writing web servers",
+				Description: "Routing with gin",
+				Body:        "A postgres connection pool is shared by handlers.",
+				UserID:      barUser.ID,
+				Author:      *barUser,
+				Tags:        []model.Tag{{Name: test.RandomString(t, 10)}},
+			},
+			{
+				Title:       "Gardening for beginners",
+				Description: "Tomatoes and herbs",
+				Body:        "Nothing about databases here.",
+				UserID:      barUser.ID,
+				Author:      *barUser,
+				Tags:        []model.Tag{tag},
+			},
+		}
+
+		articles := make([]*model.Article, 0, len(inputs))
+		for _, input := range inputs {
+			article, err := h.as.Create(context.Background(), &input)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			t.Cleanup(func() {
+				deleteArticle(t, lct.DB(), article.ID)
+			})
+
+			articles = append(articles, article)
+		}
+
+		tests := []struct {
+			title   string
+			reqUser *model.User
+			query   struct {
+				q      string
+				tag    string
+				author string
+			}
+			expectedStatusCode int
+			expectedArticles   []*model.Article
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"search articles: title ranks first",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: "postgres"},
+				http.StatusOK,
+				[]*model.Article{articles[0], articles[1]},
+				nil,
+				false,
+			},
+			{
+				"search articles: allow public access",
+				&model.User{ID: 0},
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: "postgres"},
+				http.StatusOK,
+				[]*model.Article{articles[0], articles[1]},
+				nil,
+				false,
+			},
+			{
+				"search articles: phrase",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: `"full text search"`},
+				http.StatusOK,
+				[]*model.Article{articles[0]},
+				nil,
+				false,
+			},
+			{
+				"search articles: prefix",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: "garden*"},
+				http.StatusOK,
+				[]*model.Article{articles[2]},
+				nil,
+				false,
+			},
+			{
+				"search articles: by tag name",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: tag.Name},
+				http.StatusOK,
+				[]*model.Article{articles[0], articles[2]},
+				nil,
+				false,
+			},
+			{
+				"search articles: with tag filter",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: "postgres", tag: tag.Name},
+				http.StatusOK,
+				[]*model.Article{articles[0]},
+				nil,
+				false,
+			},
+			{
+				"search articles: with author filter",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: "postgres", author: barUser.Username},
+				http.StatusOK,
+				[]*model.Article{articles[1]},
+				nil,
+				false,
+			},
+			{
+				"search articles: no match",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: test.RandomString(t, 20)},
+				http.StatusOK,
+				[]*model.Article{},
+				nil,
+				false,
+			},
+			{
+				"search articles: empty query",
+				fooUser,
+				struct {
+					q      string
+					tag    string
+					author string
+				}{q: ""},
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "validation error: Raw: cannot be blank."},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/search/articles", nil)
+
+			q := req.URL.Query()
+			q.Add("q", tt.query.q)
+			q.Add("tag", tt.query.tag)
+			q.Add("username", tt.query.author)
+			req.URL.RawQuery = q.Encode()
+
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+
+			h.SearchArticles(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+				continue
+			}
+
+			actualBody := test.GetResponseBody[message.SearchArticlesResponse](t, w.Result())
+			assert.Len(t, actualBody.Articles, len(tt.expectedArticles), tt.title)
+			assert.Len(t, tt.expectedArticles, int(actualBody.ArticlesCount), tt.title)
+
+			for i := 0; i < len(actualBody.Articles); i++ {
+				got := actualBody.Articles[i]
+				want := tt.expectedArticles[i]
+
+				assert.Equal(t, want.ID, got.Article.ID, tt.title)
+				assert.Equal(t, want.Title, got.Article.Title, tt.title)
+				assert.Equal(t, want.Author.Username, got.Article.Author.Username, tt.title)
+				assert.NotZero(t, got.Rank, tt.title)
+			}
+		}
+
+		// total count of matched articles is not limited to the page
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/search/articles?q=postgres&limit=1", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.SearchArticles(ctx)
+
+		actualBody := test.GetResponseBody[message.SearchArticlesResponse](t, w.Result())
+		assert.Len(t, actualBody.Articles, 1)
+		assert.Equal(t, int64(2), actualBody.ArticlesCount)
+
+		// markup of articles is escaped in highlights
+		word := strings.ToLower(test.RandomString(t, 12))
+		article, err := h.as.Create(context.Background(), &model.Article{
+			Title:       fmt.Sprintf(`<img src=x onerror="alert(1)"> %s`, word),
+			Description: word,
+			Body:        fmt.Sprintf("<script>alert(1)</script> %s", word),
+			UserID:      fooUser.ID,
+			Author:      *fooUser,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			deleteArticle(t, lct.DB(), article.ID)
+		})
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/search/articles?q="+word, nil)
+		w = httptest.NewRecorder()
+		ctx, _ = ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.SearchArticles(ctx)
+
+		actualBody = test.GetResponseBody[message.SearchArticlesResponse](t, w.Result())
+		if assert.Len(t, actualBody.Articles, 1) {
+			highlights := actualBody.Articles[0].Highlights
+			assert.NotContains(t, highlights.Title, "<img")
+			assert.Contains(t, highlights.Title, "&lt;img")
+			assert.Contains(t, highlights.Title, "<mark>")
+			assert.NotContains(t, highlights.Body, "<script>")
+		}
+	})
+
+	t.Run("SearchProfiles", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		bazUser := createRandomUser(t, lct.DB())
+
+		word := strings.ToLower(test.RandomString(t, 12))
+		for _, user := range []*model.User{fooUser, barUser} {
+			user.Bio = fmt.Sprintf("Writing about %s every week.", word)
+
+			_, err := h.us.Update(context.Background(), user)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err := h.us.Follow(context.Background(), bazUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		search := func(t *testing.T, reqUser *model.User, query url.Values) *http.Response {
+			t.Helper()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/search/profiles?"+query.Encode(), nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, reqUser.ID, time.Now())
+
+			h.SearchProfiles(ctx)
+
+			return w.Result()
+		}
+
+		usernames := func(resp message.ProfilesResponse) []string {
+			names := make([]string, 0, len(resp.Profiles))
+			for _, p := range resp.Profiles {
+				names = append(names, p.Username)
+			}
+			return names
+		}
+
+		// username of fooUser is user_ followed by its random part
+		resp := search(t, fooUser, url.Values{"q": {strings.TrimPrefix(fooUser.Username, "user_")}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profiles := test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(1), profiles.ProfilesCount)
+		assert.Equal(t, []string{fooUser.Username}, usernames(profiles))
+
+		resp = search(t, &model.User{ID: 0}, url.Values{"q": {word}, "sort": {"followers"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		profiles = test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(2), profiles.ProfilesCount)
+		assert.Equal(t, []string{barUser.Username, fooUser.Username}, usernames(profiles))
+
+		resp = search(t, bazUser, url.Values{"q": {word}, "sort": {"followers"}, "limit": {"1"}, "offset": {"1"}})
+		profiles = test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(2), profiles.ProfilesCount)
+		assert.Equal(t, []string{fooUser.Username}, usernames(profiles))
+
+		// blocked users are left out
+		err = h.us.Block(context.Background(), fooUser, bazUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = search(t, bazUser, url.Values{"q": {word}})
+		profiles = test.GetResponseBody[message.ProfilesResponse](t, resp)
+		assert.Equal(t, int64(1), profiles.ProfilesCount)
+		if assert.Len(t, profiles.Profiles, 1) {
+			assert.Equal(t, barUser.ResponseProfile(true), profiles.Profiles[0])
+		}
+
+		resp = search(t, bazUser, url.Values{"q": {word}, "sort": {"newest"}})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		resp = search(t, bazUser, url.Values{"q": {""}})
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "validation error: Query: cannot be blank."}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// Stream pushes new notifications of current user, and new comments and favorite count changes of
+// the article being viewed (if any) as server-sent events
+//
+// Events missed since Last-Event-ID (header or last_event_id query) are replayed first,
+// and heartbeats are sent while there is no event to keep connection open.
+func (h *Handler) Stream(ctx *gin.Context) {
+	h.logger.Info().Msg("stream")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	var articleID *uint
+	if article := ctx.Query("article"); article != "" {
+		slug, err := strconv.ParseUint(article, 10, 0)
+		if err != nil {
+			msg := "invalid article"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		article, err := h.as.GetByID(ctx.Request.Context(), uint(slug))
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("article (slug=%d) not found", slug))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "article not found"})
+			return
+		}
+
+		visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), currentUser, &article.Author)
+		if err != nil {
+			msg := "failed to get visibility of article"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+
+		if !visible {
+			msg := "forbidden"
+			err := fmt.Errorf("user (id=%d) attempted to stream article (id=%d) of private user", currentUser.ID, article.ID)
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+
+		articleID = &article.ID
+	}
+
+	var lastID uint
+	lastEventID := ctx.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = ctx.Query("last_event_id")
+	}
+
+	if lastEventID != "" {
+		id, err := strconv.ParseUint(lastEventID, 10, 0)
+		if err != nil {
+			msg := "invalid last event id"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+
+		lastID = uint(id)
+	}
+
+	// subscribe before replaying, so that no event falls in between
+	sub := h.hub.Subscribe(currentUser.ID, articleID)
+	defer h.hub.Unsubscribe(sub)
+
+	replay := []model.StreamEvent{}
+	if lastEventID != "" {
+		replay, err = h.hub.Replay(ctx.Request.Context(), sub, lastID)
+		if err != nil {
+			msg := "failed to get missed events"
+			h.logger.Error().Err(err).Msg(msg)
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+			return
+		}
+	}
+
+	// headers are flushed before any event is written, so content type is not left to the renderer
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+	ctx.Writer.WriteHeaderNow()
+	ctx.Writer.Flush()
+
+	// events pushed since subscribing may have been replayed too
+	replayed := make(map[uint]struct{}, len(replay))
+	for _, e := range replay {
+		err = h.sendStreamEvent(ctx, currentUser, e)
+		if err != nil {
+			h.logger.Error().Err(err).Uint("stream_event_id", e.ID).Msg("failed to send stream event")
+			return
+		}
+
+		replayed[e.ID] = struct{}{}
+	}
+
+	heartbeat := time.NewTicker(h.environ.StreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			return
+		case e, ok := <-sub.Events:
+			// subscription fell behind, client reconnects and replays
+			if !ok {
+				return
+			}
+
+			if _, ok := replayed[e.ID]; ok {
+				delete(replayed, e.ID)
+				continue
+			}
+
+			err = h.sendStreamEvent(ctx, currentUser, e)
+			if err != nil {
+				h.logger.Error().Err(err).Uint("stream_event_id", e.ID).Msg("failed to send stream event")
+				return
+			}
+		case <-heartbeat.C:
+			_, err = ctx.Writer.WriteString(": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+// sendStreamEvent writes an event with its target to stream,
+// events whose target no longer exists or is no longer visible to the user are skipped
+func (h *Handler) sendStreamEvent(ctx *gin.Context, user *model.User, e model.StreamEvent) error {
+	var data interface{}
+
+	switch e.Type {
+	case model.StreamEventNotification:
+		notification, err := h.ns.GetByID(ctx.Request.Context(), e.TargetID)
+		if err != nil {
+			return skipNotFound(err)
+		}
+
+		err = h.SetImageVariants(ctx, []*model.User{&notification.Actor}, nil)
+		if err != nil {
+			return err
+		}
+
+		data = notification.ResponseNotification()
+	case model.StreamEventComment:
+		comment, err := h.as.GetCommentByID(ctx.Request.Context(), e.TargetID)
+		if err != nil {
+			return skipNotFound(err)
+		}
+
+		article, err := h.as.GetByID(ctx.Request.Context(), comment.ArticleID)
+		if err != nil {
+			return skipNotFound(err)
+		}
+
+		// author may have gone private or blocked the user since subscribing
+		visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), user, &article.Author)
+		if err != nil || !visible {
+			return err
+		}
+
+		blocked, err := h.us.IsBlockedBetween(ctx.Request.Context(), user, &comment.Author)
+		if err != nil || blocked {
+			return err
+		}
+
+		err = h.SetDetails(ctx, user, nil, []*model.Comment{comment})
+		if err != nil {
+			return err
+		}
+
+		following, err := h.us.IsFollowing(ctx.Request.Context(), user, &comment.Author)
+		if err != nil {
+			return err
+		}
+
+		data = comment.ResponseComment(following)
+	case model.StreamEventFavorites:
+		article, err := h.as.GetByID(ctx.Request.Context(), e.TargetID)
+		if err != nil {
+			return skipNotFound(err)
+		}
+
+		visible, err := h.us.CanViewArticlesOf(ctx.Request.Context(), user, &article.Author)
+		if err != nil || !visible {
+			return err
+		}
+
+		data = message.FavoritesCountResponse{
+			ArticleID:      article.ID,
+			FavoritesCount: article.FavoritesCount,
+		}
+	default:
+		return nil
+	}
+
+	ctx.Render(-1, sse.Event{
+		Id:    strconv.FormatUint(uint64(e.ID), 10),
+		Event: e.Type,
+		Data:  data,
+	})
+	ctx.Writer.Flush()
+
+	return nil
+}
+
+// skipNotFound returns nil if err is because something is not found, otherwise err itself
+func skipNotFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_StreamHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	type streamEvent struct {
+		id    string
+		event string
+		data  string
+	}
+
+	// stream streams until timeout, so that replayed events are written before it ends
+	stream := func(t *testing.T, user *model.User, query, lastEventID string) (int, []streamEvent, *httptest.ResponseRecorder) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stream"+query, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+
+		if lastEventID != "" {
+			ctx.Request.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		reqCtx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
+		h.Stream(ctx)
+
+		events := []streamEvent{}
+		for _, block := range strings.Split(w.Body.String(), "\n\n") {
+			var e streamEvent
+			for _, line := range strings.Split(block, "\n") {
+				switch {
+				case strings.HasPrefix(line, "id:"):
+					e.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+				case strings.HasPrefix(line, "event:"):
+					e.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+				case strings.HasPrefix(line, "data:"):
+					e.data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+				}
+			}
+
+			if e.id != "" {
+				events = append(events, e)
+			}
+		}
+
+		return w.Result().StatusCode, events, w
+	}
+
+	fooUser := createRandomUser(t, lct.DB())
+	barUser := createRandomUser(t, lct.DB())
+	fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+	otherArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+
+	err := h.us.Follow(context.Background(), barUser, fooUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	barComment := createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+	createRandomComment(t, lct.DB(), otherArticle.ID, fooUser.ID)
+
+	err = h.as.AddFavorite(context.Background(), fooArticle, barUser, func(int64, time.Time) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relayOutbox(t, lct.DB())
+
+	articleQuery := fmt.Sprintf("?article=%d", fooArticle.ID)
+
+	t.Run("Stream: replay", func(t *testing.T) {
+		statusCode, events, w := stream(t, fooUser, articleQuery, "0")
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "text/event-stream", w.Result().Header.Get("Content-Type"))
+
+		// comment, favorite, then notifications of follow, comment and favorite once relayed
+		types := []string{}
+		for _, e := range events {
+			types = append(types, e.event)
+		}
+		assert.Equal(t, []string{
+			model.StreamEventComment,
+			model.StreamEventFavorites,
+			model.StreamEventNotification,
+			model.StreamEventNotification,
+			model.StreamEventNotification,
+		}, types)
+
+		var comment message.CommentResponse
+		err := json.Unmarshal([]byte(events[0].data), &comment)
+		assert.NoError(t, err)
+		assert.Equal(t, barComment.ID, comment.ID)
+		assert.Equal(t, barUser.Username, comment.Author.Username)
+
+		var favorites message.FavoritesCountResponse
+		err = json.Unmarshal([]byte(events[1].data), &favorites)
+		assert.NoError(t, err)
+		assert.Equal(t, message.FavoritesCountResponse{ArticleID: fooArticle.ID, FavoritesCount: 1}, favorites)
+
+		// only events after last event id are replayed
+		_, events, _ = stream(t, fooUser, articleQuery, events[3].id)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, model.StreamEventNotification, events[0].event)
+		}
+
+		// without article, only notifications are pushed
+		_, events, _ = stream(t, fooUser, "", "0")
+		assert.Len(t, events, 3)
+
+		// without last event id, nothing is replayed
+		_, events, _ = stream(t, fooUser, articleQuery, "")
+		assert.Empty(t, events)
+	})
+
+	t.Run("Stream: replay in order of transactions", func(t *testing.T) {
+		insertEvent := func(t *testing.T, tx *sql.Tx) uint {
+			t.Helper()
+
+			var id uint
+
+			queryString := `INSERT INTO article_management.stream_events (type, article_id, target_id) 
+				VALUES ($1, $2, $3) RETURNING id`
+			err := tx.QueryRowContext(context.Background(), queryString, model.StreamEventComment, fooArticle.ID, barComment.ID).Scan(&id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return id
+		}
+
+		_, events, _ := stream(t, fooUser, articleQuery, "0")
+		before := len(events)
+
+		// the event with a lower id is committed after the one with a higher id
+		firstTx, err := lct.DB().Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer firstTx.Rollback()
+
+		secondTx, err := lct.DB().Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer secondTx.Rollback()
+
+		firstID := insertEvent(t, firstTx)
+		secondID := insertEvent(t, secondTx)
+
+		err = secondTx.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// held back until the transaction started before it is finished
+		_, events, _ = stream(t, fooUser, articleQuery, "0")
+		assert.Len(t, events, before)
+
+		err = firstTx.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, events, _ = stream(t, fooUser, articleQuery, "0")
+		if assert.Len(t, events, before+2) {
+			assert.Equal(t, fmt.Sprintf("%d", firstID), events[before].id)
+			assert.Equal(t, fmt.Sprintf("%d", secondID), events[before+1].id)
+		}
+
+		_, events, _ = stream(t, fooUser, articleQuery, fmt.Sprintf("%d", firstID))
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, fmt.Sprintf("%d", secondID), events[0].id)
+		}
+	})
+
+	t.Run("Stream: following", func(t *testing.T) {
+		err := h.us.Follow(context.Background(), fooUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, events, _ := stream(t, fooUser, articleQuery, "0")
+		if assert.NotEmpty(t, events) && assert.Equal(t, model.StreamEventComment, events[0].event) {
+			var comment message.CommentResponse
+			err = json.Unmarshal([]byte(events[0].data), &comment)
+			assert.NoError(t, err)
+			assert.True(t, comment.Author.Following)
+		}
+	})
+
+	t.Run("Stream: blocks", func(t *testing.T) {
+		countComments := func(events []streamEvent) int {
+			count := 0
+			for _, e := range events {
+				if e.event == model.StreamEventComment {
+					count++
+				}
+			}
+			return count
+		}
+
+		_, events, _ := stream(t, fooUser, articleQuery, "0")
+		assert.NotZero(t, countComments(events))
+
+		// comments of blocked users are skipped
+		err := h.us.Block(context.Background(), fooUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, events, _ = stream(t, fooUser, articleQuery, "0")
+		assert.Zero(t, countComments(events))
+	})
+
+	t.Run("Stream: errors", func(t *testing.T) {
+		tests := []struct {
+			title              string
+			query              string
+			lastEventID        string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+		}{
+			{
+				"stream: invalid article",
+				"?article=invalid",
+				"",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid article"},
+			},
+			{
+				"stream: article not found",
+				"?article=0",
+				"",
+				http.StatusNotFound,
+				map[string]interface{}{"error": "article not found"},
+			},
+			{
+				"stream: invalid last event id",
+				articleQuery,
+				"invalid",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid last event id"},
+			},
+		}
+
+		for _, tt := range tests {
+			statusCode, _, w := stream(t, fooUser, tt.query, tt.lastEventID)
+			assert.Equal(t, tt.expectedStatusCode, statusCode, tt.title)
+
+			actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+			assert.Equal(t, tt.expectedError, actualBody, tt.title)
+		}
+	})
+}
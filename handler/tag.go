@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
 )
 
 // GetTags returns all of tags
@@ -26,3 +29,73 @@ func (h *Handler) GetTags(ctx *gin.Context) {
 
 	ctx.AbortWithStatusJSON(http.StatusOK, message.TagsResponse{Tags: tagNames})
 }
+
+// GetFollowedTags returns tags that current user follows
+func (h *Handler) GetFollowedTags(ctx *gin.Context) {
+	h.logger.Info().Msg("get followed tags")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	tags, err := h.as.GetFollowedTags(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get followed tags"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	tagNames := make([]string, 0, len(tags))
+	for _, t := range tags {
+		tagNames = append(tagNames, t.Name)
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.TagsResponse{Tags: tagNames})
+}
+
+// FollowTag follows a tag, whose articles are then in feed of current user
+func (h *Handler) FollowTag(ctx *gin.Context) {
+	h.logger.Info().Msg("follow tag")
+	h.updateFollowedTag(ctx, "follow", true, h.as.FollowTag)
+}
+
+// UnfollowTag unfollows a tag
+func (h *Handler) UnfollowTag(ctx *gin.Context) {
+	h.logger.Info().Msg("unfollow tag")
+	h.updateFollowedTag(ctx, "unfollow", false, h.as.UnfollowTag)
+}
+
+// updateFollowedTag applies update of action from current user to the tag of name param,
+// and responds with the tag
+func (h *Handler) updateFollowedTag(ctx *gin.Context, action string, following bool, update func(context.Context, *model.Tag, *model.User) error) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	tag, err := h.as.GetTagByName(ctx.Request.Context(), ctx.Param("name"))
+	if err != nil {
+		msg := "tag not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	err = update(ctx.Request.Context(), tag, currentUser)
+	if err != nil {
+		h.logger.Error().Err(err).
+			Msg(fmt.Sprintf("failed to %s tag: (ID: %d) -> (ID: %d)", action, currentUser.ID, tag.ID))
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("failed to %s tag", action)})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, tag.ResponseTag(following))
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/nathanbizkit/article-management-go/message"
@@ -74,4 +75,109 @@ func TestIntegration_TagHandler(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
 		assert.Equal(t, expected, actual)
 	})
+
+	t.Run("FollowTag", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		tag := model.Tag{Name: test.RandomString(t, 10)}
+		for _, user := range []*model.User{fooUser, barUser} {
+			randStr := test.RandomString(t, 10)
+			_, err := h.as.Create(context.Background(), &model.Article{
+				Title:       randStr,
+				Description: randStr,
+				Body:        randStr,
+				UserID:      user.ID,
+				Author:      *user,
+				Tags:        []model.Tag{tag},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		request := func(t *testing.T, handle gin.HandlerFunc, method, apiUrl, name string) *http.Response {
+			t.Helper()
+
+			req := httptest.NewRequest(method, apiUrl, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, barUser.ID, time.Now())
+			if name != "" {
+				ctx.AddParam("name", name)
+			}
+
+			handle(ctx)
+
+			return w.Result()
+		}
+
+		resp := request(t, h.FollowTag, http.MethodPost, "/api/v1/tags/"+tag.Name+"/follow", tag.Name)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.TagResponse{Tag: tag.Name, Following: true}, test.GetResponseBody[message.TagResponse](t, resp))
+
+		resp = request(t, h.GetFollowedTags, http.MethodGet, "/api/v1/me/tags", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.TagsResponse{Tags: []string{tag.Name}}, test.GetResponseBody[message.TagsResponse](t, resp))
+
+		// articles of followed tags are in feed, except those of current user
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed", "")
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		articles := test.GetResponseBody[message.ArticlesResponse](t, resp)
+		if assert.Len(t, articles.Articles, 1) {
+			assert.Equal(t, fooUser.Username, articles.Articles[0].Author.Username)
+			assert.False(t, articles.Articles[0].Author.Following)
+		}
+
+		// an article of a followed user and a followed tag is listed once
+		err := h.us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			source   string
+			expected int64
+		}{{"", 1}, {"users", 1}, {"tags", 1}} {
+			resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed?source="+tt.source, "")
+			assert.Equal(t, http.StatusOK, resp.StatusCode, tt.source)
+			articles = test.GetResponseBody[message.ArticlesResponse](t, resp)
+			assert.Equal(t, tt.expected, articles.ArticlesCount, tt.source)
+			if assert.Len(t, articles.Articles, 1, tt.source) {
+				assert.True(t, articles.Articles[0].Author.Following, tt.source)
+			}
+		}
+
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed?source=favorites", "")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		err = h.us.Unfollow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// articles of muted users are left out of followed tags
+		err = h.us.Mute(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed?source=tags", "")
+		assert.Equal(t, int64(0), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		err = h.us.Unmute(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = request(t, h.UnfollowTag, http.MethodDelete, "/api/v1/tags/"+tag.Name+"/follow", tag.Name)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, message.TagResponse{Tag: tag.Name, Following: false}, test.GetResponseBody[message.TagResponse](t, resp))
+
+		resp = request(t, h.GetFeedArticles, http.MethodGet, "/api/v1/articles/feed", "")
+		assert.Equal(t, int64(0), test.GetResponseBody[message.ArticlesResponse](t, resp).ArticlesCount)
+
+		resp = request(t, h.FollowTag, http.MethodPost, "/api/v1/tags/unknown_tag/follow", "unknown_tag")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, map[string]interface{}{"error": "tag not found"}, test.GetResponseBody[map[string]interface{}](t, resp))
+	})
 }
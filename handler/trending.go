@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// GetTrendingArticles gets articles ranked by their recent views, comments and favorites in a window
+func (h *Handler) GetTrendingArticles(ctx *gin.Context) {
+	h.logger.Info().Msg("get trending articles")
+
+	window, err := model.GetTrendingWindow(ctx.Query("window"))
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err = model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var currentUser *model.User
+
+	userID := h.authen.GetContextUserID(ctx)
+	if userID != 0 {
+		currentUser, err = h.us.GetByID(ctx.Request.Context(), userID)
+		if err != nil {
+			h.logger.Error().Err(err).Msg(fmt.Sprintf("current user (id=%d) not found", userID))
+			ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "current user not found"})
+			return
+		}
+	}
+
+	articles, count, err := h.ts.GetTrendingArticles(ctx.Request.Context(), window.Name, currentUser, limit, offset)
+	if err != nil {
+		msg := "failed to get trending articles"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	articleIDs := make([]uint, 0, len(articles))
+	authorIDs := make([]uint, 0, len(articles))
+	refs := make([]*model.Article, 0, len(articles))
+	for i := range articles {
+		articleIDs = append(articleIDs, articles[i].ID)
+		authorIDs = append(authorIDs, articles[i].Author.ID)
+		refs = append(refs, &articles[i])
+	}
+
+	favorited, err := h.as.AreFavorited(ctx.Request.Context(), articleIDs, currentUser)
+	if err != nil {
+		msg := "failed to get favorited status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	following, err := h.us.AreFollowing(ctx.Request.Context(), currentUser, authorIDs)
+	if err != nil {
+		msg := "failed to get following status"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = h.SetDetails(ctx, currentUser, refs, nil)
+	if err != nil {
+		msg := "failed to get article details"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	resp := make([]message.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		resp = append(resp, article.ResponseArticle(favorited[article.ID], following[article.Author.ID]))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.ArticlesResponse{
+		Articles:      resp,
+		ArticlesCount: count,
+	})
+}
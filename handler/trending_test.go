@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_TrendingHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	fooUser := createRandomUser(t, lct.DB())
+	barUser := createRandomUser(t, lct.DB())
+	bazUser := createRandomUser(t, lct.DB())
+
+	fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+	barArticle := createRandomArticle(t, lct.DB(), barUser.ID)
+	bazArticle := createRandomArticle(t, lct.DB(), bazUser.ID)
+
+	request := func(t *testing.T, handle gin.HandlerFunc, apiUrl string, user *model.User, params gin.Params) *http.Response {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, apiUrl, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+		for _, p := range params {
+			ctx.AddParam(p.Key, p.Value)
+		}
+
+		handle(ctx)
+
+		return w.Result()
+	}
+
+	recompute := func(t *testing.T) {
+		t.Helper()
+
+		relayOutbox(t, lct.DB())
+
+		err := h.ts.Recompute(context.Background(), time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// getting an article counts as a view, a comment weighs more and a favorite the most
+	slug := fmt.Sprintf("%d", fooArticle.ID)
+	resp := request(t, h.GetArticle, "/api/v1/articles/"+slug, bazUser, gin.Params{{Key: "slug", Value: slug}})
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	createRandomComment(t, lct.DB(), bazArticle.ID, fooUser.ID)
+
+	err := h.as.AddFavorite(context.Background(), barArticle, bazUser, func(int64, time.Time) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recompute(t)
+
+	t.Run("GetArticle: views count once per viewer and hour", func(t *testing.T) {
+		// viewed again by the same viewer, and by the author
+		for _, user := range []*model.User{bazUser, fooUser} {
+			resp := request(t, h.GetArticle, "/api/v1/articles/"+slug, user, gin.Params{{Key: "slug", Value: slug}})
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}
+
+		var views int
+		err := lct.DB().QueryRow(`SELECT COALESCE(SUM(views), 0) FROM article_management.article_activity 
+			WHERE article_id = $1`, fooArticle.ID).Scan(&views)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, 1, views)
+	})
+
+	t.Run("GetTrendingArticles", func(t *testing.T) {
+		for _, window := range []string{"", model.TrendingWindowWeek, model.TrendingWindowMonth} {
+			resp := request(t, h.GetTrendingArticles, "/api/v1/articles/trending?window="+window, &model.User{ID: 0}, nil)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			articles := test.GetResponseBody[message.ArticlesResponse](t, resp)
+			assert.Equal(t, int64(3), articles.ArticlesCount)
+			if assert.Len(t, articles.Articles, 3) {
+				assert.Equal(t, barArticle.ID, articles.Articles[0].ID)
+				assert.Equal(t, bazArticle.ID, articles.Articles[1].ID)
+				assert.Equal(t, fooArticle.ID, articles.Articles[2].ID)
+			}
+		}
+
+		resp := request(t, h.GetTrendingArticles, "/api/v1/articles/trending?limit=1&offset=1", bazUser, nil)
+		articles := test.GetResponseBody[message.ArticlesResponse](t, resp)
+		if assert.Len(t, articles.Articles, 1) {
+			assert.Equal(t, bazArticle.ID, articles.Articles[0].ID)
+		}
+
+		// articles of blocked users are left out
+		err := h.us.Block(context.Background(), fooUser, barUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resp = request(t, h.GetTrendingArticles, "/api/v1/articles/trending", fooUser, nil)
+		articles = test.GetResponseBody[message.ArticlesResponse](t, resp)
+		assert.Equal(t, int64(2), articles.ArticlesCount)
+
+		// undone favorites are taken back when ranked again
+		err = h.as.DeleteFavorite(context.Background(), barArticle, bazUser, func(int64, time.Time) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		recompute(t)
+
+		resp = request(t, h.GetTrendingArticles, "/api/v1/articles/trending", bazUser, nil)
+		articles = test.GetResponseBody[message.ArticlesResponse](t, resp)
+		if assert.Len(t, articles.Articles, 2) {
+			assert.Equal(t, bazArticle.ID, articles.Articles[0].ID)
+			assert.Equal(t, fooArticle.ID, articles.Articles[1].ID)
+		}
+
+		resp = request(t, h.GetTrendingArticles, "/api/v1/articles/trending?window=1y", bazUser, nil)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t,
+			map[string]interface{}{"error": "validation error: window must be one of 24h, 7d, 30d"},
+			test.GetResponseBody[map[string]interface{}](t, resp),
+		)
+	})
+}
@@ -164,8 +164,15 @@ func (h *Handler) GetCurrentUser(ctx *gin.Context) {
 
 	h.authen.SetCookieToken(ctx, *token, APIGroupPath)
 
-	following := false
-	ctx.AbortWithStatusJSON(http.StatusOK, currentUser.ResponseProfile(following))
+	err = h.SetImageVariants(ctx, []*model.User{currentUser}, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, currentUser.ResponseCurrentUser())
 }
 
 // UpdateCurrentUser updates current user's profile
@@ -189,6 +196,9 @@ func (h *Handler) UpdateCurrentUser(ctx *gin.Context) {
 	}
 
 	isPlainPassword := currentUser.Overwrite(req.Username, req.Email, req.Password, req.Name, req.Bio, req.Image)
+	if req.Private != nil {
+		currentUser.Private = *req.Private
+	}
 
 	err = currentUser.Validate(isPlainPassword)
 	if err != nil {
@@ -225,6 +235,13 @@ func (h *Handler) UpdateCurrentUser(ctx *gin.Context) {
 
 	h.authen.SetCookieToken(ctx, *token, APIGroupPath)
 
-	following := false
-	ctx.AbortWithStatusJSON(http.StatusOK, updatedUser.ResponseProfile(following))
+	err = h.SetImageVariants(ctx, []*model.User{updatedUser}, nil)
+	if err != nil {
+		msg := "failed to get image variants"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, updatedUser.ResponseCurrentUser())
 }
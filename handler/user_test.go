@@ -473,7 +473,7 @@ func TestIntegration_UserHandler(t *testing.T) {
 				"get current user: success",
 				fooUser,
 				http.StatusOK,
-				fooUser.ResponseProfile(false),
+				fooUser.ResponseCurrentUser(),
 				nil,
 				false,
 			},
@@ -545,7 +545,12 @@ func TestIntegration_UserHandler(t *testing.T) {
 			CreatedAt: fooUser.CreatedAt,
 			UpdatedAt: fooUser.UpdatedAt,
 		}
-		expected := user.ResponseProfile(false)
+		expected := user.ResponseCurrentUser()
+
+		private := true
+		privateUser := user
+		privateUser.Private = private
+		expectedPrivate := privateUser.ResponseCurrentUser()
 
 		tests := []struct {
 			title              string
@@ -588,6 +593,19 @@ func TestIntegration_UserHandler(t *testing.T) {
 				nil,
 				false,
 			},
+			{
+				"update fooUser: private",
+				fooUser,
+				&message.UpdateUserRequest{
+					Bio:     randStr,
+					Image:   "https://imgur.com/image.jpg",
+					Private: &private,
+				},
+				http.StatusOK,
+				expectedPrivate,
+				nil,
+				false,
+			},
 			{
 				"update: wrong user id",
 				&model.User{ID: 0},
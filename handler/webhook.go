@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// CreateWebhook creates a webhook of current user, only moderators can subscribe to all articles
+func (h *Handler) CreateWebhook(ctx *gin.Context) {
+	h.logger.Info().Msg("create webhook")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	var req message.CreateWebhookRequest
+	err = ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if req.AllArticles && !h.IsModerator(currentUser) {
+		err := fmt.Errorf("current user (id=%d) is forbidden to subscribe to all articles", currentUser.ID)
+		msg := "forbidden"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return
+	}
+
+	webhook, err := model.NewWebhook(currentUser.ID, req.URL, req.Events, req.AllArticles)
+	if err != nil {
+		msg := "failed to create webhook"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	err = webhook.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	createdWebhook, err := h.ws.Create(ctx.Request.Context(), &webhook)
+	if err != nil {
+		msg := "failed to create webhook"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	// secret is only shown once, for receivers to verify signatures
+	withSecret := true
+	ctx.AbortWithStatusJSON(http.StatusCreated, createdWebhook.ResponseWebhook(withSecret))
+}
+
+// GetWebhooks gets webhooks of current user
+func (h *Handler) GetWebhooks(ctx *gin.Context) {
+	h.logger.Info().Msg("get webhooks")
+
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return
+	}
+
+	webhooks, err := h.ws.GetByUser(ctx.Request.Context(), currentUser)
+	if err != nil {
+		msg := "failed to get webhooks"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	withSecret := false
+	resp := make([]message.WebhookResponse, 0, len(webhooks))
+	for _, w := range webhooks {
+		resp = append(resp, w.ResponseWebhook(withSecret))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.WebhooksResponse{Webhooks: resp})
+}
+
+// GetWebhook gets a webhook of current user
+func (h *Handler) GetWebhook(ctx *gin.Context) {
+	h.logger.Info().Msg("get webhook")
+
+	webhook, ok := h.getWebhookOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	withSecret := false
+	ctx.AbortWithStatusJSON(http.StatusOK, webhook.ResponseWebhook(withSecret))
+}
+
+// UpdateWebhook updates a webhook of current user
+func (h *Handler) UpdateWebhook(ctx *gin.Context) {
+	h.logger.Info().Msg("update webhook")
+
+	webhook, ok := h.getWebhookOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req message.UpdateWebhookRequest
+	err := ctx.ShouldBindJSON(&req)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to bind request body")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	webhook.Overwrite(req.URL, req.Events, req.Active)
+
+	err = webhook.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updatedWebhook, err := h.ws.Update(ctx.Request.Context(), webhook)
+	if err != nil {
+		msg := "failed to update webhook"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	withSecret := false
+	ctx.AbortWithStatusJSON(http.StatusOK, updatedWebhook.ResponseWebhook(withSecret))
+}
+
+// DeleteWebhook deletes a webhook of current user along with its deliveries
+func (h *Handler) DeleteWebhook(ctx *gin.Context) {
+	h.logger.Info().Msg("delete webhook")
+
+	webhook, ok := h.getWebhookOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.ws.Delete(ctx.Request.Context(), webhook)
+	if err != nil {
+		msg := "failed to delete webhook"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	ctx.AbortWithStatus(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries gets deliveries of a webhook of current user, newest first
+func (h *Handler) GetWebhookDeliveries(ctx *gin.Context) {
+	h.logger.Info().Msg("get webhook deliveries")
+
+	webhook, ok := h.getWebhookOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	limit, offset := h.GetPaginationQuery(ctx, defaultLimit, defaultOffset)
+
+	err := model.Page{Limit: limit, Offset: offset}.Validate()
+	if err != nil {
+		err := fmt.Errorf("validation error: %w", err)
+		h.logger.Error().Err(err).Msg("validation error")
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	deliveries, deliveriesCount, err := h.ws.GetDeliveries(ctx.Request.Context(), webhook, limit, offset)
+	if err != nil {
+		msg := "failed to get webhook deliveries"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	withDetails := false
+	resp := make([]message.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, d.ResponseWebhookDelivery(withDetails))
+	}
+
+	ctx.AbortWithStatusJSON(http.StatusOK, message.WebhookDeliveriesResponse{
+		Deliveries:      resp,
+		DeliveriesCount: deliveriesCount,
+	})
+}
+
+// GetWebhookDelivery gets a delivery of a webhook of current user with its payload and attempt log
+func (h *Handler) GetWebhookDelivery(ctx *gin.Context) {
+	h.logger.Info().Msg("get webhook delivery")
+
+	delivery, ok := h.getWebhookDeliveryOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	withDetails := true
+	ctx.AbortWithStatusJSON(http.StatusOK, delivery.ResponseWebhookDelivery(withDetails))
+}
+
+// RedeliverWebhookDelivery queues a delivery of a webhook of current user to be attempted again right away
+func (h *Handler) RedeliverWebhookDelivery(ctx *gin.Context) {
+	h.logger.Info().Msg("redeliver webhook delivery")
+
+	delivery, ok := h.getWebhookDeliveryOfCurrentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := h.ws.Redeliver(ctx.Request.Context(), delivery)
+	if err != nil {
+		msg := "failed to redeliver webhook delivery"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": msg})
+		return
+	}
+
+	withDetails := true
+	ctx.AbortWithStatusJSON(http.StatusAccepted, delivery.ResponseWebhookDelivery(withDetails))
+}
+
+// getWebhookOfCurrentUser returns webhook from url parameters if it belongs to current user, or aborts
+func (h *Handler) getWebhookOfCurrentUser(ctx *gin.Context) (*model.Webhook, bool) {
+	currentUser, err := h.GetCurrentUserFromContext(ctx)
+	if err != nil {
+		msg := "current user not found"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": msg})
+		return nil, false
+	}
+
+	id, err := h.GetIDFromParam(ctx, "id")
+	if err != nil {
+		msg := "invalid webhook id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return nil, false
+	}
+
+	webhook, err := h.ws.GetByID(ctx.Request.Context(), id)
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("webhook (id=%d) not found", id))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "webhook not found"})
+		return nil, false
+	}
+
+	if webhook.UserID != currentUser.ID {
+		err := fmt.Errorf(
+			"current user (id=%d) is forbidden to access this webhook (id=%d)",
+			currentUser.ID, webhook.ID,
+		)
+		msg := "forbidden"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+		return nil, false
+	}
+
+	return webhook, true
+}
+
+// getWebhookDeliveryOfCurrentUser returns delivery from url parameters if its webhook belongs to current user, or aborts
+func (h *Handler) getWebhookDeliveryOfCurrentUser(ctx *gin.Context) (*model.WebhookDelivery, bool) {
+	webhook, ok := h.getWebhookOfCurrentUser(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	deliveryID, err := h.GetIDFromParam(ctx, "delivery_id")
+	if err != nil {
+		msg := "invalid delivery id"
+		h.logger.Error().Err(err).Msg(msg)
+		ctx.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": msg})
+		return nil, false
+	}
+
+	delivery, err := h.ws.GetDeliveryByID(ctx.Request.Context(), deliveryID)
+	if err == nil && delivery.WebhookID != webhook.ID {
+		err = fmt.Errorf("delivery (id=%d) is not of webhook (id=%d)", delivery.ID, webhook.ID)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Msg(fmt.Sprintf("delivery (id=%d) not found", deliveryID))
+		ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "delivery not found"})
+		return nil, false
+	}
+
+	return delivery, true
+}
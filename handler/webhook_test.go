@@ -0,0 +1,458 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_WebhookHandler(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	gin.SetMode("test")
+	h, lct := setup(t)
+
+	createWebhook := func(t *testing.T, user *model.User, events []string, allArticles bool) *model.Webhook {
+		t.Helper()
+
+		webhook, err := model.NewWebhook(user.ID, "https://example.com/hook", events, allArticles)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		createdWebhook, err := h.ws.Create(context.Background(), &webhook)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return createdWebhook
+	}
+
+	getDeliveries := func(t *testing.T, user *model.User, webhook *model.Webhook) message.WebhookDeliveriesResponse {
+		t.Helper()
+
+		id := strconv.Itoa(int(webhook.ID))
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+id+"/deliveries", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, user.ID, time.Now())
+		ctx.AddParam("id", id)
+
+		h.GetWebhookDeliveries(ctx)
+
+		if !assert.Equal(t, http.StatusOK, w.Result().StatusCode) {
+			return message.WebhookDeliveriesResponse{}
+		}
+
+		return test.GetResponseBody[message.WebhookDeliveriesResponse](t, w.Result())
+	}
+
+	events := func(resp message.WebhookDeliveriesResponse) []string {
+		out := []string{}
+		for _, d := range resp.Deliveries {
+			out = append(out, d.Event)
+		}
+		return out
+	}
+
+	t.Run("CreateWebhook", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		moderator := createRandomUser(t, lct.DB())
+
+		lct.Environ().Moderators = []string{moderator.Username}
+		t.Cleanup(func() {
+			lct.Environ().Moderators = nil
+		})
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqBody            *message.CreateWebhookRequest
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"create webhook: success",
+				fooUser,
+				&message.CreateWebhookRequest{
+					URL:    "https://example.com/hook",
+					Events: []string{model.WebhookEventArticlePublished, model.WebhookEventCommentCreated},
+				},
+				http.StatusCreated,
+				nil,
+				false,
+			},
+			{
+				"create webhook: moderator subscribes to all articles",
+				moderator,
+				&message.CreateWebhookRequest{
+					URL:         "https://example.com/hook",
+					Events:      model.WebhookEvents,
+					AllArticles: true,
+				},
+				http.StatusCreated,
+				nil,
+				false,
+			},
+			{
+				"create webhook: forbidden to subscribe to all articles",
+				fooUser,
+				&message.CreateWebhookRequest{
+					URL:         "https://example.com/hook",
+					Events:      model.WebhookEvents,
+					AllArticles: true,
+				},
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+			{
+				"create webhook: unknown event",
+				fooUser,
+				&message.CreateWebhookRequest{
+					URL:    "https://example.com/hook",
+					Events: []string{"article.liked"},
+				},
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "validation error: Events: (0: must be a valid value.)."},
+				true,
+			},
+			{
+				"create webhook: url without http scheme",
+				fooUser,
+				&message.CreateWebhookRequest{
+					URL:    "ftp://example.com/hook",
+					Events: model.WebhookEvents,
+				},
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "validation error: URL: must be an http or https url."},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(tt.reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+
+			h.CreateWebhook(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.WebhookResponse](t, w.Result())
+				assert.Equal(t, tt.reqBody.URL, actualBody.URL, tt.title)
+				assert.Equal(t, tt.reqBody.Events, actualBody.Events, tt.title)
+				assert.Equal(t, tt.reqBody.AllArticles, actualBody.AllArticles, tt.title)
+				assert.True(t, actualBody.Active, tt.title)
+				assert.Len(t, actualBody.Secret, 64, tt.title)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+
+		h.GetWebhooks(ctx)
+
+		assert.Equal(t, http.StatusOK, w.Result().StatusCode)
+		actualBody := test.GetResponseBody[message.WebhooksResponse](t, w.Result())
+		if assert.Len(t, actualBody.Webhooks, 1) {
+			// secret is not shown again
+			assert.Empty(t, actualBody.Webhooks[0].Secret)
+		}
+	})
+
+	t.Run("UpdateWebhook", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+
+		webhook := createWebhook(t, fooUser, model.WebhookEvents, false)
+		id := strconv.Itoa(int(webhook.ID))
+		inactive := false
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqID              string
+			reqBody            *message.UpdateWebhookRequest
+			expectedStatusCode int
+			expectedBody       *message.WebhookResponse
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"update webhook: success",
+				fooUser,
+				id,
+				&message.UpdateWebhookRequest{
+					URL:    "https://example.com/other",
+					Events: []string{model.WebhookEventArticleDeleted},
+					Active: &inactive,
+				},
+				http.StatusOK,
+				&message.WebhookResponse{
+					ID:     webhook.ID,
+					URL:    "https://example.com/other",
+					Events: []string{model.WebhookEventArticleDeleted},
+					Active: false,
+				},
+				nil,
+				false,
+			},
+			{
+				"update webhook: invalid webhook id",
+				fooUser,
+				"invalid_id",
+				&message.UpdateWebhookRequest{},
+				http.StatusBadRequest,
+				nil,
+				map[string]interface{}{"error": "invalid webhook id"},
+				true,
+			},
+			{
+				"update webhook: wrong webhook id",
+				fooUser,
+				"0",
+				&message.UpdateWebhookRequest{},
+				http.StatusNotFound,
+				nil,
+				map[string]interface{}{"error": "webhook not found"},
+				true,
+			},
+			{
+				"update webhook: forbidden to update other user's webhook",
+				barUser,
+				id,
+				&message.UpdateWebhookRequest{},
+				http.StatusForbidden,
+				nil,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			body, err := json.Marshal(tt.reqBody)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/webhooks/"+tt.reqID, bytes.NewReader(body))
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", tt.reqID)
+
+			h.UpdateWebhook(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.WebhookResponse](t, w.Result())
+				assert.Equal(t, tt.expectedBody.ID, actualBody.ID, tt.title)
+				assert.Equal(t, tt.expectedBody.URL, actualBody.URL, tt.title)
+				assert.Equal(t, tt.expectedBody.Events, actualBody.Events, tt.title)
+				assert.Equal(t, tt.expectedBody.Active, actualBody.Active, tt.title)
+			}
+		}
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/webhooks/"+id, nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("id", id)
+
+		h.DeleteWebhook(ctx)
+
+		assert.Equal(t, http.StatusNoContent, w.Result().StatusCode)
+
+		_, err := h.ws.GetByID(context.Background(), webhook.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("Deliveries", func(t *testing.T) {
+		fooUser := createRandomUser(t, lct.DB())
+		barUser := createRandomUser(t, lct.DB())
+		moderator := createRandomUser(t, lct.DB())
+
+		// events written before webhooks are created are not delivered to them
+		relayOutbox(t, lct.DB())
+
+		fooWebhook := createWebhook(t, fooUser, []string{
+			model.WebhookEventArticlePublished,
+			model.WebhookEventArticleDeleted,
+			model.WebhookEventCommentCreated,
+		}, false)
+		allWebhook := createWebhook(t, moderator, []string{model.WebhookEventArticlePublished}, true)
+
+		inactiveWebhook := createWebhook(t, fooUser, model.WebhookEvents, false)
+		inactiveWebhook.Active = false
+		_, err := h.ws.Update(context.Background(), inactiveWebhook)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fooArticle := createRandomArticle(t, lct.DB(), fooUser.ID)
+		createRandomArticle(t, lct.DB(), barUser.ID)
+		createRandomComment(t, lct.DB(), fooArticle.ID, barUser.ID)
+
+		fooArticle.Title = "updated title"
+		_, err = h.as.Update(context.Background(), fooArticle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		relayOutbox(t, lct.DB())
+
+		// updates are not subscribed, and articles of other users are not delivered
+		fooResp := getDeliveries(t, fooUser, fooWebhook)
+		assert.Equal(t, []string{model.WebhookEventCommentCreated, model.WebhookEventArticlePublished}, events(fooResp))
+		assert.Equal(t, int64(2), fooResp.DeliveriesCount)
+		assert.Equal(t, model.WebhookDeliveryPending, fooResp.Deliveries[0].Status)
+		assert.NotNil(t, fooResp.Deliveries[0].NextAttemptAt)
+
+		// subscribers of all articles get articles of every user, including those of other tests
+		allResp := getDeliveries(t, moderator, allWebhook)
+		assert.GreaterOrEqual(t, allResp.DeliveriesCount, int64(2))
+		for _, event := range events(allResp) {
+			assert.Equal(t, model.WebhookEventArticlePublished, event)
+		}
+
+		// inactive webhooks get nothing
+		inactiveResp := getDeliveries(t, fooUser, inactiveWebhook)
+		assert.Empty(t, inactiveResp.Deliveries)
+
+		webhookID := strconv.Itoa(int(fooWebhook.ID))
+		deliveryID := strconv.Itoa(int(fooResp.Deliveries[1].ID))
+
+		tests := []struct {
+			title              string
+			reqUser            *model.User
+			reqDeliveryID      string
+			expectedStatusCode int
+			expectedError      map[string]interface{}
+			hasError           bool
+		}{
+			{
+				"get webhook delivery: success",
+				fooUser,
+				deliveryID,
+				http.StatusOK,
+				nil,
+				false,
+			},
+			{
+				"get webhook delivery: invalid delivery id",
+				fooUser,
+				"invalid_id",
+				http.StatusBadRequest,
+				map[string]interface{}{"error": "invalid delivery id"},
+				true,
+			},
+			{
+				"get webhook delivery: delivery of other webhook",
+				fooUser,
+				strconv.Itoa(int(allResp.Deliveries[0].ID)),
+				http.StatusNotFound,
+				map[string]interface{}{"error": "delivery not found"},
+				true,
+			},
+			{
+				"get webhook delivery: forbidden to get delivery of other user's webhook",
+				barUser,
+				deliveryID,
+				http.StatusForbidden,
+				map[string]interface{}{"error": "forbidden"},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/webhooks/"+webhookID+"/deliveries/"+tt.reqDeliveryID, nil)
+			w := httptest.NewRecorder()
+			ctx, _ := ctxWithToken(t, lct.Environ(), w, req, tt.reqUser.ID, time.Now())
+			ctx.AddParam("id", webhookID)
+			ctx.AddParam("delivery_id", tt.reqDeliveryID)
+
+			h.GetWebhookDelivery(ctx)
+
+			assert.Equal(t, tt.expectedStatusCode, w.Result().StatusCode, tt.title)
+
+			if tt.hasError {
+				actualBody := test.GetResponseBody[map[string]interface{}](t, w.Result())
+				assert.Equal(t, tt.expectedError, actualBody, tt.title)
+			} else {
+				actualBody := test.GetResponseBody[message.WebhookDeliveryResponse](t, w.Result())
+				assert.Empty(t, actualBody.AttemptLog, tt.title)
+
+				var payload message.WebhookPayload
+				err := json.Unmarshal(actualBody.Payload, &payload)
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, model.WebhookEventArticlePublished, payload.Event, tt.title)
+				assert.Equal(t, fooArticle.ID, payload.ArticleID, tt.title)
+				if assert.NotNil(t, payload.Article, tt.title) {
+					assert.Equal(t, fooUser.Username, payload.Article.Author.Username, tt.title)
+				}
+			}
+		}
+
+		// deleting an article is delivered with the article as it was
+		deleteArticle(t, lct.DB(), fooArticle.ID)
+		relayOutbox(t, lct.DB())
+
+		fooResp = getDeliveries(t, fooUser, fooWebhook)
+		assert.Equal(t, model.WebhookEventArticleDeleted, fooResp.Deliveries[0].Event)
+
+		// redelivery starts attempts over
+		code := http.StatusInternalServerError
+		delivery, err := h.ws.GetDeliveryByID(context.Background(), fooResp.Deliveries[1].ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		delivery.RecordAttempt(&code, time.Now(), 1)
+		err = h.ws.SaveAttempt(context.Background(), delivery, &model.WebhookDeliveryAttempt{ResponseCode: &code})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		deliveryID = strconv.Itoa(int(delivery.ID))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks/"+webhookID+"/deliveries/"+deliveryID+"/redeliver", nil)
+		w := httptest.NewRecorder()
+		ctx, _ := ctxWithToken(t, lct.Environ(), w, req, fooUser.ID, time.Now())
+		ctx.AddParam("id", webhookID)
+		ctx.AddParam("delivery_id", deliveryID)
+
+		h.RedeliverWebhookDelivery(ctx)
+
+		assert.Equal(t, http.StatusAccepted, w.Result().StatusCode)
+		actualBody := test.GetResponseBody[message.WebhookDeliveryResponse](t, w.Result())
+		assert.Equal(t, model.WebhookDeliveryPending, actualBody.Status)
+		assert.Equal(t, 0, actualBody.Attempts)
+		assert.Equal(t, &code, actualBody.LastResponseCode)
+		assert.Len(t, actualBody.AttemptLog, 1)
+	})
+}
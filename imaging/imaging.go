@@ -0,0 +1,188 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // register gif decoder
+	"image/jpeg"
+	"image/png"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register webp decoder
+)
+
+const (
+	jpegQuality = 85
+	hashLen     = 16
+
+	// maxPixels bounds width times height of images to decode,
+	// a decoded image takes 4 to 8 bytes per pixel regardless of its encoded size
+	maxPixels = 40_000_000
+)
+
+var errTooManyPixels = errors.New("image dimensions are too large")
+
+// Variant is an encoded image resized for a variant spec
+type Variant struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Hash        string
+	Data        []byte
+}
+
+// GenerateVariants decodes image data and generates a variant for each spec,
+// variants are upright, carry no metadata and are never upscaled
+func GenerateVariants(data []byte, specs []model.MediaVariantSpec) ([]Variant, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, errTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = Orientation(data)
+	}
+
+	// png and gif keep transparency, anything else is encoded as jpeg
+	contentType := "image/jpeg"
+	if format == "png" || format == "gif" {
+		contentType = "image/png"
+	}
+
+	variants := make([]Variant, 0, len(specs))
+	for _, spec := range specs {
+		width, height := spec.Width, spec.Height
+		if orientation >= 5 {
+			// image is transposed when displayed, resize for the box as seen by viewers
+			width, height = height, width
+		}
+
+		dst := resize(src, width, height, spec.Crop, contentType == "image/jpeg")
+		dst = orient(dst, orientation)
+
+		var buf bytes.Buffer
+		if contentType == "image/png" {
+			err = png.Encode(&buf, dst)
+		} else {
+			err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(buf.Bytes())
+
+		variants = append(variants, Variant{
+			Name:        spec.Name,
+			ContentType: contentType,
+			Width:       dst.Bounds().Dx(),
+			Height:      dst.Bounds().Dy(),
+			Hash:        hex.EncodeToString(sum[:])[:hashLen],
+			Data:        buf.Bytes(),
+		})
+	}
+
+	return variants, nil
+}
+
+// resize scales src to fit within width and height, or to fill them
+// with a centered crop, without upscaling
+func resize(src image.Image, width, height int, crop, opaque bool) *image.RGBA {
+	b := src.Bounds()
+	srcRect := b
+
+	if crop {
+		// largest centered region of source with aspect ratio of target
+		cw, ch := b.Dx(), b.Dx()*height/width
+		if ch > b.Dy() {
+			cw, ch = b.Dy()*width/height, b.Dy()
+		}
+
+		x := b.Min.X + (b.Dx()-cw)/2
+		y := b.Min.Y + (b.Dy()-ch)/2
+		srcRect = image.Rect(x, y, x+cw, y+ch)
+
+		if cw < width {
+			width, height = max(cw, 1), max(ch, 1)
+		}
+	} else {
+		w, h := b.Dx(), b.Dy()
+		if w > width || h > height {
+			if w*height > h*width {
+				w, h = width, h*width/w
+			} else {
+				w, h = w*height/h, height
+			}
+		}
+		width, height = max(w, 1), max(h, 1)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	op := draw.Src
+	if opaque {
+		// jpeg has no alpha channel, transparent pixels are flattened on white
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		op = draw.Over
+	}
+
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, srcRect, op, nil)
+	return dst
+}
+
+// orient transforms img according to exif orientation so that it is upright
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				dx, dy = w-1-x, y
+			case 3: // rotated 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				dx, dy = x, h-1-y
+			case 5: // transposed
+				dx, dy = y, x
+			case 6: // rotated 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transversed
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotated 90 counter-clockwise
+				dx, dy = y, w-1-x
+			}
+
+			dst.SetRGBA(dx, dy, img.RGBAAt(x, y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,172 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Imaging(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	specs := []model.MediaVariantSpec{
+		{Name: "thumb", Width: 50, Height: 50, Crop: true},
+		{Name: "small", Width: 100, Height: 100},
+		{Name: "large", Width: 1000, Height: 1000},
+	}
+
+	t.Run("GenerateVariants", func(t *testing.T) {
+		tests := []struct {
+			title               string
+			data                []byte
+			expectedContentType string
+			expectedSizes       map[string]image.Point
+		}{
+			{
+				"generate variants: landscape png",
+				encodeTestPNG(t, 400, 200),
+				"image/png",
+				map[string]image.Point{
+					"thumb": {50, 50},
+					"small": {100, 50},
+					"large": {400, 200}, // never upscaled
+				},
+			},
+			{
+				"generate variants: portrait jpeg",
+				encodeTestJPEG(t, 200, 400),
+				"image/jpeg",
+				map[string]image.Point{
+					"thumb": {50, 50},
+					"small": {50, 100},
+					"large": {200, 400},
+				},
+			},
+			{
+				"generate variants: rotated jpeg is upright",
+				insertJPEGSegment(encodeTestJPEG(t, 400, 200), 0xe1, append(append([]byte{}, exifHeader...), exifTIFF(6, nil)...)),
+				"image/jpeg",
+				map[string]image.Point{
+					"thumb": {50, 50},
+					"small": {50, 100},
+					"large": {200, 400},
+				},
+			},
+			{
+				"generate variants: source smaller than thumb",
+				encodeTestPNG(t, 20, 10),
+				"image/png",
+				map[string]image.Point{
+					"thumb": {10, 10},
+					"small": {20, 10},
+					"large": {20, 10},
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			variants, err := GenerateVariants(tt.data, specs)
+			assert.NoError(t, err, tt.title)
+			assert.Len(t, variants, len(specs), tt.title)
+
+			for _, v := range variants {
+				assert.Equal(t, tt.expectedContentType, v.ContentType, tt.title)
+				assert.Equal(t, tt.expectedSizes[v.Name], image.Pt(v.Width, v.Height), tt.title+" "+v.Name)
+				assert.Len(t, v.Hash, hashLen, tt.title)
+
+				cfg, _, err := image.DecodeConfig(bytes.NewReader(v.Data))
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expectedSizes[v.Name], image.Pt(cfg.Width, cfg.Height), tt.title+" "+v.Name)
+			}
+		}
+	})
+
+	t.Run("GenerateVariants: hash is stable", func(t *testing.T) {
+		data := encodeTestPNG(t, 64, 64)
+
+		a, err := GenerateVariants(data, specs)
+		assert.NoError(t, err)
+
+		b, err := GenerateVariants(data, specs)
+		assert.NoError(t, err)
+
+		assert.Equal(t, a[0].Hash, b[0].Hash)
+		assert.NotEqual(t, a[0].Hash, a[1].Hash)
+	})
+
+	t.Run("GenerateVariants: invalid image", func(t *testing.T) {
+		_, err := GenerateVariants([]byte("not an image"), specs)
+		assert.Error(t, err)
+	})
+
+	t.Run("GenerateVariants: too many pixels", func(t *testing.T) {
+		// header of a 10000x10000 png is rejected before its pixels are decoded
+		data := encodeTestPNG(t, 1, 1)
+		binary.BigEndian.PutUint32(data[16:20], 10000)
+		binary.BigEndian.PutUint32(data[20:24], 10000)
+		binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+
+		_, err := GenerateVariants(data, specs)
+		assert.ErrorIs(t, err, errTooManyPixels)
+	})
+
+	t.Run("orient", func(t *testing.T) {
+		img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+		img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+
+		// rotated 90 clockwise, left pixel ends up on top
+		actual := orient(img, 6)
+		assert.Equal(t, image.Rect(0, 0, 1, 2), actual.Bounds())
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, actual.RGBAAt(0, 0))
+
+		// rotated 90 counter-clockwise, left pixel ends up at the bottom
+		actual = orient(img, 8)
+		assert.Equal(t, color.RGBA{R: 255, A: 255}, actual.RGBAAt(0, 1))
+
+		assert.Same(t, img, orient(img, 1))
+	})
+}
+
+func encodeTestPNG(t testing.TB, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := png.Encode(&buf, gradient(width, height))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func encodeTestJPEG(t testing.TB, width, height int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, gradient(width, height), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func gradient(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.SetRGBA(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
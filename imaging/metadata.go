@@ -0,0 +1,399 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errInvalidJPEG = errors.New("invalid jpeg data")
+	errInvalidPNG  = errors.New("invalid png data")
+	errInvalidWebP = errors.New("invalid webp data")
+	errInvalidGIF  = errors.New("invalid gif data")
+)
+
+const (
+	jpegMarkerSOI   = 0xd8
+	jpegMarkerSOS   = 0xda
+	jpegMarkerAPP0  = 0xe0
+	jpegMarkerAPP1  = 0xe1
+	jpegMarkerAPP2  = 0xe2
+	jpegMarkerAPP14 = 0xee
+	jpegMarkerAPP15 = 0xef
+	jpegMarkerCOM   = 0xfe
+
+	exifOrientationTag = 0x0112
+
+	webpFlagEXIF = 0x08
+	webpFlagXMP  = 0x04
+
+	gifFlagColorTable   = 0x80
+	gifBlockExtension   = 0x21
+	gifBlockImage       = 0x2c
+	gifBlockTrailer     = 0x3b
+	gifLabelComment     = 0xfe
+	gifLabelApplication = 0xff
+)
+
+var (
+	exifHeader   = []byte("Exif\x00\x00")
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	gifSignature = []byte("GIF8")
+)
+
+// pngMetadataChunks are png chunks carrying metadata rather than pixels or color information
+var pngMetadataChunks = map[string]bool{
+	"eXIf": true,
+	"tEXt": true,
+	"iTXt": true,
+	"zTXt": true,
+	"tIME": true,
+}
+
+// gifApplications are gif application extensions affecting how images are displayed,
+// NETSCAPE2.0 and ANIMEXTS1.0 loop animations and ICCRGBG1012 holds the color profile
+var gifApplications = map[string]bool{
+	"NETSCAPE2.0": true,
+	"ANIMEXTS1.0": true,
+	"ICCRGBG1012": true,
+}
+
+// StripMetadata removes exif, xmp, iptc and comment metadata from jpeg, png, webp and gif data,
+// data of other content types is returned as is
+//
+// Orientation of jpeg is kept in a minimal exif segment so that it is still displayed upright.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg":
+		return stripJPEGMetadata(data)
+	case "image/png":
+		return stripPNGMetadata(data)
+	case "image/webp":
+		return stripWebPMetadata(data)
+	case "image/gif":
+		return stripGIFMetadata(data)
+	default:
+		return data, nil
+	}
+}
+
+// Orientation returns exif orientation (1-8) of jpeg data, 1 when it is unknown
+func Orientation(data []byte) int {
+	orientation := 1
+
+	_, _ = walkJPEGSegments(data, func(marker byte, segment []byte) bool {
+		if marker != jpegMarkerAPP1 || !bytes.HasPrefix(segment, exifHeader) {
+			return true
+		}
+
+		if o := exifOrientation(segment[len(exifHeader):]); o >= 1 && o <= 8 {
+			orientation = o
+		}
+		return false
+	})
+
+	return orientation
+}
+
+func stripJPEGMetadata(data []byte) ([]byte, error) {
+	orientation := Orientation(data)
+
+	out := make([]byte, 0, len(data))
+	out = append(out, 0xff, jpegMarkerSOI)
+
+	if orientation != 1 {
+		out = append(out, orientationSegment(orientation)...)
+	}
+
+	scan, err := walkJPEGSegments(data, func(marker byte, segment []byte) bool {
+		if marker == jpegMarkerSOS {
+			return false
+		}
+
+		if !isJPEGMetadataMarker(marker) {
+			out = binary.BigEndian.AppendUint16(append(out, 0xff, marker), uint16(len(segment)+2))
+			out = append(out, segment...)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// entropy-coded data from start of scan is copied as is
+	return append(out, data[scan:]...), nil
+}
+
+// isJPEGMetadataMarker tells whether segment of marker only carries metadata,
+// APP0 (JFIF), APP2 (ICC profile) and APP14 (Adobe) are kept since they affect decoded colors
+func isJPEGMetadataMarker(marker byte) bool {
+	if marker == jpegMarkerCOM {
+		return true
+	}
+
+	if marker < jpegMarkerAPP0 || marker > jpegMarkerAPP15 {
+		return false
+	}
+
+	return marker != jpegMarkerAPP0 && marker != jpegMarkerAPP2 && marker != jpegMarkerAPP14
+}
+
+// walkJPEGSegments calls fn with marker and payload of each segment until fn returns false,
+// and returns offset of the segment it stopped at
+func walkJPEGSegments(data []byte, fn func(marker byte, segment []byte) bool) (int, error) {
+	if len(data) < 4 || data[0] != 0xff || data[1] != jpegMarkerSOI {
+		return 0, errInvalidJPEG
+	}
+
+	i := 2
+	for {
+		if i+4 > len(data) || data[i] != 0xff {
+			return 0, errInvalidJPEG
+		}
+
+		marker := data[i+1]
+		if marker == 0xff {
+			// fill bytes before a marker
+			i++
+			continue
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		if length < 2 || i+2+length > len(data) {
+			return 0, errInvalidJPEG
+		}
+
+		if !fn(marker, data[i+4:i+2+length]) {
+			return i, nil
+		}
+
+		if marker == jpegMarkerSOS {
+			return 0, errInvalidJPEG
+		}
+
+		i += 2 + length
+	}
+}
+
+// exifOrientation reads orientation tag from the first image file directory of tiff data
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 0
+	}
+
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8 : entry+10]))
+		}
+	}
+
+	return 0
+}
+
+// orientationSegment returns an APP1 segment holding nothing but exif orientation
+func orientationSegment(orientation int) []byte {
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2a, 0x00, 0x00, 0x00, 0x08, // header, first directory at 8
+		0x00, 0x01, // one entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, // orientation, short, count 1
+		0x00, byte(orientation), 0x00, 0x00, // value
+		0x00, 0x00, 0x00, 0x00, // no next directory
+	}
+
+	segment := []byte{0xff, jpegMarkerAPP1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(2+len(exifHeader)+len(tiff)))
+	segment = append(segment, exifHeader...)
+	return append(segment, tiff...)
+}
+
+func stripPNGMetadata(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, errInvalidPNG
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, pngSignature...)
+
+	i := len(pngSignature)
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, errInvalidPNG
+		}
+
+		length := int(binary.BigEndian.Uint32(data[i : i+4]))
+		end := i + 12 + length // length, type, data and crc
+		if length < 0 || end > len(data) {
+			return nil, errInvalidPNG
+		}
+
+		chunkType := string(data[i+4 : i+8])
+		if !pngMetadataChunks[chunkType] {
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+		if chunkType == "IEND" {
+			break
+		}
+	}
+
+	return out, nil
+}
+
+func stripWebPMetadata(data []byte) ([]byte, error) {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return nil, errInvalidWebP
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:12]...)
+
+	i := 12
+	for i < len(data) {
+		if i+8 > len(data) {
+			return nil, errInvalidWebP
+		}
+
+		length := int(binary.LittleEndian.Uint32(data[i+4 : i+8]))
+		end := i + 8 + length + length%2 // fourcc, size, payload and padding to even size
+		if length < 0 || end > len(data) {
+			return nil, errInvalidWebP
+		}
+
+		switch string(data[i : i+4]) {
+		case "EXIF", "XMP ":
+		case "VP8X":
+			if length < 1 {
+				return nil, errInvalidWebP
+			}
+
+			start := len(out)
+			out = append(out, data[i:end]...)
+			out[start+8] &^= webpFlagEXIF | webpFlagXMP
+		default:
+			out = append(out, data[i:end]...)
+		}
+
+		i = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out, nil
+}
+
+func stripGIFMetadata(data []byte) ([]byte, error) {
+	const headerLen = 13 // signature, version and logical screen descriptor
+
+	if len(data) < headerLen || !bytes.HasPrefix(data, gifSignature) {
+		return nil, errInvalidGIF
+	}
+
+	i := headerLen + gifColorTableLen(data[10])
+	if i > len(data) {
+		return nil, errInvalidGIF
+	}
+
+	out := make([]byte, 0, len(data))
+	out = append(out, data[:i]...)
+
+	for {
+		if i >= len(data) {
+			return nil, errInvalidGIF
+		}
+
+		switch data[i] {
+		case gifBlockTrailer:
+			return append(out, gifBlockTrailer), nil
+		case gifBlockExtension:
+			if i+2 > len(data) {
+				return nil, errInvalidGIF
+			}
+
+			end, err := skipGIFSubBlocks(data, i+2)
+			if err != nil {
+				return nil, err
+			}
+
+			if !isGIFMetadataExtension(data[i+1], data[i+2:end]) {
+				out = append(out, data[i:end]...)
+			}
+			i = end
+		case gifBlockImage:
+			// image descriptor, local color table and lzw minimum code size
+			start := i + 10
+			if start > len(data) {
+				return nil, errInvalidGIF
+			}
+			start += gifColorTableLen(data[i+9]) + 1
+
+			end, err := skipGIFSubBlocks(data, start)
+			if err != nil {
+				return nil, err
+			}
+
+			out = append(out, data[i:end]...)
+			i = end
+		default:
+			return nil, errInvalidGIF
+		}
+	}
+}
+
+// isGIFMetadataExtension tells whether extension of label only carries metadata,
+// blocks are data sub-blocks of the extension
+func isGIFMetadataExtension(label byte, blocks []byte) bool {
+	switch label {
+	case gifLabelComment:
+		return true
+	case gifLabelApplication:
+		return len(blocks) < 12 || blocks[0] != 11 || !gifApplications[string(blocks[1:12])]
+	default:
+		return false
+	}
+}
+
+// gifColorTableLen returns length of color table described by packed fields of
+// logical screen descriptor or image descriptor
+func gifColorTableLen(flags byte) int {
+	if flags&gifFlagColorTable == 0 {
+		return 0
+	}
+	return 3 << ((flags & 0x07) + 1)
+}
+
+// skipGIFSubBlocks returns offset right after the data sub-blocks starting at i
+func skipGIFSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, errInvalidGIF
+		}
+
+		size := int(data[i])
+		i += 1 + size
+		if size == 0 {
+			return i, nil
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/webp"
+)
+
+func TestUnit_Metadata(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	secret := []byte("GPS 13.7563 N, 100.5018 E")
+
+	t.Run("StripMetadata", func(t *testing.T) {
+		t.Run("jpeg", func(t *testing.T) {
+			src := encodeTestJPEG(t, 8, 4)
+			withMeta := insertJPEGSegment(src, 0xe1, append(append([]byte{}, exifHeader...), exifTIFF(6, secret)...))
+			withMeta = insertJPEGSegment(withMeta, 0xfe, secret)
+
+			assert.Equal(t, 6, Orientation(withMeta))
+
+			actual, err := StripMetadata("image/jpeg", withMeta)
+			assert.NoError(t, err)
+			assert.False(t, bytes.Contains(actual, secret))
+			assert.Equal(t, 6, Orientation(actual))
+
+			img, err := jpeg.Decode(bytes.NewReader(actual))
+			assert.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 8, 4), img.Bounds())
+		})
+
+		t.Run("jpeg without orientation", func(t *testing.T) {
+			src := encodeTestJPEG(t, 8, 4)
+			withMeta := insertJPEGSegment(src, 0xfe, secret)
+
+			actual, err := StripMetadata("image/jpeg", withMeta)
+			assert.NoError(t, err)
+			assert.Equal(t, src, actual)
+			assert.Equal(t, 1, Orientation(actual))
+		})
+
+		t.Run("png", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			src := buf.Bytes()
+			withMeta := insertPNGChunk(src, "tEXt", append([]byte("Comment\x00"), secret...))
+
+			actual, err := StripMetadata("image/png", withMeta)
+			assert.NoError(t, err)
+			assert.Equal(t, src, actual)
+		})
+
+		t.Run("webp", func(t *testing.T) {
+			src := encodeTestWebP(nil)
+			withMeta := encodeTestWebP(map[string][]byte{
+				"EXIF": exifTIFF(6, secret),
+				"XMP ": append([]byte("<x:xmpmeta>"), secret...),
+			})
+
+			actual, err := StripMetadata("image/webp", withMeta)
+			assert.NoError(t, err)
+			assert.False(t, bytes.Contains(actual, secret))
+			assert.Equal(t, src, actual)
+
+			img, err := webp.Decode(bytes.NewReader(actual))
+			assert.NoError(t, err)
+			assert.Equal(t, image.Rect(0, 0, 1, 1), img.Bounds())
+		})
+
+		t.Run("gif", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := gif.EncodeAll(&buf, &gif.GIF{
+				Image: []*image.Paletted{
+					image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.Black, color.White}),
+					image.NewPaletted(image.Rect(0, 0, 4, 4), color.Palette{color.White, color.Black}),
+				},
+				Delay: []int{10, 10},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			src := buf.Bytes()
+			withMeta := insertGIFExtension(src, 0xfe, secret)
+			withMeta = insertGIFExtension(withMeta, 0xff, []byte("XMP DataXMP"), secret)
+
+			actual, err := StripMetadata("image/gif", withMeta)
+			assert.NoError(t, err)
+			assert.False(t, bytes.Contains(actual, secret))
+			assert.Equal(t, src, actual)
+
+			// looping animation is kept
+			img, err := gif.DecodeAll(bytes.NewReader(actual))
+			assert.NoError(t, err)
+			assert.Len(t, img.Image, 2)
+		})
+
+		t.Run("other content types", func(t *testing.T) {
+			data := []byte("%PDF-1.4")
+
+			actual, err := StripMetadata("application/pdf", data)
+			assert.NoError(t, err)
+			assert.Equal(t, data, actual)
+		})
+
+		t.Run("invalid data", func(t *testing.T) {
+			_, err := StripMetadata("image/jpeg", []byte("not a jpeg"))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/png", []byte("not a png"))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/png", append(append([]byte{}, pngSignature...), 0, 0, 1))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/webp", []byte("not a webp"))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/webp", append([]byte("RIFF\x00\x00\x00\x00WEBPVP8L"), 0xff, 0, 0, 0))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/gif", []byte("not a gif"))
+			assert.Error(t, err)
+
+			_, err = StripMetadata("image/gif", []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00\x21\xfe\x05"))
+			assert.Error(t, err)
+		})
+	})
+}
+
+// exifTIFF returns little-endian tiff data with orientation and a description
+func exifTIFF(orientation int, description []byte) []byte {
+	tiff := []byte{'I', 'I', 0x2a, 0x00, 0x08, 0x00, 0x00, 0x00}
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+
+	// image description, ascii, stored after the directory
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x010e)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 2)
+	tiff = binary.LittleEndian.AppendUint32(tiff, uint32(len(description)))
+	tiff = binary.LittleEndian.AppendUint32(tiff, 8+2+2*12+4)
+
+	tiff = binary.LittleEndian.AppendUint16(tiff, exifOrientationTag)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0x00, 0x00)
+
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00)
+	return append(tiff, description...)
+}
+
+// insertJPEGSegment inserts a segment right after start of image
+func insertJPEGSegment(data []byte, marker byte, payload []byte) []byte {
+	out := []byte{0xff, jpegMarkerSOI, 0xff, marker}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(payload)+2))
+	out = append(out, payload...)
+	return append(out, data[2:]...)
+}
+
+// insertPNGChunk inserts a chunk right after header chunk, crc is not checked when stripping
+func insertPNGChunk(data []byte, chunkType string, payload []byte) []byte {
+	ihdrEnd := len(pngSignature) + 12 + 13
+
+	out := append([]byte{}, data[:ihdrEnd]...)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(payload)))
+	out = append(out, chunkType...)
+	out = append(out, payload...)
+	out = append(out, 0x00, 0x00, 0x00, 0x00)
+	return append(out, data[ihdrEnd:]...)
+}
+
+// encodeTestWebP returns an extended webp holding a 1x1 lossless image and chunks of metadata
+func encodeTestWebP(metadata map[string][]byte) []byte {
+	lossless, _ := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+
+	var flags byte
+	if metadata["EXIF"] != nil {
+		flags |= webpFlagEXIF
+	}
+	if metadata["XMP "] != nil {
+		flags |= webpFlagXMP
+	}
+
+	out := []byte("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00")
+	out = append(out, flags, 0, 0, 0, 0, 0, 0, 0, 0, 0) // canvas of 1x1
+	out = append(out, lossless[12:]...)
+
+	for _, fourCC := range []string{"EXIF", "XMP "} {
+		payload, ok := metadata[fourCC]
+		if !ok {
+			continue
+		}
+
+		out = append(out, fourCC...)
+		out = binary.LittleEndian.AppendUint32(out, uint32(len(payload)))
+		out = append(out, payload...)
+		if len(payload)%2 == 1 {
+			out = append(out, 0x00)
+		}
+	}
+
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-8))
+	return out
+}
+
+// insertGIFExtension inserts an extension of sub-blocks right after the global color table
+func insertGIFExtension(data []byte, label byte, blocks ...[]byte) []byte {
+	start := 13 + gifColorTableLen(data[10])
+
+	out := append([]byte{}, data[:start]...)
+	out = append(out, gifBlockExtension, label)
+	for _, block := range blocks {
+		out = append(out, byte(len(block)))
+		out = append(out, block...)
+	}
+	out = append(out, 0x00)
+	return append(out, data[start:]...)
+}
@@ -0,0 +1,146 @@
+package imaging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/storage"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultQueueSize is the number of media waiting to be processed before new ones are deferred
+	DefaultQueueSize = 100
+	// DefaultWorkers is the number of media processed concurrently
+	DefaultWorkers = 2
+)
+
+// Processor generates variants of image media in the background
+//
+// Media which do not fit in queue, or which are left over when server stops,
+// stay unprocessed in database and are picked up again on the next run.
+type Processor struct {
+	logger *zerolog.Logger
+	ms     *store.MediaStore
+	st     storage.Storage
+	queue  chan uint
+}
+
+// NewProcessor returns a new processor with logger, media store and storage
+func NewProcessor(l *zerolog.Logger, ms *store.MediaStore, st storage.Storage, queueSize int) *Processor {
+	return &Processor{
+		logger: l,
+		ms:     ms,
+		st:     st,
+		queue:  make(chan uint, queueSize),
+	}
+}
+
+// Enqueue queues a media (by id) for processing without blocking,
+// and returns whether it is queued
+func (p *Processor) Enqueue(id uint) bool {
+	select {
+	case p.queue <- id:
+		return true
+	default:
+		p.logger.Warn().Uint("media_id", id).Msg("media processing queue is full, deferring media")
+		return false
+	}
+}
+
+// Run queues unprocessed media left over from previous runs and processes
+// queued media with workers until ctx is done
+func (p *Processor) Run(ctx context.Context, workers int) {
+	p.logger.Info().Int("workers", workers).Msg("starting media processor...")
+
+	for i := 0; i < workers; i++ {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case id := <-p.queue:
+					err := p.Process(ctx, id)
+					if err != nil {
+						p.logger.Error().Err(err).Uint("media_id", id).Msg("failed to process media")
+					}
+				}
+			}
+		}()
+	}
+
+	ids, err := p.ms.GetUnprocessedImageIDs(ctx, int64(cap(p.queue)))
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to get unprocessed media")
+		return
+	}
+
+	for _, id := range ids {
+		select {
+		case <-ctx.Done():
+			return
+		case p.queue <- id:
+		}
+	}
+}
+
+// Process generates, stores and saves variants of a media (by id)
+//
+// Media which cannot be decoded are marked as processed without variants.
+func (p *Processor) Process(ctx context.Context, id uint) error {
+	media, err := p.ms.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !media.IsImage() {
+		return p.ms.SaveVariants(ctx, media, []model.MediaVariant{})
+	}
+
+	r, err := p.st.Get(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	generated, err := GenerateVariants(data, model.MediaVariantSpecs)
+	if err != nil {
+		p.logger.Error().Err(err).Uint("media_id", id).Msg("failed to decode image, skipping variants")
+		return p.ms.SaveVariants(ctx, media, []model.MediaVariant{})
+	}
+
+	variants := make([]model.MediaVariant, 0, len(generated))
+	for _, g := range generated {
+		v := model.NewMediaVariant(media, g.Name, g.ContentType, g.Hash, g.Width, g.Height, int64(len(g.Data)))
+
+		err = p.st.Put(ctx, v.StorageKey, bytes.NewReader(g.Data), v.Size, v.ContentType)
+		if err != nil {
+			return fmt.Errorf("failed to store variant %s :%w", v.Name, err)
+		}
+
+		variants = append(variants, v)
+	}
+
+	err = p.ms.SaveVariants(ctx, media, variants)
+	if err != nil {
+		// do not leave orphan objects behind, e.g. when media is deleted meanwhile
+		for _, v := range variants {
+			if err := p.st.Delete(ctx, v.StorageKey); err != nil {
+				p.logger.Error().Err(err).Msg(fmt.Sprintf("failed to delete orphan variant object (key=%s)", v.StorageKey))
+			}
+		}
+		return err
+	}
+
+	p.logger.Info().Uint("media_id", id).Int("variants", len(variants)).Msg("succeeded to process media")
+	return nil
+}
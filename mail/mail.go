@@ -0,0 +1,154 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/env"
+	"github.com/rs/zerolog"
+)
+
+// Message is an email with a plain text body and an html alternative of it
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers, such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	// Send sends a message from the sender configured in mailer
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns a mailer of driver configured in env
+func New(environ *env.ENV, l *zerolog.Logger) (Mailer, error) {
+	switch environ.MailDriver {
+	case "log":
+		return NewLogMailer(l), nil
+	case "smtp":
+		return NewSMTPMailer(
+			environ.SMTPHost,
+			environ.SMTPPort,
+			environ.SMTPUser,
+			environ.SMTPPass,
+			environ.MailFrom,
+		)
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", environ.MailDriver)
+	}
+}
+
+// LogMailer logs emails instead of sending them, for development
+type LogMailer struct {
+	logger *zerolog.Logger
+}
+
+// NewLogMailer returns a new log mailer with logger
+func NewLogMailer(l *zerolog.Logger) *LogMailer {
+	return &LogMailer{logger: l}
+}
+
+// Send logs recipient, subject and text body of a message
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	m.logger.Info().Str("to", msg.To).Str("subject", msg.Subject).Msg(msg.Text)
+	return nil
+}
+
+// build returns a message from the sender as a multipart/alternative email
+func (msg *Message) build(from *netmail.Address, now time.Time) ([]byte, error) {
+	to, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	}
+
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		_, err = qw.Write([]byte(p.content))
+		if err != nil {
+			return nil, err
+		}
+
+		err = qw.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = mw.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 16)
+	_, err = rand.Read(id)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"From":         from.String(),
+		"To":           to.String(),
+		"Subject":      mime.QEncoding.Encode("utf-8", msg.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"Message-ID":   fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domainOf(from.Address)),
+		"MIME-Version": "1.0",
+		"Content-Type": fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary()),
+	}
+	for k, v := range msg.Headers {
+		headers[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, headers[k])
+	}
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+
+	return b.Bytes(), nil
+}
+
+// domainOf returns domain of an email address
+func domainOf(address string) string {
+	return address[strings.LastIndex(address, "@")+1:]
+}
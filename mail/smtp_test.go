@@ -0,0 +1,175 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sinkMessage is a message received by smtp sink
+type sinkMessage struct {
+	from string
+	to   string
+	data []byte
+}
+
+// newSMTPSink serves a minimal local smtp server keeping messages it receives
+func newSMTPSink(t *testing.T) (string, string, <-chan sinkMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan sinkMessage, 1)
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSMTPSink(conn, messages)
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	return host, port, messages
+}
+
+func serveSMTPSink(conn net.Conn, messages chan<- sinkMessage) {
+	defer conn.Close()
+
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 localhost ESMTP sink")
+
+	var msg sinkMessage
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch cmd {
+		case "EHLO", "HELO":
+			tc.PrintfLine("250-localhost")
+			tc.PrintfLine("250 8BITMIME")
+		case "MAIL":
+			msg.from = pathOf(line)
+			tc.PrintfLine("250 OK")
+		case "RCPT":
+			msg.to = pathOf(line)
+			tc.PrintfLine("250 OK")
+		case "DATA":
+			tc.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			msg.data, err = io.ReadAll(tc.DotReader())
+			if err != nil {
+				return
+			}
+			messages <- msg
+			tc.PrintfLine("250 OK")
+		case "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("250 OK")
+		}
+	}
+}
+
+// pathOf returns the address between angle brackets of MAIL and RCPT commands
+func pathOf(line string) string {
+	start := strings.Index(line, "<")
+	end := strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func TestUnit_SMTPMailer(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	host, port, messages := newSMTPSink(t)
+
+	m, err := NewSMTPMailer(host, port, "", "", "Articles <news@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Send(context.Background(), &Message{
+		To:      "foo_user@example.com",
+		Subject: "Your daily digest",
+		Text:    "1 new article\nhttps://example.com/articles/1",
+		HTML:    `<p>1 new article</p><a href="https://example.com/articles/1">title</a>`,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<https://example.com/unsubscribe>",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	received := <-messages
+	assert.Equal(t, "news@example.com", received.from)
+	assert.Equal(t, "foo_user@example.com", received.to)
+
+	msg, err := netmail.ReadMessage(bufio.NewReader(strings.NewReader(string(received.data))))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, `"Articles" <news@example.com>`, msg.Header.Get("From"))
+	assert.Equal(t, "<foo_user@example.com>", msg.Header.Get("To"))
+	assert.Equal(t, "Your daily digest", msg.Header.Get("Subject"))
+	assert.Equal(t, "<https://example.com/unsubscribe>", msg.Header.Get("List-Unsubscribe"))
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Header.Get("List-Unsubscribe-Post"))
+	assert.True(t, strings.HasSuffix(msg.Header.Get("Message-Id"), "@example.com>"))
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		b, err := io.ReadAll(part)
+		assert.NoError(t, err)
+
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(b)
+	}
+
+	assert.Equal(t, map[string]string{
+		"text/plain": "1 new article\nhttps://example.com/articles/1",
+		"text/html":  `<p>1 new article</p><a href="https://example.com/articles/1">title</a>`,
+	}, bodies)
+
+	// invalid recipients are not sent
+	err = m.Send(context.Background(), &Message{To: "not an address"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,210 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+const (
+	// DefaultCacheSize is the number of rendered revisions kept in memory
+	DefaultCacheSize = 1000
+
+	wordsPerMinute = 200
+)
+
+// Renderer renders Markdown (CommonMark with GFM tables and task lists) to sanitised HTML
+//
+// Rendered bodies are cached per article revision, which is identified by
+// article id and its last update time.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu        sync.Mutex
+	cacheSize int
+	cache     map[string]*list.Element
+	recent    *list.List
+}
+
+type cacheEntry struct {
+	key  string
+	body *model.RenderedBody
+}
+
+// NewRenderer returns a new renderer keeping up to cacheSize rendered revisions
+func NewRenderer(cacheSize int) *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.Table, extension.TaskList),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// raw HTML is passed through and removed by the sanitiser instead
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	policy := bluemonday.UGCPolicy()
+	policy.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	policy.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	policy.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return &Renderer{
+		md:        md,
+		policy:    policy,
+		cacheSize: cacheSize,
+		cache:     make(map[string]*list.Element),
+		recent:    list.New(),
+	}
+}
+
+// Render renders a Markdown source to sanitised HTML with its table of contents and reading time
+func (r *Renderer) Render(source string) (*model.RenderedBody, error) {
+	src := []byte(source)
+	doc := r.md.Parser().Parse(text.NewReader(src))
+
+	var buf bytes.Buffer
+	err := r.md.Renderer().Render(&buf, src, doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %w", err)
+	}
+
+	toc := []model.Heading{}
+	err = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !entering || !ok {
+			return ast.WalkContinue, nil
+		}
+
+		var id string
+		if value, exists := heading.AttributeString("id"); exists {
+			if b, ok := value.([]byte); ok {
+				id = string(b)
+			}
+		}
+
+		toc = append(toc, model.Heading{
+			Level: heading.Level,
+			ID:    id,
+			Text:  nodeText(heading, src),
+		})
+
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract table of contents: %w", err)
+	}
+
+	return &model.RenderedBody{
+		HTML:               r.policy.Sanitize(buf.String()),
+		TOC:                toc,
+		ReadingTimeMinutes: readingTime(source),
+	}, nil
+}
+
+// RenderArticle renders body of article into its Rendered field, reusing a cached revision if any
+func (r *Renderer) RenderArticle(article *model.Article) error {
+	key := fmt.Sprintf("%d:%d", article.ID, article.UpdatedAt.UnixNano())
+
+	if body, exists := r.get(key); exists {
+		article.Rendered = body
+		return nil
+	}
+
+	body, err := r.Render(article.Body)
+	if err != nil {
+		return err
+	}
+
+	r.put(key, body)
+	article.Rendered = body
+	return nil
+}
+
+func (r *Renderer) get(key string) (*model.RenderedBody, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, exists := r.cache[key]
+	if !exists {
+		return nil, false
+	}
+
+	r.recent.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).body, true
+}
+
+func (r *Renderer) put(key string, body *model.RenderedBody) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.cacheSize <= 0 {
+		return
+	}
+
+	if elem, exists := r.cache[key]; exists {
+		elem.Value.(*cacheEntry).body = body
+		r.recent.MoveToFront(elem)
+		return
+	}
+
+	r.cache[key] = r.recent.PushFront(&cacheEntry{key: key, body: body})
+
+	// evict the least recently used revisions
+	for r.recent.Len() > r.cacheSize {
+		oldest := r.recent.Back()
+		r.recent.Remove(oldest)
+		delete(r.cache, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// nodeText returns plain text of inline children of a node
+func nodeText(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
+		switch t := c.(type) {
+		case *ast.Text:
+			sb.Write(t.Segment.Value(source))
+			if t.SoftLineBreak() || t.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(t.Value)
+		default:
+			sb.WriteString(nodeText(c, source))
+		}
+	}
+	return sb.String()
+}
+
+// readingTime estimates minutes to read a text, which is at least a minute for any text
+func readingTime(source string) int {
+	words := 0
+	for _, field := range strings.Fields(source) {
+		// skip bare Markdown markers, e.g. list bullets and heading marks
+		if strings.ContainsFunc(field, isWordRune) {
+			words++
+		}
+	}
+
+	if words == 0 {
+		return 0
+	}
+
+	return int(math.Ceil(float64(words) / wordsPerMinute))
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
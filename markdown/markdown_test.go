@@ -0,0 +1,121 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Markdown(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("Render", func(t *testing.T) {
+		r := NewRenderer(DefaultCacheSize)
+
+		tests := []struct {
+			title    string
+			source   string
+			contains []string
+			excludes []string
+		}{
+			{
+				"render: commonmark",
+				"# Title\n\nSome *emphasis* and [a link](https://example.com).",
+				[]string{
+					`<h1 id="title">Title</h1>`,
+					"<em>emphasis</em>",
+					`<a href="https://example.com" rel="nofollow">a link</a>`,
+				},
+				nil,
+			},
+			{
+				"render: gfm table",
+				"| a | b |\n| - | - |\n| 1 | 2 |",
+				[]string{"<table>", "<th>a</th>", "<td>2</td>"},
+				nil,
+			},
+			{
+				"render: gfm task list",
+				"- [x] done\n- [ ] todo",
+				[]string{`<input checked="" disabled="" type="checkbox">`, `<input disabled="" type="checkbox">`},
+				nil,
+			},
+			{
+				"render: strip unsafe html",
+				"<script>alert(1)</script>\n\n<a href=\"javascript:alert(1)\" onclick=\"alert(1)\">x</a>",
+				nil,
+				[]string{"<script>", "javascript:", "onclick"},
+			},
+		}
+
+		for _, tt := range tests {
+			actual, err := r.Render(tt.source)
+			assert.NoError(t, err, tt.title)
+
+			for _, s := range tt.contains {
+				assert.Contains(t, actual.HTML, s, tt.title)
+			}
+
+			for _, s := range tt.excludes {
+				assert.NotContains(t, actual.HTML, s, tt.title)
+			}
+		}
+	})
+
+	t.Run("TOC", func(t *testing.T) {
+		r := NewRenderer(DefaultCacheSize)
+
+		actual, err := r.Render("# Intro\n\n## Using `go test`\n\ntext\n\n## Intro")
+		assert.NoError(t, err)
+		assert.Equal(t, []model.Heading{
+			{Level: 1, ID: "intro", Text: "Intro"},
+			{Level: 2, ID: "using-go-test", Text: "Using go test"},
+			{Level: 2, ID: "intro-1", Text: "Intro"},
+		}, actual.TOC)
+	})
+
+	t.Run("ReadingTime", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			source   string
+			expected int
+		}{
+			{"reading time: empty", "", 0},
+			{"reading time: markers only", "# - * >", 0},
+			{"reading time: short text", "- a few words", 1},
+			{"reading time: long text", strings.Repeat("word ", 401), 3},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, readingTime(tt.source), tt.title)
+		}
+	})
+
+	t.Run("RenderArticle", func(t *testing.T) {
+		r := NewRenderer(1)
+		now := time.Now()
+
+		article := model.Article{ID: 1, Body: "first", UpdatedAt: now}
+		err := r.RenderArticle(&article)
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>first</p>\n", article.Rendered.HTML)
+
+		// same revision is served from cache
+		cached := model.Article{ID: 1, Body: "changed", UpdatedAt: now}
+		err = r.RenderArticle(&cached)
+		assert.NoError(t, err)
+		assert.Same(t, article.Rendered, cached.Rendered)
+
+		// new revision is rendered again and evicts the old one
+		updated := model.Article{ID: 1, Body: "changed", UpdatedAt: now.Add(time.Second)}
+		err = r.RenderArticle(&updated)
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>changed</p>\n", updated.Rendered.HTML)
+		assert.Equal(t, 1, r.recent.Len())
+	})
+}
@@ -19,29 +19,50 @@ type UpdateArticleRequest struct {
 
 // CreateCommentRequest definition
 type CreateCommentRequest struct {
+	Body     string `json:"body"`
+	ParentID uint   `json:"parent_id,omitempty"`
+}
+
+// UpdateCommentRequest definition
+type UpdateCommentRequest struct {
 	Body string `json:"body"`
 }
 
 /* Response message */
 
+// HeadingResponse definition
+type HeadingResponse struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
 // ArticleResponse definition
 type ArticleResponse struct {
-	ID             uint            `json:"id"`
-	Title          string          `json:"title"`
-	Description    string          `json:"description"`
-	Body           string          `json:"body"`
-	Tags           []string        `json:"tags"`
-	Favorited      bool            `json:"favorited"`
-	FavoritesCount int64           `json:"favorites_count"`
-	Author         ProfileResponse `json:"author"`
-	CreatedAt      string          `json:"created_at"`
-	UpdatedAt      string          `json:"updated_at"`
+	ID                 uint               `json:"id"`
+	Title              string             `json:"title"`
+	Description        string             `json:"description"`
+	Body               string             `json:"body"`
+	BodyHTML           string             `json:"body_html,omitempty"`
+	TOC                []HeadingResponse  `json:"toc,omitempty"`
+	ReadingTimeMinutes int                `json:"reading_time_minutes,omitempty"`
+	Tags               []string           `json:"tags"`
+	Favorited          bool               `json:"favorited"`
+	FavoritesCount     int64              `json:"favorites_count"`
+	CommentsCount      int64              `json:"comments_count"`
+	Reactions          []ReactionResponse `json:"reactions"`
+	Mentions           []string           `json:"mentions"`
+	Thumbnails         map[string]string  `json:"thumbnails,omitempty"`
+	Author             ProfileResponse    `json:"author"`
+	CreatedAt          string             `json:"created_at"`
+	UpdatedAt          string             `json:"updated_at"`
 }
 
 // ArticlesResponse definition
 type ArticlesResponse struct {
 	Articles      []ArticleResponse `json:"articles"`
 	ArticlesCount int64             `json:"articles_count"`
+	Links         LinksResponse     `json:"links"`
 }
 
 // TagsResponse definition
@@ -49,16 +70,57 @@ type TagsResponse struct {
 	Tags []string `json:"tags"`
 }
 
+// TagResponse definition
+type TagResponse struct {
+	Tag       string `json:"tag"`
+	Following bool   `json:"following"`
+}
+
 // CommentResponse definition
 type CommentResponse struct {
-	ID        uint            `json:"id"`
-	Body      string          `json:"body"`
-	Author    ProfileResponse `json:"author"`
-	CreatedAt string          `json:"created_at"`
-	UpdatedAt string          `json:"updated_at"`
+	ID           uint               `json:"id"`
+	Body         string             `json:"body"`
+	Author       ProfileResponse    `json:"author"`
+	ParentID     *uint              `json:"parent_id"`
+	Depth        int                `json:"depth"`
+	RepliesCount int64              `json:"replies_count"`
+	Deleted      bool               `json:"deleted,omitempty"`
+	Edited       bool               `json:"edited"`
+	Reactions    []ReactionResponse `json:"reactions"`
+	Mentions     []string           `json:"mentions"`
+	EditedAt     *string            `json:"edited_at"`
+	Replies      []CommentResponse  `json:"replies,omitempty"`
+	CreatedAt    string             `json:"created_at"`
+	UpdatedAt    string             `json:"updated_at"`
 }
 
 // CommentsResponse definition
 type CommentsResponse struct {
-	Comments []CommentResponse `json:"comments"`
+	Comments      []CommentResponse `json:"comments"`
+	CommentsCount int64             `json:"comments_count"`
+	Links         LinksResponse     `json:"links"`
+}
+
+// CommentRevisionResponse definition
+type CommentRevisionResponse struct {
+	ID        uint   `json:"id"`
+	Body      string `json:"body"`
+	CreatedAt string `json:"created_at"`
+}
+
+// CommentRevisionsResponse definition
+type CommentRevisionsResponse struct {
+	Revisions []CommentRevisionResponse `json:"revisions"`
+}
+
+// ReactionResponse definition
+type ReactionResponse struct {
+	Reaction string `json:"reaction"`
+	Count    int64  `json:"count"`
+	Reacted  bool   `json:"reacted"`
+}
+
+// ReactionsResponse definition
+type ReactionsResponse struct {
+	Reactions []ReactionResponse `json:"reactions"`
 }
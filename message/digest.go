@@ -0,0 +1,16 @@
+package message
+
+/* Request message */
+
+// UpdateDigestSubscriptionRequest definition
+type UpdateDigestSubscriptionRequest struct {
+	Frequency string `json:"frequency"`
+}
+
+/* Response message */
+
+// DigestSubscriptionResponse definition
+type DigestSubscriptionResponse struct {
+	Frequency    string  `json:"frequency"`
+	NextDigestAt *string `json:"next_digest_at"`
+}
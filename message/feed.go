@@ -0,0 +1,11 @@
+package message
+
+/* Response message */
+
+// FeedTokenResponse definition
+type FeedTokenResponse struct {
+	Token     string `json:"token"`
+	AtomURL   string `json:"atom_url"`
+	RSSURL    string `json:"rss_url"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,25 @@
+package message
+
+/* Request message */
+
+// MediaRequest definition
+type MediaRequest struct {
+	MediaID uint `json:"media_id"`
+}
+
+/* Response message */
+
+// MediaResponse definition
+type MediaResponse struct {
+	ID          uint   `json:"id"`
+	URL         string `json:"url"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	CreatedAt   string `json:"created_at"`
+}
+
+// MediaListResponse definition
+type MediaListResponse struct {
+	Media []MediaResponse `json:"media"`
+}
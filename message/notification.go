@@ -0,0 +1,34 @@
+package message
+
+/* Request message */
+
+// UpdateNotificationPreferencesRequest definition
+type UpdateNotificationPreferencesRequest struct {
+	Preferences map[string]bool `json:"preferences"`
+}
+
+/* Response message */
+
+// NotificationResponse definition
+type NotificationResponse struct {
+	ID        uint            `json:"id"`
+	Type      string          `json:"type"`
+	Actor     ProfileResponse `json:"actor"`
+	ArticleID *uint           `json:"article_id"`
+	CommentID *uint           `json:"comment_id"`
+	Read      bool            `json:"read"`
+	ReadAt    *string         `json:"read_at"`
+	CreatedAt string          `json:"created_at"`
+}
+
+// NotificationsResponse definition
+type NotificationsResponse struct {
+	Notifications      []NotificationResponse `json:"notifications"`
+	NotificationsCount int64                  `json:"notifications_count"`
+	UnreadCount        int64                  `json:"unread_count"`
+}
+
+// NotificationPreferencesResponse definition
+type NotificationPreferencesResponse struct {
+	Preferences map[string]bool `json:"preferences"`
+}
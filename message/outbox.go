@@ -0,0 +1,7 @@
+package message
+
+// DomainEventPayload definition, which is the snapshot kept by a domain event
+type DomainEventPayload struct {
+	Article *ArticleResponse `json:"article,omitempty"`
+	Comment *CommentResponse `json:"comment,omitempty"`
+}
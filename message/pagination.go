@@ -0,0 +1,9 @@
+package message
+
+/* Response message */
+
+// LinksResponse definition
+type LinksResponse struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
@@ -0,0 +1,23 @@
+package message
+
+/* Response message */
+
+// SearchHighlightResponse definition
+type SearchHighlightResponse struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+// SearchArticleResponse definition
+type SearchArticleResponse struct {
+	Article    ArticleResponse         `json:"article"`
+	Rank       float64                 `json:"rank"`
+	Highlights SearchHighlightResponse `json:"highlights"`
+}
+
+// SearchArticlesResponse definition
+type SearchArticlesResponse struct {
+	Articles      []SearchArticleResponse `json:"articles"`
+	ArticlesCount int64                   `json:"articles_count"`
+}
@@ -0,0 +1,9 @@
+package message
+
+/* Response message */
+
+// FavoritesCountResponse definition
+type FavoritesCountResponse struct {
+	ArticleID      uint  `json:"article_id"`
+	FavoritesCount int64 `json:"favorites_count"`
+}
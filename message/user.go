@@ -24,15 +24,34 @@ type UpdateUserRequest struct {
 	Name     string `json:"name"`
 	Bio      string `json:"bio"`
 	Image    string `json:"image"`
+	// Private is left unchanged when omitted
+	Private *bool `json:"private"`
 }
 
 /* Response message */
 
 // ProfileResponse definition
 type ProfileResponse struct {
-	Username  string `json:"username"`
-	Name      string `json:"name"`
-	Bio       string `json:"bio"`
-	Image     string `json:"image"`
-	Following bool   `json:"following"`
+	Username      string            `json:"username"`
+	Name          string            `json:"name"`
+	Bio           string            `json:"bio"`
+	Image         string            `json:"image"`
+	ImageVariants map[string]string `json:"image_variants,omitempty"`
+	Following     bool              `json:"following"`
+	// Private and FollowRequested are only set on a single profile, FollowRequested
+	// tells whether the viewer has a pending request to follow the private user
+	Private         *bool `json:"private,omitempty"`
+	FollowRequested *bool `json:"follow_requested,omitempty"`
+	// FollowedBy tells whether the user follows the viewer, only set on profiles and their lists
+	FollowedBy *bool `json:"followed_by,omitempty"`
+	// Counts are only set on a single profile
+	FollowersCount *int64 `json:"followers_count,omitempty"`
+	FollowingCount *int64 `json:"following_count,omitempty"`
+	ArticlesCount  *int64 `json:"articles_count,omitempty"`
+}
+
+// ProfilesResponse definition
+type ProfilesResponse struct {
+	Profiles      []ProfileResponse `json:"profiles"`
+	ProfilesCount int64             `json:"profiles_count"`
 }
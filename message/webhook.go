@@ -0,0 +1,75 @@
+package message
+
+import "encoding/json"
+
+/* Request message */
+
+// CreateWebhookRequest definition
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	AllArticles bool     `json:"all_articles"`
+}
+
+// UpdateWebhookRequest definition
+type UpdateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+/* Response message */
+
+// WebhookResponse definition
+type WebhookResponse struct {
+	ID          uint     `json:"id"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret,omitempty"`
+	Events      []string `json:"events"`
+	AllArticles bool     `json:"all_articles"`
+	Active      bool     `json:"active"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// WebhooksResponse definition
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryAttemptResponse definition
+type WebhookDeliveryAttemptResponse struct {
+	ResponseCode *int   `json:"response_code"`
+	Error        string `json:"error,omitempty"`
+	DurationMS   int64  `json:"duration_ms"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// WebhookDeliveryResponse definition
+type WebhookDeliveryResponse struct {
+	ID               uint                             `json:"id"`
+	Event            string                           `json:"event"`
+	Status           string                           `json:"status"`
+	Attempts         int                              `json:"attempts"`
+	LastResponseCode *int                             `json:"last_response_code"`
+	NextAttemptAt    *string                          `json:"next_attempt_at"`
+	DeliveredAt      *string                          `json:"delivered_at"`
+	Payload          json.RawMessage                  `json:"payload,omitempty"`
+	AttemptLog       []WebhookDeliveryAttemptResponse `json:"attempt_log,omitempty"`
+	CreatedAt        string                           `json:"created_at"`
+}
+
+// WebhookDeliveriesResponse definition
+type WebhookDeliveriesResponse struct {
+	Deliveries      []WebhookDeliveryResponse `json:"deliveries"`
+	DeliveriesCount int64                     `json:"deliveries_count"`
+}
+
+// WebhookPayload definition, which is the body posted to webhooks
+type WebhookPayload struct {
+	Event     string           `json:"event"`
+	ArticleID uint             `json:"article_id"`
+	Article   *ArticleResponse `json:"article,omitempty"`
+	Comment   *CommentResponse `json:"comment,omitempty"`
+	CreatedAt string           `json:"created_at"`
+}
@@ -24,18 +24,39 @@ type Tag struct {
 	UpdatedAt time.Time
 }
 
+// Heading model is an entry of the table of contents of a rendered body
+type Heading struct {
+	Level int
+	ID    string
+	Text  string
+}
+
+// RenderedBody model is the sanitised HTML of a Markdown article body
+type RenderedBody struct {
+	HTML               string
+	TOC                []Heading
+	ReadingTimeMinutes int
+}
+
 // Article model
 type Article struct {
 	ID             uint
 	Title          string
 	Description    string
 	Body           string
+	Rendered       *RenderedBody
 	Tags           []Tag
 	UserID         uint
 	Author         User
 	FavoritesCount int64
+	CommentsCount  int64
+	Reactions      []Reaction
+	Mentions       []User
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+
+	// Thumbnails maps variant names of the first attached image to their urls
+	Thumbnails map[string]string
 }
 
 // Validate validates fields of article model
@@ -80,6 +101,11 @@ func validateTag(value interface{}) error {
 	return nil
 }
 
+// ResponseTag generates response message from tag
+func (t Tag) ResponseTag(following bool) message.TagResponse {
+	return message.TagResponse{Tag: t.Name, Following: following}
+}
+
 // Overwrite overwrites each field if it's not zero-value
 func (a *Article) Overwrite(title, description, body string) {
 	if title != "" {
@@ -102,6 +128,10 @@ func (a *Article) ResponseArticle(favorited, followingAuthor bool) message.Artic
 		Body:           a.Body,
 		Favorited:      favorited,
 		FavoritesCount: a.FavoritesCount,
+		CommentsCount:  a.CommentsCount,
+		Reactions:      ResponseReactions(a.Reactions),
+		Mentions:       ResponseMentions(a.Mentions),
+		Thumbnails:     a.Thumbnails,
 		Author:         a.Author.ResponseProfile(followingAuthor),
 		CreatedAt:      a.CreatedAt.Format(time.RFC3339Nano),
 		UpdatedAt:      a.UpdatedAt.Format(time.RFC3339Nano),
@@ -113,5 +143,17 @@ func (a *Article) ResponseArticle(favorited, followingAuthor bool) message.Artic
 	}
 
 	resp.Tags = tags
+
+	if a.Rendered != nil {
+		toc := make([]message.HeadingResponse, 0, len(a.Rendered.TOC))
+		for _, h := range a.Rendered.TOC {
+			toc = append(toc, message.HeadingResponse{Level: h.Level, ID: h.ID, Text: h.Text})
+		}
+
+		resp.BodyHTML = a.Rendered.HTML
+		resp.TOC = toc
+		resp.ReadingTimeMinutes = a.Rendered.ReadingTimeMinutes
+	}
+
 	return resp
 }
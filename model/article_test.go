@@ -253,9 +253,13 @@ func TestUnit_ArticleModel(t *testing.T) {
 			},
 			Favorited:      false,
 			FavoritesCount: 10,
-			CreatedAt:      nowString,
-			UpdatedAt:      nowString,
-			Tags:           []string{"tag-1", "tag-2"},
+			Reactions: []message.ReactionResponse{
+				{Reaction: "heart", Count: 2, Reacted: true},
+			},
+			Mentions:  []string{"bar_user"},
+			CreatedAt: nowString,
+			UpdatedAt: nowString,
+			Tags:      []string{"tag-1", "tag-2"},
 		}
 
 		article := Article{
@@ -276,6 +280,8 @@ func TestUnit_ArticleModel(t *testing.T) {
 				UpdatedAt: now,
 			},
 			FavoritesCount: 10,
+			Reactions:      []Reaction{{Name: "heart", Count: 2, Reacted: true}},
+			Mentions:       []User{{ID: 2, Username: "bar_user"}},
 			Tags:           []Tag{{Name: "tag-1"}, {Name: "tag-2"}},
 			CreatedAt:      now,
 			UpdatedAt:      now,
@@ -283,5 +289,25 @@ func TestUnit_ArticleModel(t *testing.T) {
 
 		actual := article.ResponseArticle(false, false)
 		assert.Equal(t, expected, actual)
+
+		article.Rendered = &RenderedBody{
+			HTML:               "<h1 id=\"intro\">Intro</h1>\n",
+			TOC:                []Heading{{Level: 1, ID: "intro", Text: "Intro"}},
+			ReadingTimeMinutes: 1,
+		}
+
+		expected.BodyHTML = "<h1 id=\"intro\">Intro</h1>\n"
+		expected.TOC = []message.HeadingResponse{{Level: 1, ID: "intro", Text: "Intro"}}
+		expected.ReadingTimeMinutes = 1
+
+		actual = article.ResponseArticle(false, false)
+		assert.Equal(t, expected, actual, "response article: with rendered body")
+	})
+
+	t.Run("ResponseTag", func(t *testing.T) {
+		tag := Tag{ID: 1, Name: "golang"}
+
+		assert.Equal(t, message.TagResponse{Tag: "golang", Following: true}, tag.ResponseTag(true))
+		assert.Equal(t, message.TagResponse{Tag: "golang", Following: false}, tag.ResponseTag(false))
 	})
 }
@@ -1,21 +1,75 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation"
 	"github.com/nathanbizkit/article-management-go/message"
 )
 
+// DeletedCommentBody is shown in place of a deleted comment which still has replies
+const DeletedCommentBody = "[deleted]"
+
+// Comment thread formats
+const (
+	CommentFormatFlat = "flat"
+	CommentFormatTree = "tree"
+)
+
+// Comment thread sorts, top threads are those with the most replies
+const (
+	CommentSortNewest = "newest"
+	CommentSortOldest = "oldest"
+	CommentSortTop    = "top"
+)
+
+var commentSorts = []string{
+	CommentSortNewest,
+	CommentSortOldest,
+	CommentSortTop,
+}
+
+func isCommentSort(sort string) bool {
+	return slices.Contains(commentSorts, sort)
+}
+
+// isTimeCommentSort tells whether comment threads of sort are ordered by a timestamp
+func isTimeCommentSort(sort string) bool {
+	return sort == CommentSortNewest || sort == CommentSortOldest
+}
+
 // Comment model
+//
+// Depth of a top-level comment is 0, and of a reply is depth of its parent plus 1.
 type Comment struct {
+	ID           uint
+	Body         string
+	UserID       uint
+	Author       User
+	ArticleID    uint
+	ParentID     *uint
+	Depth        int
+	RepliesCount int64
+	Reactions    []Reaction
+	Mentions     []User
+	Replies      []Comment
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	EditedAt     *time.Time
+	DeletedAt    *time.Time
+}
+
+// CommentRevision model keeps a prior body of an edited comment
+type CommentRevision struct {
 	ID        uint
+	CommentID uint
 	Body      string
-	UserID    uint
-	Author    User
-	ArticleID uint
 	CreatedAt time.Time
-	UpdatedAt time.Time
 }
 
 // Validate validates fields of comment model
@@ -36,13 +90,166 @@ func (c Comment) Validate() error {
 	)
 }
 
+// ValidateReply validates that comment can reply to parent within max depth
+func (c Comment) ValidateReply(parent *Comment, maxDepth int) error {
+	var err error
+	switch {
+	case parent.IsDeleted():
+		err = errors.New("cannot reply to a deleted comment")
+	case parent.ArticleID != c.ArticleID:
+		err = errors.New("must be a comment of the same article")
+	case parent.Depth+1 > maxDepth:
+		err = fmt.Errorf("replies cannot be nested deeper than %d levels", maxDepth)
+	}
+
+	if err != nil {
+		return validation.Errors{"ParentID": err}
+	}
+	return nil
+}
+
+// ValidateCommentFormat validates format of comment threads
+func ValidateCommentFormat(format string) error {
+	return validation.Errors{
+		"format": validation.Validate(
+			format,
+			validation.In(CommentFormatFlat, CommentFormatTree).
+				Error(fmt.Sprintf("must be one of %s, %s", CommentFormatFlat, CommentFormatTree)),
+		),
+	}.Filter()
+}
+
+// ValidateCommentSort validates sort of comment threads
+func ValidateCommentSort(sort string) error {
+	return validation.Errors{
+		"sort": validation.Validate(
+			sort,
+			validation.By(func(value interface{}) error {
+				if !isCommentSort(sort) {
+					return errors.New("must be one of " + strings.Join(commentSorts, ", "))
+				}
+				return nil
+			}),
+		),
+	}.Filter()
+}
+
+// IsDeleted returns whether comment is deleted and only kept as a placeholder for its replies
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// IsEdited returns whether comment body has been edited
+func (c *Comment) IsEdited() bool {
+	return c.EditedAt != nil
+}
+
+// IsEditable returns whether comment can still be edited at the time within edit window
+func (c *Comment) IsEditable(window time.Duration, now time.Time) bool {
+	return !c.IsDeleted() && now.Before(c.CreatedAt.Add(window))
+}
+
+// NestComments arranges comments into threads, top-level comments keep their order
+// and replies are ordered oldest first, comments whose parent is missing are top-level
+func NestComments(comments []Comment) []Comment {
+	byID := make(map[uint]bool, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = true
+	}
+
+	children := make(map[uint][]Comment)
+	roots := []Comment{}
+	for _, c := range comments {
+		if c.ParentID != nil && byID[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var attach func(c Comment) Comment
+	attach = func(c Comment) Comment {
+		replies := children[c.ID]
+		sort.SliceStable(replies, func(i, j int) bool {
+			return replies[i].CreatedAt.Before(replies[j].CreatedAt) ||
+				(replies[i].CreatedAt.Equal(replies[j].CreatedAt) && replies[i].ID < replies[j].ID)
+		})
+
+		c.Replies = make([]Comment, 0, len(replies))
+		for _, r := range replies {
+			c.Replies = append(c.Replies, attach(r))
+		}
+		return c
+	}
+
+	threads := make([]Comment, 0, len(roots))
+	for _, r := range roots {
+		threads = append(threads, attach(r))
+	}
+
+	return threads
+}
+
+// FlattenComments lists comments of threads depth-first, each followed by its replies
+func FlattenComments(threads []Comment) []Comment {
+	comments := []Comment{}
+	for _, c := range threads {
+		replies := c.Replies
+		c.Replies = nil
+
+		comments = append(comments, c)
+		comments = append(comments, FlattenComments(replies)...)
+	}
+	return comments
+}
+
 // ResponseComment generates response message for comment
 func (c *Comment) ResponseComment(followingAuthor bool) message.CommentResponse {
-	return message.CommentResponse{
-		ID:        c.ID,
-		Body:      c.Body,
-		Author:    c.Author.ResponseProfile(followingAuthor),
-		CreatedAt: c.CreatedAt.Format(time.RFC3339Nano),
-		UpdatedAt: c.UpdatedAt.Format(time.RFC3339Nano),
+	resp := message.CommentResponse{
+		ID:           c.ID,
+		Body:         c.Body,
+		Author:       c.Author.ResponseProfile(followingAuthor),
+		ParentID:     c.ParentID,
+		Depth:        c.Depth,
+		RepliesCount: c.RepliesCount,
+		Reactions:    ResponseReactions(c.Reactions),
+		Mentions:     ResponseMentions(c.Mentions),
+		CreatedAt:    c.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt:    c.UpdatedAt.Format(time.RFC3339Nano),
+	}
+
+	if c.IsEdited() {
+		editedAt := c.EditedAt.Format(time.RFC3339Nano)
+		resp.Edited = true
+		resp.EditedAt = &editedAt
+	}
+
+	if c.IsDeleted() {
+		resp.Body = DeletedCommentBody
+		resp.Author = message.ProfileResponse{}
+		resp.Deleted = true
+	}
+
+	return resp
+}
+
+// ResponseCommentThread generates response message for comment with its replies nested
+func (c *Comment) ResponseCommentThread(following map[uint]bool) message.CommentResponse {
+	resp := c.ResponseComment(following[c.Author.ID])
+
+	resp.Replies = make([]message.CommentResponse, 0, len(c.Replies))
+	for _, r := range c.Replies {
+		resp.Replies = append(resp.Replies, r.ResponseCommentThread(following))
+	}
+
+	return resp
+}
+
+// ResponseCommentRevision generates response message for comment revision
+func (r *CommentRevision) ResponseCommentRevision() message.CommentRevisionResponse {
+	return message.CommentRevisionResponse{
+		ID:        r.ID,
+		Body:      r.Body,
+		CreatedAt: r.CreatedAt.Format(time.RFC3339Nano),
 	}
 }
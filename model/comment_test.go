@@ -82,6 +82,10 @@ func TestUnit_CommentModel(t *testing.T) {
 				Image:     "https://imgur.com/image.jpeg",
 				Following: false,
 			},
+			Reactions: []message.ReactionResponse{
+				{Reaction: "thumbs_up", Count: 1, Reacted: false},
+			},
+			Mentions:  []string{},
 			CreatedAt: nowString,
 			UpdatedAt: nowString,
 		}
@@ -102,6 +106,7 @@ func TestUnit_CommentModel(t *testing.T) {
 				UpdatedAt: now,
 			},
 			ArticleID: 1,
+			Reactions: []Reaction{{Name: "thumbs_up", Count: 1}},
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
@@ -109,4 +114,161 @@ func TestUnit_CommentModel(t *testing.T) {
 		actual := comment.ResponseComment(false)
 		assert.Equal(t, expected, actual)
 	})
+
+	t.Run("ValidateReply", func(t *testing.T) {
+		deletedAt := time.Now()
+
+		tests := []struct {
+			title    string
+			parent   *Comment
+			hasError bool
+		}{
+			{
+				"validate reply: success",
+				&Comment{ID: 1, ArticleID: 1, Depth: 1},
+				false,
+			},
+			{
+				"validate reply: deleted parent",
+				&Comment{ID: 1, ArticleID: 1, DeletedAt: &deletedAt},
+				true,
+			},
+			{
+				"validate reply: parent from another article",
+				&Comment{ID: 1, ArticleID: 2},
+				true,
+			},
+			{
+				"validate reply: too deep",
+				&Comment{ID: 1, ArticleID: 1, Depth: 2},
+				true,
+			},
+		}
+
+		reply := Comment{Body: "A reply.", ArticleID: 1, UserID: 1}
+		for _, tt := range tests {
+			err := reply.ValidateReply(tt.parent, 2)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("ValidateCommentFormat", func(t *testing.T) {
+		assert.NoError(t, ValidateCommentFormat(CommentFormatFlat))
+		assert.NoError(t, ValidateCommentFormat(CommentFormatTree))
+		assert.EqualError(t, ValidateCommentFormat("nested"), "format: must be one of flat, tree.")
+	})
+
+	t.Run("ValidateCommentSort", func(t *testing.T) {
+		assert.NoError(t, ValidateCommentSort(CommentSortNewest))
+		assert.NoError(t, ValidateCommentSort(CommentSortOldest))
+		assert.NoError(t, ValidateCommentSort(CommentSortTop))
+		assert.EqualError(t, ValidateCommentSort("best"), "sort: must be one of newest, oldest, top.")
+	})
+
+	t.Run("NestComments", func(t *testing.T) {
+		now := time.Now()
+		id := func(v uint) *uint { return &v }
+
+		// ordered newest first like comments from store
+		comments := []Comment{
+			{ID: 5, ParentID: id(2), Depth: 2, CreatedAt: now.Add(5 * time.Second)},
+			{ID: 4, ParentID: id(1), Depth: 1, CreatedAt: now.Add(4 * time.Second)},
+			{ID: 3, CreatedAt: now.Add(3 * time.Second)},
+			{ID: 2, ParentID: id(1), Depth: 1, CreatedAt: now.Add(2 * time.Second)},
+			{ID: 1, CreatedAt: now.Add(1 * time.Second)},
+			{ID: 6, ParentID: id(99), Depth: 1, CreatedAt: now},
+		}
+
+		threads := NestComments(comments)
+
+		ids := func(cs []Comment) []uint {
+			out := []uint{}
+			for _, c := range cs {
+				out = append(out, c.ID)
+			}
+			return out
+		}
+
+		assert.Equal(t, []uint{3, 1, 6}, ids(threads))
+		assert.Empty(t, threads[0].Replies)
+		assert.Equal(t, []uint{2, 4}, ids(threads[1].Replies))
+		assert.Equal(t, []uint{5}, ids(threads[1].Replies[0].Replies))
+
+		flat := FlattenComments(threads)
+		assert.Equal(t, []uint{3, 1, 2, 5, 4, 6}, ids(flat))
+		for _, c := range flat {
+			assert.Nil(t, c.Replies)
+		}
+	})
+
+	t.Run("ResponseComment: deleted", func(t *testing.T) {
+		now := time.Now()
+		parentID := uint(1)
+
+		comment := Comment{
+			ID:           2,
+			Body:         "",
+			UserID:       1,
+			Author:       User{ID: 1, Username: "foo_user"},
+			ArticleID:    1,
+			ParentID:     &parentID,
+			Depth:        1,
+			RepliesCount: 3,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			DeletedAt:    &now,
+		}
+
+		actual := comment.ResponseComment(true)
+		assert.Equal(t, DeletedCommentBody, actual.Body)
+		assert.Equal(t, message.ProfileResponse{}, actual.Author)
+		assert.True(t, actual.Deleted)
+		assert.Equal(t, &parentID, actual.ParentID)
+		assert.Equal(t, 1, actual.Depth)
+		assert.Equal(t, int64(3), actual.RepliesCount)
+	})
+
+	t.Run("IsEditable", func(t *testing.T) {
+		now := time.Now()
+
+		comment := Comment{ID: 1, CreatedAt: now.Add(-10 * time.Minute)}
+		assert.True(t, comment.IsEditable(15*time.Minute, now))
+		assert.False(t, comment.IsEditable(5*time.Minute, now))
+
+		comment.DeletedAt = &now
+		assert.False(t, comment.IsEditable(15*time.Minute, now))
+	})
+
+	t.Run("ResponseComment: edited", func(t *testing.T) {
+		now := time.Now()
+		editedAt := now.Add(time.Minute)
+
+		comment := Comment{ID: 1, Body: "Edited.", CreatedAt: now, UpdatedAt: editedAt}
+		actual := comment.ResponseComment(false)
+		assert.False(t, actual.Edited)
+		assert.Nil(t, actual.EditedAt)
+
+		comment.EditedAt = &editedAt
+		actual = comment.ResponseComment(false)
+		assert.True(t, actual.Edited)
+		assert.Equal(t, editedAt.Format(time.RFC3339Nano), *actual.EditedAt)
+	})
+
+	t.Run("ResponseCommentRevision", func(t *testing.T) {
+		now := time.Now()
+
+		revision := CommentRevision{ID: 1, CommentID: 2, Body: "Original.", CreatedAt: now}
+
+		expected := message.CommentRevisionResponse{
+			ID:        1,
+			Body:      "Original.",
+			CreatedAt: now.Format(time.RFC3339Nano),
+		}
+		assert.Equal(t, expected, revision.ResponseCommentRevision())
+	})
 }
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// Digest frequencies
+const (
+	DigestFrequencyOff    = "off"
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+// DigestSubscription model tells how often a user gets an email digest of new articles
+// of followed authors, users without one get none
+//
+// LastSentAt is the end of the period covered by the last digest, or the time of
+// subscribing, so that the next digest covers articles created after it.
+type DigestSubscription struct {
+	UserID           uint
+	Frequency        string
+	UnsubscribeToken string
+	LastSentAt       time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// NewDigestSubscription returns a new digest subscription of the user with a random unsubscribe token
+func NewDigestSubscription(userID uint, frequency string) (DigestSubscription, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return DigestSubscription{}, err
+	}
+
+	return DigestSubscription{
+		UserID:           userID,
+		Frequency:        frequency,
+		UnsubscribeToken: hex.EncodeToString(b),
+	}, nil
+}
+
+// Validate validates fields of digest subscription model
+func (s DigestSubscription) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(
+			&s.UserID,
+			validation.Required,
+		),
+		validation.Field(
+			&s.Frequency,
+			validation.Required,
+			validation.In(DigestFrequencyOff, DigestFrequencyDaily, DigestFrequencyWeekly),
+		),
+	)
+}
+
+// Period returns how long a digest covers, or zero when it is off
+func (s DigestSubscription) Period() time.Duration {
+	switch s.Frequency {
+	case DigestFrequencyDaily:
+		return 24 * time.Hour
+	case DigestFrequencyWeekly:
+		return 7 * 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// IsActive returns whether the user gets digests
+func (s DigestSubscription) IsActive() bool {
+	return s.Period() != 0
+}
+
+// ResponseDigestSubscription generates response message for digest subscription
+func (s *DigestSubscription) ResponseDigestSubscription() message.DigestSubscriptionResponse {
+	resp := message.DigestSubscriptionResponse{Frequency: s.Frequency}
+
+	if s.IsActive() {
+		nextDigestAt := s.LastSentAt.Add(s.Period()).Format(time.RFC3339Nano)
+		resp.NextDigestAt = &nextDigestAt
+	}
+
+	return resp
+}
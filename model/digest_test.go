@@ -0,0 +1,108 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_DigestSubscriptionModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	newDigestSubscription := func(userID uint, frequency string) DigestSubscription {
+		t.Helper()
+
+		s, err := NewDigestSubscription(userID, frequency)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	t.Run("NewDigestSubscription", func(t *testing.T) {
+		s, err := NewDigestSubscription(7, DigestFrequencyDaily)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint(7), s.UserID)
+		assert.Equal(t, DigestFrequencyDaily, s.Frequency)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{64}$`), s.UnsubscribeToken)
+
+		other := newDigestSubscription(7, DigestFrequencyDaily)
+		assert.NotEqual(t, s.UnsubscribeToken, other.UnsubscribeToken)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			sub      DigestSubscription
+			hasError bool
+		}{
+			{
+				"validate digest subscription: daily",
+				newDigestSubscription(1, DigestFrequencyDaily),
+				false,
+			},
+			{
+				"validate digest subscription: weekly",
+				newDigestSubscription(1, DigestFrequencyWeekly),
+				false,
+			},
+			{
+				"validate digest subscription: off",
+				newDigestSubscription(1, DigestFrequencyOff),
+				false,
+			},
+			{
+				"validate digest subscription: unknown frequency",
+				newDigestSubscription(1, "hourly"),
+				true,
+			},
+			{
+				"validate digest subscription: no frequency",
+				newDigestSubscription(1, ""),
+				true,
+			},
+			{
+				"validate digest subscription: no user",
+				newDigestSubscription(0, DigestFrequencyDaily),
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			err := tt.sub.Validate()
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("ResponseDigestSubscription", func(t *testing.T) {
+		lastSentAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+		daily := DigestSubscription{Frequency: DigestFrequencyDaily, LastSentAt: lastSentAt}
+		resp := daily.ResponseDigestSubscription()
+		assert.Equal(t, DigestFrequencyDaily, resp.Frequency)
+		if assert.NotNil(t, resp.NextDigestAt) {
+			assert.Equal(t, "2024-01-02T08:00:00Z", *resp.NextDigestAt)
+		}
+
+		weekly := DigestSubscription{Frequency: DigestFrequencyWeekly, LastSentAt: lastSentAt}
+		resp = weekly.ResponseDigestSubscription()
+		if assert.NotNil(t, resp.NextDigestAt) {
+			assert.Equal(t, "2024-01-08T08:00:00Z", *resp.NextDigestAt)
+		}
+
+		off := DigestSubscription{Frequency: DigestFrequencyOff, LastSentAt: lastSentAt}
+		resp = off.ResponseDigestSubscription()
+		assert.Equal(t, DigestFrequencyOff, resp.Frequency)
+		assert.Nil(t, resp.NextDigestAt)
+	})
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// FeedToken model is the secret in the private feed url of a user,
+// which lets feed readers get the user's feed without logging in
+type FeedToken struct {
+	UserID    uint
+	Token     string
+	CreatedAt time.Time
+}
+
+// NewFeedToken returns a new random feed token of the user
+func NewFeedToken(userID uint) (FeedToken, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return FeedToken{}, err
+	}
+
+	return FeedToken{
+		UserID: userID,
+		Token:  hex.EncodeToString(b),
+	}, nil
+}
+
+// ResponseFeedToken generates response message for feed token with urls of its feed,
+// where feedURL is the url of the feed without format extension
+func (t *FeedToken) ResponseFeedToken(feedURL string) message.FeedTokenResponse {
+	return message.FeedTokenResponse{
+		Token:     t.Token,
+		AtomURL:   feedURL + ".atom",
+		RSSURL:    feedURL + ".rss",
+		CreatedAt: t.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_FeedTokenModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("NewFeedToken", func(t *testing.T) {
+		token, err := NewFeedToken(7)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint(7), token.UserID)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{64}$`), token.Token)
+
+		other, err := NewFeedToken(7)
+		assert.NoError(t, err)
+		assert.NotEqual(t, token.Token, other.Token)
+	})
+
+	t.Run("ResponseFeedToken", func(t *testing.T) {
+		createdAt := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+		token := FeedToken{UserID: 7, Token: "abc", CreatedAt: createdAt}
+
+		assert.Equal(t, message.FeedTokenResponse{
+			Token:     "abc",
+			AtomURL:   "https://example.com/api/v1/feeds/private/abc.atom",
+			RSSURL:    "https://example.com/api/v1/feeds/private/abc.rss",
+			CreatedAt: "2024-01-01T08:00:00Z",
+		}, token.ResponseFeedToken("https://example.com/api/v1/feeds/private/abc"))
+	})
+}
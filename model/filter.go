@@ -0,0 +1,247 @@
+package model
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+// Article sorts
+const (
+	ArticleSortNewest          = "newest"
+	ArticleSortOldest          = "oldest"
+	ArticleSortMostFavorited   = "most_favorited"
+	ArticleSortRecentlyUpdated = "recently_updated"
+	ArticleSortMostCommented   = "most_commented"
+)
+
+// Tag match modes
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// Feed sources
+const (
+	FeedSourceAll   = "all"
+	FeedSourceUsers = "users"
+	FeedSourceTags  = "tags"
+)
+
+const (
+	filterMaxTags    = 10
+	filterMaxAuthors = 10
+	filterDateLayout = "2006-01-02"
+)
+
+var articleSorts = []string{
+	ArticleSortNewest,
+	ArticleSortOldest,
+	ArticleSortMostFavorited,
+	ArticleSortRecentlyUpdated,
+	ArticleSortMostCommented,
+}
+
+func isArticleSort(sort string) bool {
+	return slices.Contains(articleSorts, sort)
+}
+
+// isTimeArticleSort tells whether articles of sort are ordered by a timestamp
+func isTimeArticleSort(sort string) bool {
+	return sort == ArticleSortNewest || sort == ArticleSortOldest || sort == ArticleSortRecentlyUpdated
+}
+
+// ArticleFilter model
+//
+// Date ranges are half-open, where After is inclusive and Before is exclusive.
+// Articles of users blocking or blocked by Viewer are left out, so are articles of
+// private users unless Viewer is the user or follows the user.
+type ArticleFilter struct {
+	Tags          []string
+	TagMatch      string
+	ExcludeTags   []string
+	Authors       []string
+	FavoritedBy   *User
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Sort          string
+	Viewer        *User
+}
+
+// ParseArticleFilter returns a validated article filter from url query values
+//
+// List values can be repeated or comma-separated, and dates are either
+// RFC 3339 timestamps or plain dates (YYYY-MM-DD) in UTC.
+func ParseArticleFilter(query url.Values) (ArticleFilter, error) {
+	f := ArticleFilter{
+		Tags:        queryList(query["tag"]),
+		TagMatch:    query.Get("tag_match"),
+		ExcludeTags: queryList(query["exclude_tag"]),
+		Authors:     queryList(query["username"]),
+		Sort:        query.Get("sort"),
+	}
+
+	if f.TagMatch == "" {
+		f.TagMatch = TagMatchAny
+	}
+
+	if f.Sort == "" {
+		f.Sort = ArticleSortNewest
+	}
+
+	dates := []struct {
+		field string
+		key   string
+		value **time.Time
+	}{
+		{"CreatedAfter", "created_after", &f.CreatedAfter},
+		{"CreatedBefore", "created_before", &f.CreatedBefore},
+		{"UpdatedAfter", "updated_after", &f.UpdatedAfter},
+		{"UpdatedBefore", "updated_before", &f.UpdatedBefore},
+	}
+
+	for _, d := range dates {
+		raw := strings.TrimSpace(query.Get(d.key))
+		if raw == "" {
+			continue
+		}
+
+		t, err := parseFilterTime(raw)
+		if err != nil {
+			return f, validation.Errors{d.field: err}
+		}
+
+		*d.value = &t
+	}
+
+	return f, f.Validate()
+}
+
+// Validate validates fields of article filter model
+func (f ArticleFilter) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(
+			&f.Tags,
+			validation.Length(0, filterMaxTags),
+			validation.Each(validation.Length(tagMinLen, tagMaxLen)),
+		),
+		validation.Field(
+			&f.TagMatch,
+			validation.Required,
+			validation.In(TagMatchAny, TagMatchAll),
+		),
+		validation.Field(
+			&f.ExcludeTags,
+			validation.Length(0, filterMaxTags),
+			validation.Each(validation.Length(tagMinLen, tagMaxLen)),
+		),
+		validation.Field(
+			&f.Authors,
+			validation.Length(0, filterMaxAuthors),
+		),
+		validation.Field(
+			&f.CreatedBefore,
+			validation.By(validateTimeRange(f.CreatedAfter, f.CreatedBefore)),
+		),
+		validation.Field(
+			&f.UpdatedBefore,
+			validation.By(validateTimeRange(f.UpdatedAfter, f.UpdatedBefore)),
+		),
+		validation.Field(
+			&f.Sort,
+			validation.Required,
+			validation.By(func(value interface{}) error {
+				if !isArticleSort(f.Sort) {
+					return errors.New("must be one of " + strings.Join(articleSorts, ", "))
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// FeedFilter model
+//
+// Feed of Viewer is made of articles of UserIDs and articles tagged with any of TagIDs,
+// where an article of both is listed once. Articles of tags are left out when they are
+// written by Viewer, by users muted by Viewer or by users hidden from Viewer.
+type FeedFilter struct {
+	Source  string
+	UserIDs []uint
+	TagIDs  []uint
+	Viewer  *User
+}
+
+// ParseFeedFilter returns a validated feed filter from url query values
+func ParseFeedFilter(query url.Values) (FeedFilter, error) {
+	f := FeedFilter{Source: query.Get("source")}
+
+	if f.Source == "" {
+		f.Source = FeedSourceAll
+	}
+
+	return f, f.Validate()
+}
+
+// Validate validates fields of feed filter model
+func (f FeedFilter) Validate() error {
+	return validation.ValidateStruct(&f,
+		validation.Field(
+			&f.Source,
+			validation.Required,
+			validation.In(FeedSourceAll, FeedSourceUsers, FeedSourceTags),
+		),
+	)
+}
+
+// FromUsers tells whether feed has articles of followed users
+func (f FeedFilter) FromUsers() bool {
+	return f.Source == FeedSourceAll || f.Source == FeedSourceUsers
+}
+
+// FromTags tells whether feed has articles of followed tags
+func (f FeedFilter) FromTags() bool {
+	return f.Source == FeedSourceAll || f.Source == FeedSourceTags
+}
+
+func validateTimeRange(after, before *time.Time) validation.RuleFunc {
+	return func(value interface{}) error {
+		if after != nil && before != nil && !before.After(*after) {
+			return errors.New("must be later than the start of range")
+		}
+		return nil
+	}
+}
+
+func parseFilterTime(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(filterDateLayout, raw)
+	if err != nil {
+		return time.Time{}, errors.New("must be a date (YYYY-MM-DD) or RFC 3339 timestamp")
+	}
+
+	return t, nil
+}
+
+// queryList flattens repeated and comma-separated query values into a list of unique values
+func queryList(values []string) []string {
+	list := []string{}
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item != "" && !slices.Contains(list, item) {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,153 @@
+package model
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_FilterModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("ParseArticleFilter", func(t *testing.T) {
+		date := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+		timestamp := time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC)
+
+		tests := []struct {
+			title    string
+			query    url.Values
+			expected ArticleFilter
+			hasError bool
+		}{
+			{
+				"parse article filter: defaults",
+				url.Values{"tag": {""}, "username": {""}},
+				ArticleFilter{
+					Tags:        []string{},
+					TagMatch:    TagMatchAny,
+					ExcludeTags: []string{},
+					Authors:     []string{},
+					Sort:        ArticleSortNewest,
+				},
+				false,
+			},
+			{
+				"parse article filter: repeated and comma-separated lists",
+				url.Values{
+					"tag":         {"golang, postgres", "golang"},
+					"tag_match":   {"all"},
+					"exclude_tag": {"draft"},
+					"username":    {"foo_user,bar_user"},
+					"sort":        {"most_favorited"},
+				},
+				ArticleFilter{
+					Tags:        []string{"golang", "postgres"},
+					TagMatch:    TagMatchAll,
+					ExcludeTags: []string{"draft"},
+					Authors:     []string{"foo_user", "bar_user"},
+					Sort:        ArticleSortMostFavorited,
+				},
+				false,
+			},
+			{
+				"parse article filter: date ranges",
+				url.Values{
+					"created_after":  {"2024-01-02"},
+					"created_before": {"2024-03-04T05:06:07Z"},
+					"updated_after":  {"2024-01-02"},
+				},
+				ArticleFilter{
+					Tags:          []string{},
+					TagMatch:      TagMatchAny,
+					ExcludeTags:   []string{},
+					Authors:       []string{},
+					CreatedAfter:  &date,
+					CreatedBefore: &timestamp,
+					UpdatedAfter:  &date,
+					Sort:          ArticleSortNewest,
+				},
+				false,
+			},
+			{
+				"parse article filter: unknown sort",
+				url.Values{"sort": {"popular"}},
+				ArticleFilter{},
+				true,
+			},
+			{
+				"parse article filter: unknown tag match",
+				url.Values{"tag_match": {"none"}},
+				ArticleFilter{},
+				true,
+			},
+			{
+				"parse article filter: tag is too short",
+				url.Values{"tag": {"go"}},
+				ArticleFilter{},
+				true,
+			},
+			{
+				"parse article filter: too many tags",
+				url.Values{"exclude_tag": {"tag01,tag02,tag03,tag04,tag05,tag06,tag07,tag08,tag09,tag10,tag11"}},
+				ArticleFilter{},
+				true,
+			},
+			{
+				"parse article filter: invalid date",
+				url.Values{"updated_before": {"yesterday"}},
+				ArticleFilter{},
+				true,
+			},
+			{
+				"parse article filter: empty date range",
+				url.Values{"created_after": {"2024-01-02"}, "created_before": {"2024-01-02"}},
+				ArticleFilter{},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			actual, err := ParseArticleFilter(tt.query)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expected, actual, tt.title)
+			}
+		}
+	})
+
+	t.Run("ParseFeedFilter", func(t *testing.T) {
+		tests := []struct {
+			title     string
+			query     url.Values
+			expected  FeedFilter
+			fromUsers bool
+			fromTags  bool
+			hasError  bool
+		}{
+			{"parse feed filter: defaults", url.Values{}, FeedFilter{Source: FeedSourceAll}, true, true, false},
+			{"parse feed filter: users", url.Values{"source": {"users"}}, FeedFilter{Source: FeedSourceUsers}, true, false, false},
+			{"parse feed filter: tags", url.Values{"source": {"tags"}}, FeedFilter{Source: FeedSourceTags}, false, true, false},
+			{"parse feed filter: unknown source", url.Values{"source": {"favorites"}}, FeedFilter{}, false, false, true},
+		}
+
+		for _, tt := range tests {
+			actual, err := ParseFeedFilter(tt.query)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expected, actual, tt.title)
+				assert.Equal(t, tt.fromUsers, actual.FromUsers(), tt.title)
+				assert.Equal(t, tt.fromTags, actual.FromTags(), tt.title)
+			}
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+const (
+	mediaFilenameMaxLen = 255
+)
+
+// mediaExtensions maps allowed media content types to file extensions of stored objects
+var mediaExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// MediaVariantSpec defines a resized variant generated from an image media
+type MediaVariantSpec struct {
+	Name   string
+	Width  int
+	Height int
+	Crop   bool
+}
+
+// MediaVariantSpecs are variants generated for every image media,
+// the square crop suits avatars while fitted sizes suit article thumbnails
+var MediaVariantSpecs = []MediaVariantSpec{
+	{Name: "thumb", Width: 128, Height: 128, Crop: true},
+	{Name: "small", Width: 320, Height: 320},
+	{Name: "medium", Width: 640, Height: 640},
+	{Name: "large", Width: 1280, Height: 1280},
+}
+
+// Media model
+type Media struct {
+	ID          uint
+	UserID      uint
+	StorageKey  string
+	Filename    string
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewMedia returns a new media of user with a unique storage key
+//
+// Content type should be sniffed from content rather than taken from client.
+func NewMedia(userID uint, filename, contentType string, size int64) (Media, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return Media{}, err
+	}
+
+	filename = strings.TrimSpace(filepath.Base(filepath.ToSlash(filename)))
+	if filename == "." || filename == "/" {
+		filename = ""
+	}
+
+	return Media{
+		UserID:      userID,
+		StorageKey:  fmt.Sprintf("media/%d/%s%s", userID, hex.EncodeToString(b), mediaExtensions[contentType]),
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+	}, nil
+}
+
+// Validate validates fields of media model
+func (m Media) Validate(maxSize int64) error {
+	contentTypes := make([]interface{}, 0, len(mediaExtensions))
+	for contentType := range mediaExtensions {
+		contentTypes = append(contentTypes, contentType)
+	}
+
+	return validation.ValidateStruct(&m,
+		validation.Field(
+			&m.UserID,
+			validation.Required,
+		),
+		validation.Field(
+			&m.Filename,
+			validation.Required,
+			validation.Length(1, mediaFilenameMaxLen),
+		),
+		validation.Field(
+			&m.ContentType,
+			validation.Required,
+			validation.In(contentTypes...).Error("must be an image (jpeg, png, gif, webp) or pdf"),
+		),
+		validation.Field(
+			&m.Size,
+			validation.Required,
+			validation.Max(maxSize).Error(fmt.Sprintf("must be no greater than %d bytes", maxSize)),
+		),
+	)
+}
+
+// IsImage returns whether media is an image
+func (m Media) IsImage() bool {
+	return strings.HasPrefix(m.ContentType, "image/")
+}
+
+// ResponseMedia generates response message from media with its public url
+func (m *Media) ResponseMedia(url string) message.MediaResponse {
+	return message.MediaResponse{
+		ID:          m.ID,
+		URL:         url,
+		Filename:    m.Filename,
+		ContentType: m.ContentType,
+		Size:        m.Size,
+		CreatedAt:   m.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// MediaVariant model
+type MediaVariant struct {
+	ID          uint
+	MediaID     uint
+	Name        string
+	StorageKey  string
+	ContentType string
+	Width       int
+	Height      int
+	Size        int64
+	Hash        string
+	CreatedAt   time.Time
+}
+
+// NewMediaVariant returns a new variant of media, keyed by hash of its content
+func NewMediaVariant(m *Media, name, contentType, hash string, width, height int, size int64) MediaVariant {
+	v := MediaVariant{
+		MediaID:     m.ID,
+		Name:        name,
+		ContentType: contentType,
+		Width:       width,
+		Height:      height,
+		Size:        size,
+		Hash:        hash,
+	}
+
+	v.StorageKey = fmt.Sprintf("media/%d/variants/%d-%s", m.UserID, m.ID, v.FileName())
+	return v
+}
+
+// FileName returns content-addressed file name of variant
+func (v *MediaVariant) FileName() string {
+	return fmt.Sprintf("%s-%s%s", v.Name, v.Hash, mediaExtensions[v.ContentType])
+}
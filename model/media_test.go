@@ -0,0 +1,141 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MediaModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	const maxSize = 1024
+
+	newMedia := func(userID uint, filename, contentType string, size int64) Media {
+		t.Helper()
+
+		m, err := NewMedia(userID, filename, contentType, size)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+
+	t.Run("NewMedia", func(t *testing.T) {
+		m, err := NewMedia(7, "../../etc/avatar.png", "image/png", 100)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint(7), m.UserID)
+		assert.Equal(t, "avatar.png", m.Filename)
+		assert.Equal(t, "image/png", m.ContentType)
+		assert.Equal(t, int64(100), m.Size)
+		assert.Regexp(t, regexp.MustCompile(`^media/7/[0-9a-f]{32}\.png$`), m.StorageKey)
+
+		other := newMedia(7, "avatar.png", "image/png", 100)
+		assert.NotEqual(t, m.StorageKey, other.StorageKey)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			media    Media
+			hasError bool
+		}{
+			{
+				"validate media: success image",
+				newMedia(1, "photo.jpg", "image/jpeg", 512),
+				false,
+			},
+			{
+				"validate media: success pdf",
+				newMedia(1, "paper.pdf", "application/pdf", maxSize),
+				false,
+			},
+			{
+				"validate media: no user id",
+				newMedia(0, "photo.jpg", "image/jpeg", 512),
+				true,
+			},
+			{
+				"validate media: no filename",
+				newMedia(1, "", "image/jpeg", 512),
+				true,
+			},
+			{
+				"validate media: unsupported content type",
+				newMedia(1, "page.html", "text/html", 512),
+				true,
+			},
+			{
+				"validate media: empty file",
+				newMedia(1, "photo.jpg", "image/jpeg", 0),
+				true,
+			},
+			{
+				"validate media: file is too large",
+				newMedia(1, "photo.jpg", "image/jpeg", maxSize+1),
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			err := tt.media.Validate(maxSize)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("NewMediaVariant", func(t *testing.T) {
+		m := Media{ID: 3, UserID: 7}
+
+		v := NewMediaVariant(&m, "thumb", "image/png", "0123456789abcdef", 128, 96, 2048)
+
+		assert.Equal(t, uint(3), v.MediaID)
+		assert.Equal(t, "thumb-0123456789abcdef.png", v.FileName())
+		assert.Equal(t, "media/7/variants/3-thumb-0123456789abcdef.png", v.StorageKey)
+		assert.Equal(t, 128, v.Width)
+		assert.Equal(t, 96, v.Height)
+		assert.Equal(t, int64(2048), v.Size)
+	})
+
+	t.Run("IsImage", func(t *testing.T) {
+		assert.True(t, Media{ContentType: "image/webp"}.IsImage())
+		assert.False(t, Media{ContentType: "application/pdf"}.IsImage())
+	})
+
+	t.Run("ResponseMedia", func(t *testing.T) {
+		now := time.Now()
+
+		expected := message.MediaResponse{
+			ID:          1,
+			URL:         "http://localhost/api/v1/media/1",
+			Filename:    "photo.jpg",
+			ContentType: "image/jpeg",
+			Size:        512,
+			CreatedAt:   now.Format(time.RFC3339Nano),
+		}
+
+		media := Media{
+			ID:          1,
+			UserID:      1,
+			StorageKey:  "media/1/photo.jpg",
+			Filename:    "photo.jpg",
+			ContentType: "image/jpeg",
+			Size:        512,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+
+		actual := media.ResponseMedia("http://localhost/api/v1/media/1")
+		assert.Equal(t, expected, actual)
+	})
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"regexp"
+)
+
+// MaxMentions is the maximum number of users mentioned by a body to be resolved
+const MaxMentions = 20
+
+var (
+	mentionPattern   = regexp.MustCompile(`(?:^|[^a-zA-Z0-9_.@/])@([a-zA-Z0-9][a-zA-Z0-9_.]*[a-zA-Z0-9])`)
+	codeBlockPattern = regexp.MustCompile("(?s)```.*?```")
+	codeSpanPattern  = regexp.MustCompile("`[^`\n]*`")
+)
+
+// ParseMentions returns unique usernames mentioned as @username in a body in order of appearance
+//
+// Mentions inside code blocks and code spans are ignored.
+func ParseMentions(body string) []string {
+	body = codeBlockPattern.ReplaceAllString(body, "")
+	body = codeSpanPattern.ReplaceAllString(body, "")
+
+	usernames := make([]string, 0)
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+
+		seen[username] = true
+		usernames = append(usernames, username)
+
+		if len(usernames) == MaxMentions {
+			break
+		}
+	}
+
+	return usernames
+}
+
+// ResponseMentions generates response message for mentioned users
+func ResponseMentions(users []User) []string {
+	resp := make([]string, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, u.Username)
+	}
+	return resp
+}
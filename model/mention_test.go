@@ -0,0 +1,68 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_MentionModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("ParseMentions", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			body     string
+			expected []string
+		}{
+			{
+				"parse mentions: none",
+				"A text body.",
+				[]string{},
+			},
+			{
+				"parse mentions: in order and unique",
+				"@foo_user hello, thanks @bar.user and @foo_user.",
+				[]string{"foo_user", "bar.user"},
+			},
+			{
+				"parse mentions: punctuation around",
+				"(cc @foo_user), @bar_user!",
+				[]string{"foo_user", "bar_user"},
+			},
+			{
+				"parse mentions: not an email or a path",
+				"mail foo@example.com or see /docs/@bar_user",
+				[]string{},
+			},
+			{
+				"parse mentions: not in code",
+				"`@foo_user` and\n```\n@bar_user\n```\nbut @baz_user",
+				[]string{"baz_user"},
+			},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, ParseMentions(tt.body), tt.title)
+		}
+	})
+
+	t.Run("ParseMentions: limit", func(t *testing.T) {
+		var sb strings.Builder
+		for i := 0; i < MaxMentions+5; i++ {
+			fmt.Fprintf(&sb, "@user_%d ", i)
+		}
+
+		assert.Len(t, ParseMentions(sb.String()), MaxMentions)
+	})
+
+	t.Run("ResponseMentions", func(t *testing.T) {
+		users := []User{{ID: 1, Username: "foo_user"}, {ID: 2, Username: "bar_user"}}
+		assert.Equal(t, []string{"foo_user", "bar_user"}, ResponseMentions(users))
+		assert.Equal(t, []string{}, ResponseMentions(nil))
+	})
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// Notification types
+const (
+	NotificationTypeFollow   = "follow"
+	NotificationTypeFavorite = "favorite"
+	NotificationTypeComment  = "comment"
+	NotificationTypeReply    = "reply"
+	NotificationTypeMention  = "mention"
+)
+
+// NotificationTypes lists all notification types a user can opt out of
+var NotificationTypes = []string{
+	NotificationTypeFollow,
+	NotificationTypeFavorite,
+	NotificationTypeComment,
+	NotificationTypeReply,
+	NotificationTypeMention,
+}
+
+// Notification model tells a user that an actor did something concerning them
+type Notification struct {
+	ID        uint
+	UserID    uint
+	Actor     User
+	Type      string
+	ArticleID *uint
+	CommentID *uint
+	ReadAt    *time.Time
+	CreatedAt time.Time
+}
+
+// IsRead returns whether notification was marked read
+func (n Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// ResponseNotification generates response message for notification
+func (n *Notification) ResponseNotification() message.NotificationResponse {
+	resp := message.NotificationResponse{
+		ID:        n.ID,
+		Type:      n.Type,
+		Actor:     n.Actor.ResponseProfile(false),
+		ArticleID: n.ArticleID,
+		CommentID: n.CommentID,
+		CreatedAt: n.CreatedAt.Format(time.RFC3339Nano),
+	}
+
+	if n.IsRead() {
+		readAt := n.ReadAt.Format(time.RFC3339Nano)
+		resp.Read = true
+		resp.ReadAt = &readAt
+	}
+
+	return resp
+}
+
+// NotificationPreferences model tells which notification types a user receives
+type NotificationPreferences map[string]bool
+
+// NewNotificationPreferences returns preferences receiving all types but opted out ones
+func NewNotificationPreferences(optOuts []string) NotificationPreferences {
+	prefs := make(NotificationPreferences, len(NotificationTypes))
+	for _, t := range NotificationTypes {
+		prefs[t] = !slices.Contains(optOuts, t)
+	}
+	return prefs
+}
+
+// Validate validates that preferences only have known notification types
+func (p NotificationPreferences) Validate() error {
+	errs := validation.Errors{}
+	for t := range p {
+		if !slices.Contains(NotificationTypes, t) {
+			errs[t] = errors.New("must be one of " + strings.Join(NotificationTypes, ", "))
+		}
+	}
+	return errs.Filter()
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_NotificationModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("ResponseNotification", func(t *testing.T) {
+		now := time.Now()
+		articleID := uint(2)
+
+		notification := Notification{
+			ID:        1,
+			UserID:    1,
+			Actor:     User{ID: 2, Username: "bar_user", Name: "BarUser"},
+			Type:      NotificationTypeFavorite,
+			ArticleID: &articleID,
+			CreatedAt: now,
+		}
+
+		expected := message.NotificationResponse{
+			ID:        1,
+			Type:      NotificationTypeFavorite,
+			Actor:     message.ProfileResponse{Username: "bar_user", Name: "BarUser"},
+			ArticleID: &articleID,
+			CreatedAt: now.Format(time.RFC3339Nano),
+		}
+		assert.Equal(t, expected, notification.ResponseNotification())
+
+		readAt := now.Add(time.Minute)
+		readAtString := readAt.Format(time.RFC3339Nano)
+		notification.ReadAt = &readAt
+
+		expected.Read = true
+		expected.ReadAt = &readAtString
+		assert.Equal(t, expected, notification.ResponseNotification())
+	})
+
+	t.Run("NewNotificationPreferences", func(t *testing.T) {
+		expected := NotificationPreferences{
+			NotificationTypeFollow:   true,
+			NotificationTypeFavorite: false,
+			NotificationTypeComment:  true,
+			NotificationTypeReply:    true,
+			NotificationTypeMention:  false,
+		}
+
+		actual := NewNotificationPreferences([]string{NotificationTypeFavorite, NotificationTypeMention})
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("NotificationPreferences: Validate", func(t *testing.T) {
+		prefs := NotificationPreferences{NotificationTypeFollow: false, NotificationTypeReply: true}
+		assert.NoError(t, prefs.Validate())
+
+		prefs = NotificationPreferences{"newsletter": false}
+		assert.EqualError(t, prefs.Validate(), "newsletter: must be one of follow, favorite, comment, reply, mention.")
+	})
+}
@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// Domain event types
+const (
+	DomainEventArticleCreated     = "article.created"
+	DomainEventArticleUpdated     = "article.updated"
+	DomainEventArticleDeleted     = "article.deleted"
+	DomainEventArticleFavorited   = "article.favorited"
+	DomainEventArticleUnfavorited = "article.unfavorited"
+	DomainEventCommentCreated     = "comment.created"
+	DomainEventCommentDeleted     = "comment.deleted"
+	DomainEventUserFollowed       = "user.followed"
+	DomainEventUserUnfollowed     = "user.unfollowed"
+	DomainEventUserMentioned      = "user.mentioned"
+)
+
+// DomainEvent model records what the actor did, written to outbox in the same
+// transaction as the change so that consumers never miss nor see a rolled back one
+//
+// UserID is the user the event concerns: the author of the article for article
+// and comment events, the followed user for follow events, or the mentioned user
+// for mention events. Payload keeps a snapshot of the article or comment, which
+// may be gone when it is consumed.
+type DomainEvent struct {
+	ID        uint
+	Type      string
+	ActorID   uint
+	UserID    *uint
+	ArticleID *uint
+	CommentID *uint
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// NewArticleEvent returns a new domain event of the article, done by its author
+func NewArticleEvent(eventType string, a *Article) (DomainEvent, error) {
+	article := a.ResponseArticle(false, false)
+
+	payload, err := json.Marshal(message.DomainEventPayload{Article: &article})
+	if err != nil {
+		return DomainEvent{}, err
+	}
+
+	return DomainEvent{
+		Type:      eventType,
+		ActorID:   a.UserID,
+		UserID:    &a.UserID,
+		ArticleID: &a.ID,
+		Payload:   payload,
+	}, nil
+}
+
+// NewCommentEvent returns a new domain event of the comment on an article of the article author, done by its author
+func NewCommentEvent(eventType string, c *Comment, articleAuthorID uint) (DomainEvent, error) {
+	comment := c.ResponseComment(false)
+
+	payload, err := json.Marshal(message.DomainEventPayload{Comment: &comment})
+	if err != nil {
+		return DomainEvent{}, err
+	}
+
+	return DomainEvent{
+		Type:      eventType,
+		ActorID:   c.UserID,
+		UserID:    &articleAuthorID,
+		ArticleID: &c.ArticleID,
+		CommentID: &c.ID,
+		Payload:   payload,
+	}, nil
+}
+
+// NewFavoriteEvent returns a new domain event of the user (un)favoriting the article
+func NewFavoriteEvent(eventType string, a *Article, user *User) DomainEvent {
+	return DomainEvent{
+		Type:      eventType,
+		ActorID:   user.ID,
+		UserID:    &a.UserID,
+		ArticleID: &a.ID,
+		Payload:   []byte(`{}`),
+	}
+}
+
+// NewFollowEvent returns a new domain event of user A (un)following user B
+func NewFollowEvent(eventType string, a *User, b *User) DomainEvent {
+	return DomainEvent{
+		Type:    eventType,
+		ActorID: a.ID,
+		UserID:  &b.ID,
+		Payload: []byte(`{}`),
+	}
+}
+
+// NewMentionEvent returns a new domain event of the author mentioning the user in an article,
+// or in a comment (if any) on the article
+func NewMentionEvent(authorID, userID, articleID uint, commentID *uint) DomainEvent {
+	return DomainEvent{
+		Type:      DomainEventUserMentioned,
+		ActorID:   authorID,
+		UserID:    &userID,
+		ArticleID: &articleID,
+		CommentID: commentID,
+		Payload:   []byte(`{}`),
+	}
+}
+
+// DecodePayload returns the snapshot kept by event
+func (e DomainEvent) DecodePayload() (message.DomainEventPayload, error) {
+	var payload message.DomainEventPayload
+	err := json.Unmarshal(e.Payload, &payload)
+	return payload, err
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_DomainEventModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	author := User{ID: 1, Username: "foo_user"}
+	article := Article{ID: 10, Title: "title", UserID: author.ID, Author: author}
+	comment := Comment{ID: 100, Body: "body", UserID: 2, ArticleID: article.ID, Author: User{ID: 2, Username: "bar_user"}}
+
+	t.Run("NewArticleEvent", func(t *testing.T) {
+		e, err := NewArticleEvent(DomainEventArticleCreated, &article)
+		assert.NoError(t, err)
+		assert.Equal(t, DomainEventArticleCreated, e.Type)
+		assert.Equal(t, author.ID, e.ActorID)
+		assert.Equal(t, &author.ID, e.UserID)
+		assert.Equal(t, &article.ID, e.ArticleID)
+		assert.Nil(t, e.CommentID)
+
+		payload, err := e.DecodePayload()
+		assert.NoError(t, err)
+		if assert.NotNil(t, payload.Article) {
+			assert.Equal(t, article.Title, payload.Article.Title)
+			assert.Equal(t, author.Username, payload.Article.Author.Username)
+		}
+		assert.Nil(t, payload.Comment)
+	})
+
+	t.Run("NewCommentEvent", func(t *testing.T) {
+		e, err := NewCommentEvent(DomainEventCommentCreated, &comment, author.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, comment.UserID, e.ActorID)
+		assert.Equal(t, &author.ID, e.UserID)
+		assert.Equal(t, &article.ID, e.ArticleID)
+		assert.Equal(t, &comment.ID, e.CommentID)
+
+		payload, err := e.DecodePayload()
+		assert.NoError(t, err)
+		if assert.NotNil(t, payload.Comment) {
+			assert.Equal(t, comment.Body, payload.Comment.Body)
+		}
+		assert.Nil(t, payload.Article)
+	})
+
+	t.Run("NewFavoriteEvent", func(t *testing.T) {
+		user := User{ID: 3}
+
+		e := NewFavoriteEvent(DomainEventArticleFavorited, &article, &user)
+		assert.Equal(t, user.ID, e.ActorID)
+		assert.Equal(t, &author.ID, e.UserID)
+		assert.Equal(t, &article.ID, e.ArticleID)
+
+		payload, err := e.DecodePayload()
+		assert.NoError(t, err)
+		assert.Nil(t, payload.Article)
+	})
+
+	t.Run("NewFollowEvent", func(t *testing.T) {
+		user := User{ID: 3}
+
+		e := NewFollowEvent(DomainEventUserFollowed, &user, &author)
+		assert.Equal(t, user.ID, e.ActorID)
+		assert.Equal(t, &author.ID, e.UserID)
+		assert.Nil(t, e.ArticleID)
+	})
+
+	t.Run("NewMentionEvent", func(t *testing.T) {
+		user := User{ID: 3}
+
+		e := NewMentionEvent(comment.UserID, user.ID, article.ID, &comment.ID)
+		assert.Equal(t, DomainEventUserMentioned, e.Type)
+		assert.Equal(t, comment.UserID, e.ActorID)
+		assert.Equal(t, &user.ID, e.UserID)
+		assert.Equal(t, &article.ID, e.ArticleID)
+		assert.Equal(t, &comment.ID, e.CommentID)
+	})
+
+	t.Run("NewWebhookPayload", func(t *testing.T) {
+		createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		articleEvent, err := NewArticleEvent(DomainEventArticleCreated, &article)
+		assert.NoError(t, err)
+		articleEvent.CreatedAt = createdAt
+
+		payload, delivered, err := NewWebhookPayload(&articleEvent)
+		assert.NoError(t, err)
+		assert.True(t, delivered)
+		assert.Equal(t, WebhookEventArticlePublished, payload.Event)
+		assert.Equal(t, article.ID, payload.ArticleID)
+		assert.NotNil(t, payload.Article)
+		assert.Equal(t, createdAt.Format(time.RFC3339Nano), payload.CreatedAt)
+
+		commentEvent, err := NewCommentEvent(DomainEventCommentCreated, &comment, author.ID)
+		assert.NoError(t, err)
+
+		payload, delivered, err = NewWebhookPayload(&commentEvent)
+		assert.NoError(t, err)
+		assert.True(t, delivered)
+		assert.Equal(t, WebhookEventCommentCreated, payload.Event)
+		assert.NotNil(t, payload.Comment)
+
+		// events without webhook events are not delivered
+		favoriteEvent := NewFavoriteEvent(DomainEventArticleFavorited, &article, &author)
+
+		_, delivered, err = NewWebhookPayload(&favoriteEvent)
+		assert.NoError(t, err)
+		assert.False(t, delivered)
+	})
+}
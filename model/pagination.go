@@ -0,0 +1,175 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+)
+
+const (
+	pageMinLimit = 1
+	// PageMaxLimit is the most rows a page can have
+	PageMaxLimit = 100
+)
+
+var (
+	errInvalidCursor      = errors.New("invalid cursor")
+	errCursorSortMismatch = errors.New("cursor does not match sort")
+)
+
+// Cursor model points at a row of a keyset paginated listing
+//
+// A cursor carries the sort it was issued for along with the sort key of the row,
+// which is a timestamp (Time) or a count (Count) depending on the sort.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Time  time.Time `json:"t"`
+	Count int64     `json:"n"`
+	ID    uint      `json:"i"`
+}
+
+// Encode returns an opaque string of cursor
+func (c Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor parses an opaque cursor string
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var c Cursor
+	err = json.Unmarshal(b, &c)
+	if err != nil || c.ID == 0 || !(isArticleSort(c.Sort) || isCommentSort(c.Sort)) {
+		return nil, errInvalidCursor
+	}
+
+	if (isTimeArticleSort(c.Sort) || isTimeCommentSort(c.Sort)) && c.Time.IsZero() {
+		return nil, errInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Page model
+//
+// A page is either addressed by offset or by a cursor, where After returns
+// rows following the cursor and Before returns rows preceding it.
+type Page struct {
+	Limit  int64
+	Offset int64
+	After  *Cursor
+	Before *Cursor
+}
+
+// ParsePage returns a validated page from limit, offset and opaque cursors
+func ParsePage(limit, offset int64, after, before string) (Page, error) {
+	page := Page{Limit: limit, Offset: offset}
+
+	var err error
+	if after != "" {
+		page.After, err = DecodeCursor(after)
+		if err != nil {
+			return page, validation.Errors{"After": err}
+		}
+	}
+
+	if before != "" {
+		page.Before, err = DecodeCursor(before)
+		if err != nil {
+			return page, validation.Errors{"Before": err}
+		}
+	}
+
+	return page, page.Validate()
+}
+
+// Validate validates fields of page model
+func (p Page) Validate() error {
+	return validation.ValidateStruct(&p,
+		validation.Field(
+			&p.Limit,
+			validation.Required,
+			validation.Min(int64(pageMinLimit)),
+			validation.Max(int64(PageMaxLimit)),
+		),
+		validation.Field(
+			&p.Offset,
+			validation.Min(int64(0)),
+			validation.By(func(value interface{}) error {
+				if p.Offset != 0 && (p.After != nil || p.Before != nil) {
+					return errors.New("cannot be used with a cursor")
+				}
+				return nil
+			}),
+		),
+		validation.Field(
+			&p.Before,
+			validation.By(func(value interface{}) error {
+				if p.Before != nil && p.After != nil {
+					return errors.New("cannot be used with after")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// ValidateSort validates that cursors of page were issued for sort
+func (p Page) ValidateSort(sort string) error {
+	if p.After != nil && p.After.Sort != sort {
+		return validation.Errors{"After": errCursorSortMismatch}
+	}
+
+	if p.Before != nil && p.Before.Sort != sort {
+		return validation.Errors{"Before": errCursorSortMismatch}
+	}
+
+	return nil
+}
+
+// PageInfo model
+type PageInfo struct {
+	TotalCount int64
+	Next       *Cursor
+	Prev       *Cursor
+}
+
+// NewPageInfo computes next and previous cursors of a fetched page
+//
+// hasMore tells whether there was another row beyond the page in fetching direction.
+func NewPageInfo(p Page, totalCount int64, first, last *Cursor, count int, hasMore bool) *PageInfo {
+	info := &PageInfo{TotalCount: totalCount}
+	if count == 0 {
+		return info
+	}
+
+	var hasNext, hasPrev bool
+	switch {
+	case p.After != nil:
+		hasNext = hasMore
+		hasPrev = true
+	case p.Before != nil:
+		hasNext = true
+		hasPrev = hasMore
+	default:
+		hasNext = p.Offset+int64(count) < totalCount
+		hasPrev = p.Offset > 0
+	}
+
+	if hasNext {
+		info.Next = last
+	}
+
+	if hasPrev {
+		info.Prev = first
+	}
+
+	return info
+}
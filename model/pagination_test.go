@@ -0,0 +1,177 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_PaginationModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	t.Run("Cursor", func(t *testing.T) {
+		cursor := Cursor{Sort: ArticleSortNewest, Time: now, ID: 10}
+
+		actual, err := DecodeCursor(cursor.Encode())
+		assert.NoError(t, err, "cursor: decode encoded cursor")
+		assert.True(t, now.Equal(actual.Time), "cursor: decode encoded cursor")
+		assert.Equal(t, cursor.Sort, actual.Sort, "cursor: decode encoded cursor")
+		assert.Equal(t, cursor.ID, actual.ID, "cursor: decode encoded cursor")
+
+		countCursor := Cursor{Sort: ArticleSortMostFavorited, Count: 0, ID: 10}
+
+		actual, err = DecodeCursor(countCursor.Encode())
+		assert.NoError(t, err, "cursor: decode count cursor")
+		assert.Equal(t, countCursor, *actual, "cursor: decode count cursor")
+
+		commentCursor := Cursor{Sort: CommentSortTop, Count: 3, ID: 10}
+
+		actual, err = DecodeCursor(commentCursor.Encode())
+		assert.NoError(t, err, "cursor: decode comment cursor")
+		assert.Equal(t, commentCursor, *actual, "cursor: decode comment cursor")
+
+		tests := []struct {
+			title  string
+			cursor string
+		}{
+			{"cursor: not base64", "%%%"},
+			{"cursor: not json", "bm90IGpzb24"},
+			{"cursor: missing position", Cursor{}.Encode()},
+			{"cursor: unknown sort", Cursor{Sort: "unknown", Time: now, ID: 10}.Encode()},
+			{"cursor: missing time of time sort", Cursor{Sort: ArticleSortOldest, Count: 1, ID: 10}.Encode()},
+		}
+
+		for _, tt := range tests {
+			actual, err := DecodeCursor(tt.cursor)
+			assert.Error(t, err, tt.title)
+			assert.Nil(t, actual, tt.title)
+		}
+	})
+
+	t.Run("ParsePage", func(t *testing.T) {
+		cursor := Cursor{Sort: ArticleSortNewest, Time: now, ID: 10}
+
+		tests := []struct {
+			title    string
+			limit    int64
+			offset   int64
+			after    string
+			before   string
+			hasError bool
+		}{
+			{"parse page: limit and offset", 20, 10, "", "", false},
+			{"parse page: after cursor", 20, 0, cursor.Encode(), "", false},
+			{"parse page: before cursor", 20, 0, "", cursor.Encode(), false},
+			{"parse page: negative limit", -1, 0, "", "", true},
+			{"parse page: limit is too large", 101, 0, "", "", true},
+			{"parse page: negative offset", 20, -1, "", "", true},
+			{"parse page: offset with cursor", 20, 5, cursor.Encode(), "", true},
+			{"parse page: after with before", 20, 0, cursor.Encode(), cursor.Encode(), true},
+			{"parse page: invalid after", 20, 0, "invalid", "", true},
+			{"parse page: invalid before", 20, 0, "", "invalid", true},
+		}
+
+		for _, tt := range tests {
+			_, err := ParsePage(tt.limit, tt.offset, tt.after, tt.before)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("ValidateSort", func(t *testing.T) {
+		cursor := &Cursor{Sort: ArticleSortNewest, Time: now, ID: 10}
+
+		tests := []struct {
+			title    string
+			page     Page
+			sort     string
+			hasError bool
+		}{
+			{"validate sort: without cursor", Page{Limit: 20}, ArticleSortOldest, false},
+			{"validate sort: after cursor of sort", Page{Limit: 20, After: cursor}, ArticleSortNewest, false},
+			{"validate sort: after cursor of other sort", Page{Limit: 20, After: cursor}, ArticleSortOldest, true},
+			{"validate sort: before cursor of other sort", Page{Limit: 20, Before: cursor}, ArticleSortMostFavorited, true},
+		}
+
+		for _, tt := range tests {
+			err := tt.page.ValidateSort(tt.sort)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("NewPageInfo", func(t *testing.T) {
+		first := &Cursor{Sort: ArticleSortNewest, Time: now, ID: 2}
+		last := &Cursor{Sort: ArticleSortNewest, Time: now, ID: 1}
+		cursor := &Cursor{Sort: ArticleSortNewest, Time: now, ID: 3}
+
+		tests := []struct {
+			title    string
+			page     Page
+			count    int
+			hasMore  bool
+			expected *PageInfo
+		}{
+			{
+				"new page info: first offset page",
+				Page{Limit: 2, Offset: 0},
+				2,
+				false,
+				&PageInfo{TotalCount: 5, Next: last},
+			},
+			{
+				"new page info: last offset page",
+				Page{Limit: 2, Offset: 3},
+				2,
+				false,
+				&PageInfo{TotalCount: 5, Prev: first},
+			},
+			{
+				"new page info: after cursor with more rows",
+				Page{Limit: 2, After: cursor},
+				2,
+				true,
+				&PageInfo{TotalCount: 5, Next: last, Prev: first},
+			},
+			{
+				"new page info: after cursor without more rows",
+				Page{Limit: 2, After: cursor},
+				2,
+				false,
+				&PageInfo{TotalCount: 5, Prev: first},
+			},
+			{
+				"new page info: before cursor without more rows",
+				Page{Limit: 2, Before: cursor},
+				2,
+				false,
+				&PageInfo{TotalCount: 5, Next: last},
+			},
+			{
+				"new page info: empty page",
+				Page{Limit: 2, After: cursor},
+				0,
+				false,
+				&PageInfo{TotalCount: 5},
+			},
+		}
+
+		for _, tt := range tests {
+			actual := NewPageInfo(tt.page, 5, first, last, tt.count, tt.hasMore)
+			assert.Equal(t, tt.expected, actual, tt.title)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// Reaction model counts users who reacted to an article or a comment with a reaction
+//
+// Reacted tells whether the viewing user is one of them.
+type Reaction struct {
+	Name    string
+	Count   int64
+	Reacted bool
+}
+
+// ValidateReaction validates that reaction is one of allowed reactions
+func ValidateReaction(reaction string, allowed []string) error {
+	return validation.Errors{
+		"reaction": validation.Validate(
+			reaction,
+			validation.By(func(value interface{}) error {
+				if !slices.Contains(allowed, reaction) {
+					return errors.New("must be one of " + strings.Join(allowed, ", "))
+				}
+				return nil
+			}),
+		),
+	}.Filter()
+}
+
+// ResponseReactions generates response message for reactions
+func ResponseReactions(reactions []Reaction) []message.ReactionResponse {
+	resp := make([]message.ReactionResponse, 0, len(reactions))
+	for _, r := range reactions {
+		resp = append(resp, message.ReactionResponse{
+			Reaction: r.Name,
+			Count:    r.Count,
+			Reacted:  r.Reacted,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_ReactionModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	allowed := []string{"thumbs_up", "heart"}
+
+	t.Run("ValidateReaction", func(t *testing.T) {
+		assert.NoError(t, ValidateReaction("thumbs_up", allowed))
+		assert.NoError(t, ValidateReaction("heart", allowed))
+		assert.EqualError(t, ValidateReaction("rocket", allowed), "reaction: must be one of thumbs_up, heart.")
+		assert.Error(t, ValidateReaction("", allowed))
+	})
+
+	t.Run("ResponseReactions", func(t *testing.T) {
+		reactions := []Reaction{
+			{Name: "heart", Count: 3, Reacted: true},
+			{Name: "thumbs_up", Count: 1},
+		}
+
+		expected := []message.ReactionResponse{
+			{Reaction: "heart", Count: 3, Reacted: true},
+			{Reaction: "thumbs_up", Count: 1, Reacted: false},
+		}
+
+		assert.Equal(t, expected, ResponseReactions(reactions))
+		assert.Equal(t, []message.ReactionResponse{}, ResponseReactions(nil))
+	})
+}
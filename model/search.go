@@ -0,0 +1,187 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+const (
+	searchQueryMinLen = 2
+	searchQueryMaxLen = 200
+)
+
+// Profile search sorts
+const (
+	ProfileSortRelevance = "relevance"
+	ProfileSortFollowers = "followers"
+)
+
+// SearchQuery model
+//
+// A raw query is made of plain words, "quoted phrases" and prefix words
+// ending with an asterisk (e.g. post*). Every term must match.
+type SearchQuery struct {
+	Raw      string
+	Words    []string
+	Phrases  [][]string
+	Prefixes []string
+}
+
+// ParseSearchQuery parses a raw search query into words, phrases and prefixes
+func ParseSearchQuery(raw string) SearchQuery {
+	q := SearchQuery{Raw: strings.TrimSpace(raw)}
+
+	parts := strings.Split(q.Raw, `"`)
+	for i, part := range parts {
+		// odd parts are wrapped with double quotes
+		if i%2 == 1 {
+			phrase := searchLexemes(part)
+			switch len(phrase) {
+			case 0:
+			case 1:
+				q.Words = append(q.Words, phrase[0])
+			default:
+				q.Phrases = append(q.Phrases, phrase)
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			lexemes := searchLexemes(field)
+			if len(lexemes) == 0 {
+				continue
+			}
+
+			if strings.HasSuffix(field, "*") {
+				// only the last lexeme of a word is treated as a prefix (e.g. full-tex*)
+				q.Words = append(q.Words, lexemes[:len(lexemes)-1]...)
+				q.Prefixes = append(q.Prefixes, lexemes[len(lexemes)-1])
+				continue
+			}
+
+			q.Words = append(q.Words, lexemes...)
+		}
+	}
+
+	return q
+}
+
+// searchLexemes splits text into lower-cased words made only of letters and numbers
+func searchLexemes(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// Validate validates fields of search query model
+func (q SearchQuery) Validate() error {
+	return validation.ValidateStruct(&q,
+		validation.Field(
+			&q.Raw,
+			validation.Required,
+			validation.Length(searchQueryMinLen, searchQueryMaxLen),
+			validation.By(func(value interface{}) error {
+				if q.IsEmpty() {
+					return errors.New("must contain at least one word")
+				}
+				return nil
+			}),
+		),
+	)
+}
+
+// IsEmpty checks whether the query has no searchable terms
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Words) == 0 && len(q.Phrases) == 0 && len(q.Prefixes) == 0
+}
+
+// TSQuery returns the query in postgres to_tsquery syntax
+func (q SearchQuery) TSQuery() string {
+	terms := make([]string, 0, len(q.Words)+len(q.Phrases)+len(q.Prefixes))
+
+	for _, w := range q.Words {
+		terms = append(terms, fmt.Sprintf("'%s'", w))
+	}
+
+	for _, p := range q.Phrases {
+		words := make([]string, 0, len(p))
+		for _, w := range p {
+			words = append(words, fmt.Sprintf("'%s'", w))
+		}
+		terms = append(terms, fmt.Sprintf("(%s)", strings.Join(words, " <-> ")))
+	}
+
+	for _, p := range q.Prefixes {
+		terms = append(terms, fmt.Sprintf("'%s':*", p))
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// ArticleSearchResult model
+type ArticleSearchResult struct {
+	Article              Article
+	Rank                 float64
+	TitleHighlight       string
+	DescriptionHighlight string
+	BodyHighlight        string
+}
+
+// ResponseSearchArticle generates response message for article search result
+func (r *ArticleSearchResult) ResponseSearchArticle(favorited, followingAuthor bool) message.SearchArticleResponse {
+	return message.SearchArticleResponse{
+		Article: r.Article.ResponseArticle(favorited, followingAuthor),
+		Rank:    r.Rank,
+		Highlights: message.SearchHighlightResponse{
+			Title:       r.TitleHighlight,
+			Description: r.DescriptionHighlight,
+			Body:        r.BodyHighlight,
+		},
+	}
+}
+
+// ProfileSearch model
+//
+// Profiles match when a word of their username, name or bio is similar to Query.
+// Users blocking or blocked by Viewer are left out.
+type ProfileSearch struct {
+	Query  string
+	Sort   string
+	Viewer *User
+}
+
+// ParseProfileSearch returns a validated profile search from url query values
+func ParseProfileSearch(query url.Values) (ProfileSearch, error) {
+	s := ProfileSearch{
+		Query: strings.TrimSpace(query.Get("q")),
+		Sort:  query.Get("sort"),
+	}
+
+	if s.Sort == "" {
+		s.Sort = ProfileSortRelevance
+	}
+
+	return s, s.Validate()
+}
+
+// Validate validates fields of profile search model
+func (s ProfileSearch) Validate() error {
+	return validation.ValidateStruct(&s,
+		validation.Field(
+			&s.Query,
+			validation.Required,
+			validation.Length(searchQueryMinLen, searchQueryMaxLen),
+		),
+		validation.Field(
+			&s.Sort,
+			validation.Required,
+			validation.In(ProfileSortRelevance, ProfileSortFollowers),
+		),
+	)
+}
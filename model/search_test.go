@@ -0,0 +1,295 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_SearchModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("ParseSearchQuery", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			raw      string
+			expected SearchQuery
+		}{
+			{
+				"parse search query: words",
+				"  Golang   Postgres ",
+				SearchQuery{
+					Raw:   "Golang   Postgres",
+					Words: []string{"golang", "postgres"},
+				},
+			},
+			{
+				"parse search query: phrase",
+				`"full text search" index`,
+				SearchQuery{
+					Raw:     `"full text search" index`,
+					Words:   []string{"index"},
+					Phrases: [][]string{{"full", "text", "search"}},
+				},
+			},
+			{
+				"parse search query: single word phrase",
+				`"postgres"`,
+				SearchQuery{
+					Raw:   `"postgres"`,
+					Words: []string{"postgres"},
+				},
+			},
+			{
+				"parse search query: prefix",
+				"post* gin",
+				SearchQuery{
+					Raw:      "post* gin",
+					Words:    []string{"gin"},
+					Prefixes: []string{"post"},
+				},
+			},
+			{
+				"parse search query: hyphenated prefix",
+				"full-tex*",
+				SearchQuery{
+					Raw:      "full-tex*",
+					Words:    []string{"full"},
+					Prefixes: []string{"tex"},
+				},
+			},
+			{
+				"parse search query: strip tsquery operators",
+				"go & (sql | !orm):*",
+				SearchQuery{
+					Raw:   "go & (sql | !orm):*",
+					Words: []string{"go", "sql"},
+					// the trailing asterisk belongs to the "!orm):*" field
+					Prefixes: []string{"orm"},
+				},
+			},
+			{
+				"parse search query: unclosed quote",
+				`"full text`,
+				SearchQuery{
+					Raw:     `"full text`,
+					Phrases: [][]string{{"full", "text"}},
+				},
+			},
+			{
+				"parse search query: no terms",
+				`"" * &`,
+				SearchQuery{
+					Raw: `"" * &`,
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			actual := ParseSearchQuery(tt.raw)
+			assert.Equal(t, tt.expected, actual, tt.title)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			raw      string
+			hasError bool
+		}{
+			{
+				"validate search query: success",
+				"golang",
+				false,
+			},
+			{
+				"validate search query: empty",
+				"",
+				true,
+			},
+			{
+				"validate search query: too short",
+				"a",
+				true,
+			},
+			{
+				"validate search query: too long",
+				strings.Repeat("a", 201),
+				true,
+			},
+			{
+				"validate search query: no searchable terms",
+				"&& ||",
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			err := ParseSearchQuery(tt.raw).Validate()
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("TSQuery", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			raw      string
+			expected string
+		}{
+			{
+				"tsquery: words",
+				"golang postgres",
+				"'golang' & 'postgres'",
+			},
+			{
+				"tsquery: phrase and prefix",
+				`"full text" sear*`,
+				"('full' <-> 'text') & 'sear':*",
+			},
+			{
+				"tsquery: no terms",
+				"&&",
+				"",
+			},
+		}
+
+		for _, tt := range tests {
+			actual := ParseSearchQuery(tt.raw).TSQuery()
+			assert.Equal(t, tt.expected, actual, tt.title)
+		}
+	})
+
+	t.Run("ResponseSearchArticle", func(t *testing.T) {
+		now := time.Now()
+		nowString := now.Format(time.RFC3339Nano)
+
+		expected := message.SearchArticleResponse{
+			Article: message.ArticleResponse{
+				ID:          1,
+				Title:       "Article 1",
+				Description: "This is a description.",
+				Body:        "This is a text body.",
+				Author: message.ProfileResponse{
+					Username:  "foo_user",
+					Name:      "FooUser",
+					Bio:       "This is my bio.",
+					Image:     "https://imgur.com/image.jpeg",
+					Following: true,
+				},
+				Favorited:      true,
+				FavoritesCount: 10,
+				Reactions:      []message.ReactionResponse{},
+				Mentions:       []string{},
+				CreatedAt:      nowString,
+				UpdatedAt:      nowString,
+				Tags:           []string{"tag-1"},
+			},
+			Rank: 0.5,
+			Highlights: message.SearchHighlightResponse{
+				Title:       "<mark>Article</mark> 1",
+				Description: "This is a description.",
+				Body:        "This is a text body.",
+			},
+		}
+
+		result := ArticleSearchResult{
+			Article: Article{
+				ID:          1,
+				Title:       "Article 1",
+				Description: "This is a description.",
+				Body:        "This is a text body.",
+				UserID:      1,
+				Author: User{
+					ID:        1,
+					Username:  "foo_user",
+					Email:     "foo@example.com",
+					Password:  "encrypted_password",
+					Name:      "FooUser",
+					Bio:       "This is my bio.",
+					Image:     "https://imgur.com/image.jpeg",
+					CreatedAt: now,
+					UpdatedAt: now,
+				},
+				FavoritesCount: 10,
+				Tags:           []Tag{{Name: "tag-1"}},
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			},
+			Rank:                 0.5,
+			TitleHighlight:       "<mark>Article</mark> 1",
+			DescriptionHighlight: "This is a description.",
+			BodyHighlight:        "This is a text body.",
+		}
+
+		actual := result.ResponseSearchArticle(true, true)
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("ParseProfileSearch", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			query    url.Values
+			expected ProfileSearch
+			hasError bool
+		}{
+			{
+				"parse profile search: defaults",
+				url.Values{"q": {"  foo user "}},
+				ProfileSearch{Query: "foo user", Sort: ProfileSortRelevance},
+				false,
+			},
+			{
+				"parse profile search: sort by followers",
+				url.Values{"q": {"foo"}, "sort": {"followers"}},
+				ProfileSearch{Query: "foo", Sort: ProfileSortFollowers},
+				false,
+			},
+			{
+				"parse profile search: no query",
+				url.Values{"q": {"  "}},
+				ProfileSearch{},
+				true,
+			},
+			{
+				"parse profile search: query is too short",
+				url.Values{"q": {"f"}},
+				ProfileSearch{},
+				true,
+			},
+			{
+				"parse profile search: query is too long",
+				url.Values{"q": {strings.Repeat("a", searchQueryMaxLen+1)}},
+				ProfileSearch{},
+				true,
+			},
+			{
+				"parse profile search: unknown sort",
+				url.Values{"q": {"foo"}, "sort": {"newest"}},
+				ProfileSearch{},
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			actual, err := ParseProfileSearch(tt.query)
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expected, actual, tt.title)
+			}
+		}
+	})
+}
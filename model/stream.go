@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// Stream event types
+const (
+	StreamEventNotification = "notification"
+	StreamEventComment      = "comment"
+	StreamEventFavorites    = "favorites"
+)
+
+// StreamEvent model tells that something was created or changed, to be pushed to subscribers
+//
+// Notification events are pushed to the user they notify, comment and
+// favorites events to the users viewing the article.
+type StreamEvent struct {
+	ID        uint
+	Type      string
+	UserID    *uint
+	ArticleID *uint
+	TargetID  uint
+	CreatedAt time.Time
+}
+
+// Matches returns whether event is pushed to the user viewing an article (if any)
+func (e StreamEvent) Matches(userID uint, articleID *uint) bool {
+	switch e.Type {
+	case StreamEventNotification:
+		return e.UserID != nil && *e.UserID == userID
+	case StreamEventComment, StreamEventFavorites:
+		return articleID != nil && e.ArticleID != nil && *e.ArticleID == *articleID
+	default:
+		return false
+	}
+}
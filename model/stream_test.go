@@ -0,0 +1,81 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_StreamEventModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("Matches", func(t *testing.T) {
+		fooUserID := uint(1)
+		fooArticleID := uint(10)
+		barArticleID := uint(20)
+
+		tests := []struct {
+			title     string
+			event     StreamEvent
+			userID    uint
+			articleID *uint
+			expected  bool
+		}{
+			{
+				"matches: notification of user",
+				StreamEvent{Type: StreamEventNotification, UserID: &fooUserID},
+				fooUserID,
+				nil,
+				true,
+			},
+			{
+				"matches: notification of other user",
+				StreamEvent{Type: StreamEventNotification, UserID: &fooUserID},
+				2,
+				&fooArticleID,
+				false,
+			},
+			{
+				"matches: comment on viewed article",
+				StreamEvent{Type: StreamEventComment, ArticleID: &fooArticleID},
+				fooUserID,
+				&fooArticleID,
+				true,
+			},
+			{
+				"matches: comment on other article",
+				StreamEvent{Type: StreamEventComment, ArticleID: &fooArticleID},
+				fooUserID,
+				&barArticleID,
+				false,
+			},
+			{
+				"matches: favorites without viewed article",
+				StreamEvent{Type: StreamEventFavorites, ArticleID: &fooArticleID},
+				fooUserID,
+				nil,
+				false,
+			},
+			{
+				"matches: favorites of viewed article",
+				StreamEvent{Type: StreamEventFavorites, ArticleID: &fooArticleID},
+				fooUserID,
+				&fooArticleID,
+				true,
+			},
+			{
+				"matches: unknown type",
+				StreamEvent{Type: "unknown", UserID: &fooUserID, ArticleID: &fooArticleID},
+				fooUserID,
+				&fooArticleID,
+				false,
+			},
+		}
+
+		for _, tt := range tests {
+			assert.Equal(t, tt.expected, tt.event.Matches(tt.userID, tt.articleID), tt.title)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trending windows
+const (
+	TrendingWindowDay   = "24h"
+	TrendingWindowWeek  = "7d"
+	TrendingWindowMonth = "30d"
+)
+
+// Weights of activity on an article in its trending score
+const (
+	TrendingWeightView     = 1
+	TrendingWeightComment  = 3
+	TrendingWeightFavorite = 5
+)
+
+// TrendingWindow model is a period of recent activity articles are ranked by,
+// where activity counts half as much every HalfLife it gets older
+type TrendingWindow struct {
+	Name     string
+	Period   time.Duration
+	HalfLife time.Duration
+}
+
+// TrendingWindows are the windows trending articles are ranked in
+var TrendingWindows = []TrendingWindow{
+	{Name: TrendingWindowDay, Period: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	{Name: TrendingWindowWeek, Period: 7 * 24 * time.Hour, HalfLife: 36 * time.Hour},
+	{Name: TrendingWindowMonth, Period: 30 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+}
+
+// GetTrendingWindow returns the trending window of name, or the day window when name is empty
+func GetTrendingWindow(name string) (TrendingWindow, error) {
+	if name == "" {
+		name = TrendingWindowDay
+	}
+
+	names := make([]string, 0, len(TrendingWindows))
+	for _, w := range TrendingWindows {
+		if w.Name == name {
+			return w, nil
+		}
+		names = append(names, w.Name)
+	}
+
+	return TrendingWindow{}, fmt.Errorf("window must be one of %s", strings.Join(names, ", "))
+}
+
+// ViewerKey returns what views of an article are told apart by, the id of the user when logged in
+// or a hash of the address of an anonymous viewer, which is not kept as is
+func ViewerKey(viewer *User, ip string) string {
+	if viewer != nil {
+		return "user:" + strconv.FormatUint(uint64(viewer.ID), 10)
+	}
+
+	sum := sha256.Sum256([]byte(ip))
+	return "ip:" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_TrendingModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	t.Run("GetTrendingWindow", func(t *testing.T) {
+		tests := []struct {
+			title          string
+			name           string
+			expectedPeriod time.Duration
+			hasError       bool
+		}{
+			{"get trending window: default", "", 24 * time.Hour, false},
+			{"get trending window: day", "24h", 24 * time.Hour, false},
+			{"get trending window: week", "7d", 7 * 24 * time.Hour, false},
+			{"get trending window: month", "30d", 30 * 24 * time.Hour, false},
+			{"get trending window: unknown", "1y", 0, true},
+		}
+
+		for _, tt := range tests {
+			w, err := GetTrendingWindow(tt.name)
+
+			if tt.hasError {
+				assert.EqualError(t, err, "window must be one of 24h, 7d, 30d", tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+				assert.Equal(t, tt.expectedPeriod, w.Period, tt.title)
+				assert.Less(t, w.HalfLife, w.Period, tt.title)
+			}
+		}
+	})
+	t.Run("ViewerKey", func(t *testing.T) {
+		user := &User{ID: 42}
+
+		assert.Equal(t, "user:42", ViewerKey(user, "203.0.113.7"))
+
+		key := ViewerKey(nil, "203.0.113.7")
+		assert.True(t, strings.HasPrefix(key, "ip:"))
+		assert.NotContains(t, key, "203.0.113.7")
+		assert.LessOrEqual(t, len(key), 64)
+		assert.Equal(t, key, ViewerKey(nil, "203.0.113.7"))
+		assert.NotEqual(t, key, ViewerKey(nil, "203.0.113.8"))
+	})
+}
@@ -32,8 +32,12 @@ type User struct {
 	Name      string
 	Bio       string
 	Image     string
+	Private   bool
 	CreatedAt time.Time
 	UpdatedAt time.Time
+
+	// ImageVariants maps variant names of avatar to their urls
+	ImageVariants map[string]string
 }
 
 // Validate validates fields of user model
@@ -179,10 +183,43 @@ func (u *User) CheckPassword(plain string) bool {
 // ResponseProfile generates response message for user's profile
 func (u *User) ResponseProfile(following bool) message.ProfileResponse {
 	return message.ProfileResponse{
-		Username:  u.Username,
-		Name:      u.Name,
-		Bio:       u.Bio,
-		Image:     u.Image,
-		Following: following,
+		Username:      u.Username,
+		Name:          u.Name,
+		Bio:           u.Bio,
+		Image:         u.Image,
+		ImageVariants: u.ImageVariants,
+		Following:     following,
 	}
 }
+
+// ResponseCurrentUser generates response message for profile of current user with its settings
+func (u *User) ResponseCurrentUser() message.ProfileResponse {
+	resp := u.ResponseProfile(false)
+	resp.Private = &u.Private
+	return resp
+}
+
+// ProfileStats model counts followers, followed users and articles of a user
+type ProfileStats struct {
+	FollowersCount int64
+	FollowingCount int64
+	ArticlesCount  int64
+}
+
+// ResponseProfileOfViewer generates response message for user's profile as seen by the viewer,
+// with whether the viewer follows the user and whether the user follows the viewer
+func (u *User) ResponseProfileOfViewer(following, followedBy bool) message.ProfileResponse {
+	resp := u.ResponseProfile(following)
+	resp.FollowedBy = &followedBy
+	return resp
+}
+
+// ResponseProfileWithStats generates response message for user's profile as seen by the viewer with its stats
+func (u *User) ResponseProfileWithStats(following, followedBy bool, stats *ProfileStats) message.ProfileResponse {
+	resp := u.ResponseProfileOfViewer(following, followedBy)
+	resp.Private = &u.Private
+	resp.FollowersCount = &stats.FollowersCount
+	resp.FollowingCount = &stats.FollowingCount
+	resp.ArticlesCount = &stats.ArticlesCount
+	return resp
+}
@@ -507,4 +507,59 @@ func TestUnit_UserModel(t *testing.T) {
 		actual := user.ResponseProfile(false)
 		assert.Equal(t, expected, actual)
 	})
+	t.Run("ResponseProfileWithStats", func(t *testing.T) {
+		user := User{
+			ID:       1,
+			Username: "foo_user",
+			Name:     "FooUser",
+		}
+
+		following := true
+		followedBy := false
+		private := false
+		var followersCount, followingCount, articlesCount int64 = 2, 1, 3
+
+		expected := message.ProfileResponse{
+			Username:       "foo_user",
+			Name:           "FooUser",
+			Following:      following,
+			Private:        &private,
+			FollowedBy:     &followedBy,
+			FollowersCount: &followersCount,
+			FollowingCount: &followingCount,
+			ArticlesCount:  &articlesCount,
+		}
+
+		actual := user.ResponseProfileWithStats(true, false, &ProfileStats{
+			FollowersCount: 2,
+			FollowingCount: 1,
+			ArticlesCount:  3,
+		})
+		assert.Equal(t, expected, actual)
+
+		// stats are only shown on a single profile
+		actual = user.ResponseProfileOfViewer(true, false)
+		assert.Equal(t, &followedBy, actual.FollowedBy)
+		assert.Nil(t, actual.FollowersCount)
+		assert.Nil(t, actual.Private)
+	})
+
+	t.Run("ResponseCurrentUser", func(t *testing.T) {
+		user := User{
+			ID:       1,
+			Username: "foo_user",
+			Name:     "FooUser",
+			Private:  true,
+		}
+
+		private := true
+		expected := message.ProfileResponse{
+			Username: "foo_user",
+			Name:     "FooUser",
+			Private:  &private,
+		}
+
+		actual := user.ResponseCurrentUser()
+		assert.Equal(t, expected, actual)
+	})
 }
@@ -0,0 +1,348 @@
+package model
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation"
+	"github.com/go-ozzo/ozzo-validation/is"
+	"github.com/nathanbizkit/article-management-go/message"
+)
+
+// Webhook events
+const (
+	WebhookEventArticlePublished = "article.published"
+	WebhookEventArticleUpdated   = "article.updated"
+	WebhookEventArticleDeleted   = "article.deleted"
+	WebhookEventCommentCreated   = "comment.created"
+)
+
+// WebhookEvents lists all events a webhook can subscribe to
+var WebhookEvents = []string{
+	WebhookEventArticlePublished,
+	WebhookEventArticleUpdated,
+	WebhookEventArticleDeleted,
+	WebhookEventCommentCreated,
+}
+
+// webhookEventsOfDomainEvents maps domain events to the webhook events they are delivered as
+var webhookEventsOfDomainEvents = map[string]string{
+	DomainEventArticleCreated: WebhookEventArticlePublished,
+	DomainEventArticleUpdated: WebhookEventArticleUpdated,
+	DomainEventArticleDeleted: WebhookEventArticleDeleted,
+	DomainEventCommentCreated: WebhookEventCommentCreated,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+const (
+	webhookBackoffBase = 30 * time.Second
+	webhookBackoffMax  = 6 * time.Hour
+)
+
+var webhookURLScheme = regexp.MustCompile(`^https?://`)
+
+// nonPublicPrefixes are ranges of global unicast addresses which are not routed publicly either
+var nonPublicPrefixes = []netip.Prefix{
+	// "this network" (RFC 791)
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// carrier-grade NAT (RFC 6598)
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// IETF protocol assignments (RFC 6890)
+	netip.MustParsePrefix("192.0.0.0/24"),
+	// benchmarking (RFC 2544)
+	netip.MustParsePrefix("198.18.0.0/15"),
+	// reserved (RFC 1112)
+	netip.MustParsePrefix("240.0.0.0/4"),
+	// local-use NAT64 (RFC 8215)
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+}
+
+// nat64Prefix is the well-known NAT64 prefix (RFC 6052), whose addresses embed an ipv4 address
+var nat64Prefix = netip.MustParsePrefix("64:ff9b::/96")
+
+// Webhook model subscribes a url to events of articles of its user,
+// or of all articles when created by a moderator
+type Webhook struct {
+	ID          uint
+	UserID      uint
+	URL         string
+	Secret      string
+	Events      []string
+	AllArticles bool
+	Active      bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// NewWebhook returns a new active webhook of user with a random secret
+func NewWebhook(userID uint, url string, events []string, allArticles bool) (Webhook, error) {
+	b := make([]byte, 32)
+	_, err := rand.Read(b)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	return Webhook{
+		UserID:      userID,
+		URL:         url,
+		Secret:      hex.EncodeToString(b),
+		Events:      events,
+		AllArticles: allArticles,
+		Active:      true,
+	}, nil
+}
+
+// Validate validates fields of webhook model
+func (w Webhook) Validate() error {
+	events := make([]interface{}, 0, len(WebhookEvents))
+	for _, e := range WebhookEvents {
+		events = append(events, e)
+	}
+
+	return validation.ValidateStruct(&w,
+		validation.Field(
+			&w.UserID,
+			validation.Required,
+		),
+		validation.Field(
+			&w.URL,
+			validation.Required,
+			is.URL,
+			validation.Match(webhookURLScheme).Error("must be an http or https url"),
+			validation.By(validateWebhookHost),
+		),
+		validation.Field(
+			&w.Events,
+			validation.Required,
+			validation.Each(validation.In(events...)),
+		),
+	)
+}
+
+// Overwrite overwrites each field if it's not zero-value
+func (w *Webhook) Overwrite(url string, events []string, active *bool) {
+	if url != "" {
+		w.URL = url
+	}
+
+	if len(events) != 0 {
+		w.Events = events
+	}
+
+	if active != nil {
+		w.Active = *active
+	}
+}
+
+// Sign returns signature of payload sent at the unix timestamp, which is HMAC-SHA256 of
+// the timestamp and payload joined by a dot with webhook secret as key
+//
+// The timestamp is signed so that receivers can reject deliveries replayed later.
+func (w Webhook) Sign(timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// IsPublicAddr returns whether the address is publicly routable, which webhooks are only delivered to
+//
+// NAT64 addresses are as public as the ipv4 addresses they embed.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if nat64Prefix.Contains(addr) {
+		b := addr.As16()
+		return IsPublicAddr(netip.AddrFrom4([4]byte(b[12:])))
+	}
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateWebhookHost rejects urls of local hosts and non-public addresses,
+// hosts resolving to those are rejected when delivering
+func validateWebhookHost(value interface{}) error {
+	u, err := url.Parse(value.(string))
+	if err != nil {
+		return nil
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("must not be a local host")
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return errors.New("must not be a non-public address")
+	}
+
+	return nil
+}
+
+// ResponseWebhook generates response message from webhook, secret is only shown when created
+func (w *Webhook) ResponseWebhook(withSecret bool) message.WebhookResponse {
+	resp := message.WebhookResponse{
+		ID:          w.ID,
+		URL:         w.URL,
+		Events:      w.Events,
+		AllArticles: w.AllArticles,
+		Active:      w.Active,
+		CreatedAt:   w.CreatedAt.Format(time.RFC3339Nano),
+		UpdatedAt:   w.UpdatedAt.Format(time.RFC3339Nano),
+	}
+
+	if withSecret {
+		resp.Secret = w.Secret
+	}
+
+	return resp
+}
+
+// WebhookDelivery model is a payload queued to be posted to a webhook
+type WebhookDelivery struct {
+	ID               uint
+	WebhookID        uint
+	Event            string
+	Payload          []byte
+	Status           string
+	Attempts         int
+	NextAttemptAt    time.Time
+	LastResponseCode *int
+	DeliveredAt      *time.Time
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	// Webhook is only set on deliveries claimed to be attempted
+	Webhook Webhook
+	// AttemptLog is only set on a delivery got by id
+	AttemptLog []WebhookDeliveryAttempt
+}
+
+// WebhookBackoff returns how long to wait before retrying a delivery after its attempts failed,
+// which doubles on each attempt
+func WebhookBackoff(attempts int) time.Duration {
+	backoff := webhookBackoffBase
+	for i := 1; i < attempts && backoff < webhookBackoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookBackoffMax)
+}
+
+// RecordAttempt updates delivery after an attempt at time now which got response code (if any),
+// it succeeds on 2xx and is retried with backoff otherwise until max attempts
+func (d *WebhookDelivery) RecordAttempt(responseCode *int, now time.Time, maxAttempts int) {
+	d.Attempts++
+	d.LastResponseCode = responseCode
+
+	switch {
+	case responseCode != nil && *responseCode >= 200 && *responseCode < 300:
+		d.Status = WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= maxAttempts:
+		d.Status = WebhookDeliveryFailed
+	default:
+		d.Status = WebhookDeliveryPending
+		d.NextAttemptAt = now.Add(WebhookBackoff(d.Attempts))
+	}
+}
+
+// ResponseWebhookDelivery generates response message from webhook delivery,
+// payload and attempt log are only shown with details
+func (d *WebhookDelivery) ResponseWebhookDelivery(withDetails bool) message.WebhookDeliveryResponse {
+	resp := message.WebhookDeliveryResponse{
+		ID:               d.ID,
+		Event:            d.Event,
+		Status:           d.Status,
+		Attempts:         d.Attempts,
+		LastResponseCode: d.LastResponseCode,
+		CreatedAt:        d.CreatedAt.Format(time.RFC3339Nano),
+	}
+
+	if d.Status == WebhookDeliveryPending {
+		nextAttemptAt := d.NextAttemptAt.Format(time.RFC3339Nano)
+		resp.NextAttemptAt = &nextAttemptAt
+	}
+
+	if d.DeliveredAt != nil {
+		deliveredAt := d.DeliveredAt.Format(time.RFC3339Nano)
+		resp.DeliveredAt = &deliveredAt
+	}
+
+	if withDetails {
+		resp.Payload = json.RawMessage(d.Payload)
+		resp.AttemptLog = make([]message.WebhookDeliveryAttemptResponse, 0, len(d.AttemptLog))
+		for _, a := range d.AttemptLog {
+			resp.AttemptLog = append(resp.AttemptLog, a.ResponseWebhookDeliveryAttempt())
+		}
+	}
+
+	return resp
+}
+
+// WebhookDeliveryAttempt model logs an attempt to post a delivery
+type WebhookDeliveryAttempt struct {
+	ID           uint
+	DeliveryID   uint
+	ResponseCode *int
+	Error        string
+	Duration     time.Duration
+	CreatedAt    time.Time
+}
+
+// ResponseWebhookDeliveryAttempt generates response message from webhook delivery attempt
+func (a *WebhookDeliveryAttempt) ResponseWebhookDeliveryAttempt() message.WebhookDeliveryAttemptResponse {
+	return message.WebhookDeliveryAttemptResponse{
+		ResponseCode: a.ResponseCode,
+		Error:        a.Error,
+		DurationMS:   a.Duration.Milliseconds(),
+		CreatedAt:    a.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// NewWebhookPayload returns payload of the webhook event the domain event is delivered as,
+// or false if it is not delivered to webhooks
+func NewWebhookPayload(e *DomainEvent) (message.WebhookPayload, bool, error) {
+	event, exists := webhookEventsOfDomainEvents[e.Type]
+	if !exists || e.ArticleID == nil {
+		return message.WebhookPayload{}, false, nil
+	}
+
+	snapshot, err := e.DecodePayload()
+	if err != nil {
+		return message.WebhookPayload{}, false, err
+	}
+
+	return message.WebhookPayload{
+		Event:     event,
+		ArticleID: *e.ArticleID,
+		Article:   snapshot.Article,
+		Comment:   snapshot.Comment,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339Nano),
+	}, true, nil
+}
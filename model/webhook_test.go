@@ -0,0 +1,264 @@
+package model
+
+import (
+	"encoding/json"
+	"net/netip"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_WebhookModel(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	newWebhook := func(userID uint, url string, events []string, allArticles bool) Webhook {
+		t.Helper()
+
+		w, err := NewWebhook(userID, url, events, allArticles)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return w
+	}
+
+	t.Run("NewWebhook", func(t *testing.T) {
+		w, err := NewWebhook(7, "https://example.com/hook", []string{WebhookEventArticlePublished}, true)
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint(7), w.UserID)
+		assert.Equal(t, "https://example.com/hook", w.URL)
+		assert.Equal(t, []string{WebhookEventArticlePublished}, w.Events)
+		assert.True(t, w.AllArticles)
+		assert.True(t, w.Active)
+		assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{64}$`), w.Secret)
+
+		other := newWebhook(7, "https://example.com/hook", []string{WebhookEventArticlePublished}, true)
+		assert.NotEqual(t, w.Secret, other.Secret)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			title    string
+			webhook  Webhook
+			hasError bool
+		}{
+			{
+				"validate webhook: success",
+				newWebhook(1, "https://example.com/hook", WebhookEvents, false),
+				false,
+			},
+			{
+				"validate webhook: no user id",
+				newWebhook(0, "https://example.com/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: no url",
+				newWebhook(1, "", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: invalid url",
+				newWebhook(1, "not a url", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: url without http scheme",
+				newWebhook(1, "ftp://example.com/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: localhost",
+				newWebhook(1, "http://localhost:8080/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: loopback address",
+				newWebhook(1, "http://127.0.0.1/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: private address",
+				newWebhook(1, "http://10.0.0.5/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: link-local address",
+				newWebhook(1, "http://169.254.169.254/latest/meta-data", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: ipv6 loopback address",
+				newWebhook(1, "http://[::1]/hook", WebhookEvents, false),
+				true,
+			},
+			{
+				"validate webhook: public address",
+				newWebhook(1, "http://93.184.216.34/hook", WebhookEvents, false),
+				false,
+			},
+			{
+				"validate webhook: no events",
+				newWebhook(1, "https://example.com/hook", []string{}, false),
+				true,
+			},
+			{
+				"validate webhook: unknown event",
+				newWebhook(1, "https://example.com/hook", []string{"article.liked"}, false),
+				true,
+			},
+		}
+
+		for _, tt := range tests {
+			err := tt.webhook.Validate()
+
+			if tt.hasError {
+				assert.Error(t, err, tt.title)
+			} else {
+				assert.NoError(t, err, tt.title)
+			}
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		w := newWebhook(1, "https://example.com/hook", WebhookEvents, false)
+
+		w.Overwrite("", nil, nil)
+		assert.Equal(t, "https://example.com/hook", w.URL)
+		assert.Equal(t, WebhookEvents, w.Events)
+		assert.True(t, w.Active)
+
+		active := false
+		w.Overwrite("https://example.com/other", []string{WebhookEventCommentCreated}, &active)
+		assert.Equal(t, "https://example.com/other", w.URL)
+		assert.Equal(t, []string{WebhookEventCommentCreated}, w.Events)
+		assert.False(t, w.Active)
+	})
+
+	t.Run("Sign", func(t *testing.T) {
+		w := Webhook{Secret: "secret"}
+
+		// echo -n '1700000000.{"event":"article.published"}' | openssl dgst -sha256 -hmac secret
+		expected := "sha256=232e72872fb999d09181a95b88968a619c1b47fdc1471ec8d41f231661e4b46c"
+		actual := w.Sign(1700000000, []byte(`{"event":"article.published"}`))
+		assert.Regexp(t, regexp.MustCompile(`^sha256=[0-9a-f]{64}$`), actual)
+		assert.Equal(t, expected, actual)
+
+		other := Webhook{Secret: "other"}
+		assert.NotEqual(t, actual, other.Sign(1700000000, []byte(`{"event":"article.published"}`)))
+		assert.NotEqual(t, actual, w.Sign(1700000001, []byte(`{"event":"article.published"}`)))
+	})
+
+	t.Run("IsPublicAddr", func(t *testing.T) {
+		for addr, expected := range map[string]bool{
+			"93.184.216.34":        true,
+			"2606:4700::1111":      true,
+			"127.0.0.1":            false,
+			"10.1.2.3":             false,
+			"172.16.0.1":           false,
+			"192.168.1.1":          false,
+			"169.254.169.254":      false,
+			"100.64.0.1":           false,
+			"0.0.0.0":              false,
+			"0.1.2.3":              false,
+			"192.0.0.8":            false,
+			"198.18.0.1":           false,
+			"198.19.255.255":       false,
+			"240.0.0.1":            false,
+			"64:ff9b::a01:203":     false,
+			"64:ff9b::7f00:1":      false,
+			"64:ff9b::5db8:d822":   true,
+			"64:ff9b:1::1":         false,
+			"::1":                  false,
+			"fe80::1":              false,
+			"fd00::1":              false,
+			"::ffff:127.0.0.1":     false,
+			"::ffff:93.184.216.34": true,
+		} {
+			assert.Equal(t, expected, IsPublicAddr(netip.MustParseAddr(addr)), addr)
+		}
+	})
+
+	t.Run("WebhookBackoff", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, WebhookBackoff(1))
+		assert.Equal(t, time.Minute, WebhookBackoff(2))
+		assert.Equal(t, 4*time.Minute, WebhookBackoff(4))
+		assert.Equal(t, 6*time.Hour, WebhookBackoff(20))
+	})
+
+	t.Run("RecordAttempt", func(t *testing.T) {
+		now := time.Now()
+		ok := 204
+		serverError := 500
+
+		d := WebhookDelivery{Status: WebhookDeliveryPending}
+
+		d.RecordAttempt(&serverError, now, 3)
+		assert.Equal(t, WebhookDeliveryPending, d.Status)
+		assert.Equal(t, 1, d.Attempts)
+		assert.Equal(t, &serverError, d.LastResponseCode)
+		assert.Equal(t, now.Add(30*time.Second), d.NextAttemptAt)
+
+		// no response (e.g. timeout)
+		d.RecordAttempt(nil, now, 3)
+		assert.Equal(t, WebhookDeliveryPending, d.Status)
+		assert.Equal(t, now.Add(time.Minute), d.NextAttemptAt)
+		assert.Nil(t, d.LastResponseCode)
+
+		d.RecordAttempt(&serverError, now, 3)
+		assert.Equal(t, WebhookDeliveryFailed, d.Status)
+		assert.Equal(t, 3, d.Attempts)
+		assert.Nil(t, d.DeliveredAt)
+
+		d = WebhookDelivery{Status: WebhookDeliveryPending}
+		d.RecordAttempt(&ok, now, 3)
+		assert.Equal(t, WebhookDeliverySucceeded, d.Status)
+		assert.Equal(t, &now, d.DeliveredAt)
+	})
+
+	t.Run("ResponseWebhookDelivery", func(t *testing.T) {
+		now := time.Now()
+		code := 500
+
+		d := WebhookDelivery{
+			ID:               1,
+			WebhookID:        2,
+			Event:            WebhookEventCommentCreated,
+			Payload:          []byte(`{"event":"comment.created"}`),
+			Status:           WebhookDeliveryPending,
+			Attempts:         1,
+			NextAttemptAt:    now,
+			LastResponseCode: &code,
+			CreatedAt:        now,
+			AttemptLog: []WebhookDeliveryAttempt{
+				{ID: 1, DeliveryID: 1, ResponseCode: &code, Duration: 1500 * time.Millisecond, CreatedAt: now},
+			},
+		}
+
+		nowString := now.Format(time.RFC3339Nano)
+		expected := message.WebhookDeliveryResponse{
+			ID:               1,
+			Event:            WebhookEventCommentCreated,
+			Status:           WebhookDeliveryPending,
+			Attempts:         1,
+			LastResponseCode: &code,
+			NextAttemptAt:    &nowString,
+			CreatedAt:        nowString,
+		}
+		assert.Equal(t, expected, d.ResponseWebhookDelivery(false))
+
+		expected.Payload = json.RawMessage(`{"event":"comment.created"}`)
+		expected.AttemptLog = []message.WebhookDeliveryAttemptResponse{
+			{ResponseCode: &code, DurationMS: 1500, CreatedAt: nowString},
+		}
+		assert.Equal(t, expected, d.ResponseWebhookDelivery(true))
+
+		d.Status = WebhookDeliveryFailed
+		assert.Nil(t, d.ResponseWebhookDelivery(false).NextAttemptAt)
+	})
+}
@@ -0,0 +1,121 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultPollInterval is how often new events are relayed
+	DefaultPollInterval = time.Second
+	// DefaultBatchSize is the number of events a consumer consumes in a transaction
+	DefaultBatchSize = 100
+	// DefaultRetention is how long events are kept after all consumers consumed them
+	DefaultRetention = 7 * 24 * time.Hour
+)
+
+// Consumer names, which their checkpoints are kept by
+const (
+	ConsumerNotifications = "notifications"
+	ConsumerWebhooks      = "webhooks"
+	ConsumerTrending      = "trending"
+)
+
+// Consumer consumes a domain event, its changes made in tx are committed along with its checkpoint
+type Consumer func(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error
+
+type subscription struct {
+	name     string
+	consumer Consumer
+}
+
+// Relay relays domain events written to outbox to consumers in the background
+//
+// Each consumer has its own checkpoint, so a consumer which keeps failing
+// on an event holds back only itself, retrying the event every poll.
+type Relay struct {
+	logger        *zerolog.Logger
+	obs           *store.OutboxStore
+	retention     time.Duration
+	subscriptions []subscription
+}
+
+// NewRelay returns a new relay with logger, outbox store and retention of consumed events
+func NewRelay(l *zerolog.Logger, obs *store.OutboxStore, retention time.Duration) *Relay {
+	return &Relay{
+		logger:    l,
+		obs:       obs,
+		retention: retention,
+	}
+}
+
+// Subscribe subscribes a consumer by name, which must be done before relaying
+//
+// A consumer subscribed for the first time consumes all events kept in outbox.
+func (r *Relay) Subscribe(name string, consumer Consumer) {
+	r.subscriptions = append(r.subscriptions, subscription{name: name, consumer: consumer})
+}
+
+// Run relays new events every poll interval, and deletes old consumed events hourly until ctx is done
+func (r *Relay) Run(ctx context.Context, pollInterval time.Duration) {
+	r.logger.Info().Int("consumers", len(r.subscriptions)).Msg("starting outbox relay...")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := r.RelayAll(ctx)
+			if err != nil {
+				r.logger.Error().Err(err).Msg("failed to relay outbox events")
+			}
+		case <-cleanup.C:
+			names := make([]string, 0, len(r.subscriptions))
+			for _, s := range r.subscriptions {
+				names = append(names, s.name)
+			}
+
+			deleted, err := r.obs.DeleteConsumedEventsBefore(ctx, names, time.Now().Add(-r.retention))
+			if err != nil {
+				r.logger.Error().Err(err).Msg("failed to delete consumed outbox events")
+				continue
+			}
+
+			r.logger.Info().Int64("deleted", deleted).Msg("deleted consumed outbox events")
+		}
+	}
+}
+
+// RelayAll relays all events which are not consumed yet to every consumer
+func (r *Relay) RelayAll(ctx context.Context) error {
+	var errs []error
+
+	for _, s := range r.subscriptions {
+		for ctx.Err() == nil {
+			consumed, err := r.obs.ConsumeEvents(ctx, s.name, DefaultBatchSize, s.consumer)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("consumer %s: %w", s.name, err))
+				break
+			}
+
+			if consumed < DefaultBatchSize {
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,142 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_Relay(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	l := test.NewTestLogger(t)
+	lct := test.NewLocalTestContainer(t)
+
+	us := store.NewUserStore(lct.DB())
+	ns := store.NewNotificationStore(lct.DB())
+	obs := store.NewOutboxStore(lct.DB())
+
+	createUser := func(t *testing.T) *model.User {
+		t.Helper()
+
+		randStr := test.RandomString(t, 10)
+		user, err := us.Create(context.Background(), &model.User{
+			Username: fmt.Sprintf("user_%s", randStr),
+			Email:    fmt.Sprintf("%s@example.com", randStr),
+			Password: "P@55w0rD!",
+			Name:     "USER",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			_, err := lct.DB().Exec(`DELETE FROM article_management.users WHERE id = $1`, user.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		return user
+	}
+
+	countNotifications := func(t *testing.T, user *model.User) int64 {
+		t.Helper()
+
+		_, count, err := ns.GetNotifications(context.Background(), user, false, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return count
+	}
+
+	t.Run("RelayAll: consumed exactly once", func(t *testing.T) {
+		fooUser := createUser(t)
+		barUser := createUser(t)
+
+		err := us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		relay := NewRelay(&l, obs, DefaultRetention)
+		relay.Subscribe(ConsumerNotifications, ns.ConsumeEvent)
+
+		err = relay.RelayAll(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), countNotifications(t, fooUser))
+
+		// consumed events are not consumed again
+		err = relay.RelayAll(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), countNotifications(t, fooUser))
+	})
+
+	t.Run("RelayAll: failed event is consumed again", func(t *testing.T) {
+		fooUser := createUser(t)
+		barUser := createUser(t)
+
+		consumer := fmt.Sprintf("test_%s", test.RandomString(t, 10))
+		t.Cleanup(func() {
+			_, err := lct.DB().Exec(`DELETE FROM article_management.outbox_checkpoints WHERE consumer = $1`, consumer)
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		// catch the new consumer up with events of other tests
+		relay := NewRelay(&l, obs, DefaultRetention)
+		relay.Subscribe(consumer, func(*sql.Tx, context.Context, *model.DomainEvent) error { return nil })
+
+		err := relay.RelayAll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = us.Follow(context.Background(), barUser, fooUser)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		failing := true
+		consumed := 0
+
+		relay = NewRelay(&l, obs, DefaultRetention)
+		relay.Subscribe(consumer, func(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error {
+			if e.Type != model.DomainEventUserFollowed || e.ActorID != barUser.ID {
+				return nil
+			}
+
+			if failing {
+				return errors.New("failed")
+			}
+
+			consumed++
+			return nil
+		})
+
+		err = relay.RelayAll(context.Background())
+		assert.Error(t, err)
+		assert.Equal(t, 0, consumed)
+
+		failing = false
+
+		err = relay.RelayAll(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, consumed)
+
+		err = relay.RelayAll(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, consumed)
+	})
+}
@@ -0,0 +1,198 @@
+package realtime
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultBufferSize is the number of events waiting to be sent to a subscriber before it is dropped
+	DefaultBufferSize = 32
+	// MaxReplayEvents is the number of missed events replayed to a reconnecting subscriber
+	MaxReplayEvents = 100
+
+	publishBatchSize = 100
+	pollInterval     = time.Second
+	pingInterval     = 90 * time.Second
+	cleanupInterval  = time.Hour
+)
+
+// Subscription receives events pushed to a user viewing an article (if any)
+//
+// Events is closed when subscriber falls behind, which is expected to
+// reconnect and replay what it missed.
+type Subscription struct {
+	UserID    uint
+	ArticleID *uint
+	Events    <-chan model.StreamEvent
+	events    chan model.StreamEvent
+}
+
+// Hub fans out stream events to subscriptions of this replica
+//
+// Events are written to database by triggers and notified on postgres channel,
+// so that every replica listening to it pushes them to its own subscriptions.
+// They are pushed in order of transactions which wrote them rather than in order
+// of ids, which are not committed in order.
+type Hub struct {
+	logger        *zerolog.Logger
+	ss            *store.StreamStore
+	retention     time.Duration
+	bufferSize    int
+	mu            sync.Mutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewHub returns a new hub with logger, stream store, how long events are kept for replay and buffer size of subscriptions
+func NewHub(l *zerolog.Logger, ss *store.StreamStore, retention time.Duration, bufferSize int) *Hub {
+	return &Hub{
+		logger:        l,
+		ss:            ss,
+		retention:     retention,
+		bufferSize:    bufferSize,
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe subscribes to events pushed to the user viewing an article (if any)
+func (h *Hub) Subscribe(userID uint, articleID *uint) *Subscription {
+	events := make(chan model.StreamEvent, h.bufferSize)
+	sub := &Subscription{
+		UserID:    userID,
+		ArticleID: articleID,
+		Events:    events,
+		events:    events,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscriptions[sub] = struct{}{}
+	return sub
+}
+
+// Unsubscribe stops pushing events to a subscription, unsubscribing again does nothing
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.remove(sub)
+}
+
+// Publish pushes an event to matching subscriptions without blocking,
+// subscriptions which are full are dropped
+func (h *Hub) Publish(e model.StreamEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscriptions {
+		if !e.Matches(sub.UserID, sub.ArticleID) {
+			continue
+		}
+
+		select {
+		case sub.events <- e:
+		default:
+			h.logger.Warn().Uint("user_id", sub.UserID).Msg("stream subscription is full, dropping subscription")
+			h.remove(sub)
+		}
+	}
+}
+
+// Replay gets events after the id which were pushed to a subscription, in order they were pushed
+func (h *Hub) Replay(ctx context.Context, sub *Subscription, lastID uint) ([]model.StreamEvent, error) {
+	return h.ss.GetUserEventsAfter(ctx, lastID, sub.UserID, sub.ArticleID, MaxReplayEvents)
+}
+
+// Run listens to new events and publishes them until ctx is done,
+// and deletes events older than retention periodically
+func (h *Hub) Run(ctx context.Context, listener *pq.Listener) {
+	h.logger.Info().Msg("starting stream hub...")
+	defer listener.Close()
+
+	err := listener.Listen(store.StreamChannel)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to listen to stream events")
+		return
+	}
+
+	lastTxID, lastID, err := h.ss.GetLastPosition(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to get last stream event")
+		return
+	}
+
+	// events notified while a transaction started before theirs is still running
+	// are held back, and are published once it finishes even if it notifies nothing
+	poll := time.NewTicker(pollInterval)
+	defer poll.Stop()
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+			// notifications only tell there are new events, which are published in order,
+			// and a nil one tells connection was reestablished with notifications in between gone
+			lastTxID, lastID = h.publishAfter(ctx, lastTxID, lastID)
+		case <-poll.C:
+			lastTxID, lastID = h.publishAfter(ctx, lastTxID, lastID)
+		case <-ping.C:
+			err := listener.Ping()
+			if err != nil {
+				h.logger.Error().Err(err).Msg("failed to ping stream listener")
+			}
+		case <-cleanup.C:
+			deleted, err := h.ss.DeleteEventsBefore(ctx, time.Now().Add(-h.retention))
+			if err != nil {
+				h.logger.Error().Err(err).Msg("failed to delete old stream events")
+				continue
+			}
+
+			h.logger.Info().Int64("deleted", deleted).Msg("deleted old stream events")
+		}
+	}
+}
+
+// publishAfter publishes events after the position of tx id and id, and returns position of the last one
+func (h *Hub) publishAfter(ctx context.Context, lastTxID string, lastID uint) (string, uint) {
+	for {
+		events, txID, err := h.ss.GetEventsAfter(ctx, lastTxID, lastID, publishBatchSize)
+		if err != nil {
+			h.logger.Error().Err(err).Msg("failed to get new stream events")
+			return lastTxID, lastID
+		}
+
+		for _, e := range events {
+			h.Publish(e)
+			lastID = e.ID
+		}
+		lastTxID = txID
+
+		if len(events) < publishBatchSize {
+			return lastTxID, lastID
+		}
+	}
+}
+
+// remove drops a subscription and closes its events, must be called with lock held
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subscriptions[sub]; !ok {
+		return
+	}
+
+	delete(h.subscriptions, sub)
+	close(sub.events)
+}
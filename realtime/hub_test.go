@@ -0,0 +1,88 @@
+package realtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Hub(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	l := test.NewTestLogger(t)
+
+	fooUserID := uint(1)
+	barUserID := uint(2)
+	fooArticleID := uint(10)
+
+	notification := model.StreamEvent{ID: 1, Type: model.StreamEventNotification, UserID: &fooUserID, TargetID: 1}
+	comment := model.StreamEvent{ID: 2, Type: model.StreamEventComment, ArticleID: &fooArticleID, TargetID: 1}
+
+	t.Run("Publish", func(t *testing.T) {
+		hub := NewHub(&l, nil, time.Hour, DefaultBufferSize)
+
+		fooSub := hub.Subscribe(fooUserID, nil)
+		barSub := hub.Subscribe(barUserID, &fooArticleID)
+
+		hub.Publish(notification)
+		hub.Publish(comment)
+
+		assert.Equal(t, []model.StreamEvent{notification}, drain(fooSub))
+		assert.Equal(t, []model.StreamEvent{comment}, drain(barSub))
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		hub := NewHub(&l, nil, time.Hour, DefaultBufferSize)
+
+		fooSub := hub.Subscribe(fooUserID, nil)
+		hub.Unsubscribe(fooSub)
+		hub.Unsubscribe(fooSub)
+
+		hub.Publish(notification)
+
+		_, ok := <-fooSub.Events
+		assert.False(t, ok)
+	})
+
+	t.Run("Publish: full subscription is dropped", func(t *testing.T) {
+		hub := NewHub(&l, nil, time.Hour, 1)
+
+		fooSub := hub.Subscribe(fooUserID, nil)
+		barSub := hub.Subscribe(barUserID, &fooArticleID)
+
+		hub.Publish(notification)
+		hub.Publish(notification)
+		hub.Publish(comment)
+
+		assert.Equal(t, []model.StreamEvent{notification}, drain(fooSub))
+
+		_, ok := <-fooSub.Events
+		assert.False(t, ok)
+
+		assert.Equal(t, []model.StreamEvent{comment}, drain(barSub))
+
+		// dropped subscription can still be unsubscribed
+		hub.Unsubscribe(fooSub)
+	})
+}
+
+// drain returns events buffered in subscription without blocking
+func drain(sub *Subscription) []model.StreamEvent {
+	events := []model.StreamEvent{}
+	for {
+		select {
+		case e, ok := <-sub.Events:
+			if !ok {
+				return events
+			}
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
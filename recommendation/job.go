@@ -0,0 +1,51 @@
+package recommendation
+
+import (
+	"context"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+// DefaultInterval is how often recommendations are recomputed
+const DefaultInterval = 15 * time.Minute
+
+// Job refreshes related articles and suggested follows in the background
+type Job struct {
+	logger *zerolog.Logger
+	rs     *store.RecommendationStore
+}
+
+// NewJob returns a new recommendation job with logger and recommendation store
+func NewJob(l *zerolog.Logger, rs *store.RecommendationStore) *Job {
+	return &Job{
+		logger: l,
+		rs:     rs,
+	}
+}
+
+// Run refreshes recommendations right away and then every interval until ctx is done
+func (j *Job) Run(ctx context.Context, interval time.Duration) {
+	j.logger.Info().Msg("starting recommendation job...")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+
+		err := j.rs.Refresh(ctx)
+		if err != nil {
+			j.logger.Error().Err(err).Msg("failed to refresh recommendations")
+		} else {
+			j.logger.Info().Dur("took", time.Since(start)).Msg("refreshed recommendations")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
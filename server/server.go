@@ -11,13 +11,23 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/nanmu42/gzip"
 	"github.com/nathanbizkit/article-management-go/auth"
 	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/digest"
 	"github.com/nathanbizkit/article-management-go/env"
 	"github.com/nathanbizkit/article-management-go/handler"
+	"github.com/nathanbizkit/article-management-go/imaging"
+	"github.com/nathanbizkit/article-management-go/mail"
 	"github.com/nathanbizkit/article-management-go/middleware"
+	"github.com/nathanbizkit/article-management-go/outbox"
+	"github.com/nathanbizkit/article-management-go/realtime"
+	"github.com/nathanbizkit/article-management-go/recommendation"
+	"github.com/nathanbizkit/article-management-go/storage"
 	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/nathanbizkit/article-management-go/trending"
+	"github.com/nathanbizkit/article-management-go/webhook"
 	"github.com/rs/zerolog"
 )
 
@@ -62,16 +72,63 @@ func Start() {
 		router.Use(middleware.Secure(environ))
 	}
 
+	st, err := storage.New(environ)
+	if err != nil {
+		l.Fatal().Err(err).Msg("failed to set up media storage")
+	}
+
+	l.Info().Str("driver", environ.StorageDriver).Msg("succeeded to set up media storage")
+
+	mailer, err := mail.New(environ, &l)
+	if err != nil {
+		l.Fatal().Err(err).Msg("failed to set up mailer")
+	}
+
+	l.Info().Str("driver", environ.MailDriver).Msg("succeeded to set up mailer")
+
 	authen := auth.New(environ)
 	us := store.NewUserStore(dbPool)
 	as := store.NewArticleStore(dbPool)
-	h := handler.New(&l, environ, authen, us, as)
+	ms := store.NewMediaStore(dbPool)
+	ns := store.NewNotificationStore(dbPool)
+	ws := store.NewWebhookStore(dbPool)
+	ds := store.NewDigestStore(dbPool)
+	rs := store.NewRecommendationStore(dbPool)
+	ts := store.NewTrendingStore(dbPool)
+	ss := store.NewStreamStore(dbPool)
+	obs := store.NewOutboxStore(dbPool)
+	ip := imaging.NewProcessor(&l, ms, st, imaging.DefaultQueueSize)
+	hub := realtime.NewHub(&l, ss, environ.StreamRetention, realtime.DefaultBufferSize)
+	wd := webhook.NewDispatcher(&l, ws, environ.WebhookTimeout, environ.WebhookMaxAttempts)
+	dj := digest.NewJob(&l, ds, us, as, mailer, environ.AppBaseURL)
+	rj := recommendation.NewJob(&l, rs)
+	tj := trending.NewJob(&l, ts)
+	relay := outbox.NewRelay(&l, obs, outbox.DefaultRetention)
+	relay.Subscribe(outbox.ConsumerNotifications, ns.ConsumeEvent)
+	relay.Subscribe(outbox.ConsumerWebhooks, ws.ConsumeEvent)
+	relay.Subscribe(outbox.ConsumerTrending, ts.ConsumeEvent)
+	h := handler.New(&l, environ, authen, us, as, ms, ns, ws, ds, rs, ts, st, ip, hub)
 
 	handler.LinkRouter(router, h)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	go ip.Run(ctx, imaging.DefaultWorkers)
+	go wd.Run(ctx, webhook.DefaultWorkers, webhook.DefaultPollInterval)
+	go relay.Run(ctx, outbox.DefaultPollInterval)
+	go dj.Run(ctx, digest.DefaultInterval)
+	go rj.Run(ctx, recommendation.DefaultInterval)
+	go tj.Run(ctx, trending.DefaultInterval)
+
+	listener := db.NewListener(environ, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			l.Error().Err(err).Msg("stream listener connection event")
+		}
+	})
+
+	go hub.Run(ctx, listener)
+
 	l.Info().Str("port", environ.AppPort).Msg("starting server...")
 
 	go func() {
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nathanbizkit/article-management-go/env"
+)
+
+// ErrNotFound is returned when an object does not exist in storage
+var ErrNotFound = errors.New("object not found")
+
+// Storage is a blob store of uploaded media objects addressed by key
+type Storage interface {
+	// Put stores an object of size bytes read from r under key
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens an object stored under key, which must be closed by caller
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object stored under key, removing a missing object is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns a storage of driver configured in env
+func New(environ *env.ENV) (Storage, error) {
+	switch environ.StorageDriver {
+	case "local":
+		return NewLocalStorage(environ.StorageLocalDir)
+	case "s3":
+		return NewS3Storage(
+			environ.S3Endpoint,
+			environ.S3Region,
+			environ.S3AccessKey,
+			environ.S3SecretKey,
+			environ.S3Bucket,
+			environ.S3UseSSL,
+		)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", environ.StorageDriver)
+	}
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnit_Storage(t *testing.T) {
+	if !testing.Short() {
+		t.Skip("skipping unit tests.")
+	}
+
+	local, err := NewLocalStorage(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoint := newFakeS3(t)
+	s3, err := NewS3Storage(endpoint, "us-east-1", "access", "secret", "media", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	storages := []struct {
+		name    string
+		storage Storage
+	}{
+		{"local", local},
+		{"s3", s3},
+	}
+
+	for _, st := range storages {
+		t.Run(st.name, func(t *testing.T) {
+			ctx := context.Background()
+			content := []byte("hello, world")
+
+			err := st.storage.Put(ctx, "media/1/hello.txt", bytes.NewReader(content), int64(len(content)), "text/plain")
+			assert.NoError(t, err, "put: success")
+
+			r, err := st.storage.Get(ctx, "media/1/hello.txt")
+			assert.NoError(t, err, "get: success")
+
+			if err == nil {
+				actual, err := io.ReadAll(r)
+				assert.NoError(t, err, "get: read content")
+				assert.Equal(t, content, actual, "get: read content")
+				r.Close()
+			}
+
+			_, err = st.storage.Get(ctx, "media/1/missing.txt")
+			assert.ErrorIs(t, err, ErrNotFound, "get: missing object")
+
+			err = st.storage.Delete(ctx, "media/1/hello.txt")
+			assert.NoError(t, err, "delete: success")
+
+			_, err = st.storage.Get(ctx, "media/1/hello.txt")
+			assert.ErrorIs(t, err, ErrNotFound, "get: deleted object")
+
+			err = st.storage.Delete(ctx, "media/1/hello.txt")
+			assert.NoError(t, err, "delete: missing object")
+		})
+	}
+
+	t.Run("local: size mismatch", func(t *testing.T) {
+		err := local.Put(context.Background(), "media/short.txt", strings.NewReader("abc"), 10, "text/plain")
+		assert.Error(t, err)
+
+		_, err = local.Get(context.Background(), "media/short.txt")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("local: invalid keys", func(t *testing.T) {
+		for _, key := range []string{"", "/", "../escape", "media/../../escape"} {
+			_, err := local.Get(context.Background(), key)
+			assert.Error(t, err, key)
+			assert.NotErrorIs(t, err, ErrNotFound, key)
+		}
+	})
+}
+
+// newFakeS3 starts a minimal in-memory S3-compatible server and returns its endpoint
+//
+// Requests are not authenticated, and only path-style bucket and object
+// operations used by S3Storage are supported.
+func newFakeS3(t *testing.T) string {
+	t.Helper()
+
+	var mu sync.Mutex
+	buckets := map[string]bool{}
+	objects := map[string][]byte{}
+	contentTypes := map[string]string{}
+
+	notFound := func(w http.ResponseWriter, r *http.Request, code string) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusNotFound)
+		if r.Method != http.MethodHead {
+			io.WriteString(w, "<Error><Code>"+code+"</Code><Message>not found</Message></Error>")
+		}
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		path, _ := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/"))
+		bucket, key, _ := strings.Cut(path, "/")
+
+		if key == "" {
+			switch r.Method {
+			case http.MethodHead:
+				if !buckets[bucket] {
+					notFound(w, r, "NoSuchBucket")
+					return
+				}
+			case http.MethodPut:
+				buckets[bucket] = true
+			default:
+				w.WriteHeader(http.StatusNotImplemented)
+			}
+			return
+		}
+
+		if !buckets[bucket] {
+			notFound(w, r, "NoSuchBucket")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPut:
+			body, err := readS3Body(r)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			sum := md5.Sum(body)
+			objects[path] = body
+			contentTypes[path] = r.Header.Get("Content-Type")
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+		case http.MethodHead, http.MethodGet:
+			body, exists := objects[path]
+			if !exists {
+				notFound(w, r, "NoSuchKey")
+				return
+			}
+
+			sum := md5.Sum(body)
+			w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+			w.Header().Set("Content-Type", contentTypes[path])
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+
+			if r.Method == http.MethodGet {
+				w.Write(body)
+			}
+		case http.MethodDelete:
+			delete(objects, path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// readS3Body reads an uploaded object, decoding aws-chunked streaming payloads
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var body bytes.Buffer
+	br := bufio.NewReader(r.Body)
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			// trailing headers are not needed
+			return body.Bytes(), nil
+		}
+
+		_, err = io.CopyN(&body, br, size)
+		if err != nil {
+			return nil, err
+		}
+
+		// chunk data is terminated by CRLF
+		_, err = br.Discard(2)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
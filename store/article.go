@@ -6,6 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -30,10 +31,10 @@ func (s *ArticleStore) GetByID(ctx context.Context, id uint) (*model.Article, er
 	var author model.User
 
 	queryString := `SELECT 
-		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, a.created_at, a.updated_at, 
+		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, cc.comments_count, a.created_at, a.updated_at, 
 		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
 		FROM article_management.articles a 
-		INNER JOIN article_management.users u ON u.id = a.user_id 
+		INNER JOIN article_management.users u ON u.id = a.user_id ` + commentsCountJoin + ` 
 		WHERE a.id = $1`
 	err := s.db.QueryRowContext(ctx, queryString, id).
 		Scan(
@@ -43,6 +44,7 @@ func (s *ArticleStore) GetByID(ctx context.Context, id uint) (*model.Article, er
 			&article.Body,
 			&article.UserID,
 			&article.FavoritesCount,
+			&article.CommentsCount,
 			&article.CreatedAt,
 			&article.UpdatedAt,
 
@@ -74,7 +76,8 @@ func (s *ArticleStore) GetByID(ctx context.Context, id uint) (*model.Article, er
 	return &article, nil
 }
 
-// Create creates an article and returns the newly created article
+// Create creates an article with its tags and mentioned users and returns the newly created article,
+// newly mentioned users are notified
 func (s *ArticleStore) Create(ctx context.Context, m *model.Article) (*model.Article, error) {
 	var article model.Article
 
@@ -184,13 +187,24 @@ func (s *ArticleStore) Create(ctx context.Context, m *model.Article) (*model.Art
 			article.Tags = tags
 		}
 
-		return nil
+		err = setMentions(tx, ctx, articleMentionTable, article.ID, article.UserID, article.ID, nil, m.Mentions)
+		if err != nil {
+			return err
+		}
+
+		e, err := model.NewArticleEvent(model.DomainEventArticleCreated, &article)
+		if err != nil {
+			return err
+		}
+
+		return writeEvent(tx, ctx, &e)
 	})
 
 	return &article, err
 }
 
-// Update updates an article (for title, description, body)
+// Update updates an article (for title, description, body) and replaces its mentioned users,
+// newly mentioned users are notified
 func (s *ArticleStore) Update(ctx context.Context, m *model.Article) (*model.Article, error) {
 	var article model.Article
 
@@ -198,7 +212,9 @@ func (s *ArticleStore) Update(ctx context.Context, m *model.Article) (*model.Art
 		queryString := `UPDATE article_management.articles 
 			SET title = $1, description = $2, body = $3, updated_at = DEFAULT 
 			WHERE id = $4 
-			RETURNING id, title, description, body, user_id, favorites_count, created_at, updated_at`
+			RETURNING id, title, description, body, user_id, favorites_count, 
+			(SELECT COUNT(c.id) FROM article_management.comments c WHERE c.article_id = articles.id AND c.deleted_at IS NULL), 
+			created_at, updated_at`
 		err := tx.QueryRowContext(ctx, queryString, m.Title, m.Description, m.Body, m.ID).
 			Scan(
 				&article.ID,
@@ -207,6 +223,7 @@ func (s *ArticleStore) Update(ctx context.Context, m *model.Article) (*model.Art
 				&article.Body,
 				&article.UserID,
 				&article.FavoritesCount,
+				&article.CommentsCount,
 				&article.CreatedAt,
 				&article.UpdatedAt,
 			)
@@ -230,98 +247,241 @@ func (s *ArticleStore) Update(ctx context.Context, m *model.Article) (*model.Art
 		}
 
 		article.Tags = tags
-		return nil
+
+		err = setMentions(tx, ctx, articleMentionTable, article.ID, article.UserID, article.ID, nil, m.Mentions)
+		if err != nil {
+			return err
+		}
+
+		e, err := model.NewArticleEvent(model.DomainEventArticleUpdated, &article)
+		if err != nil {
+			return err
+		}
+
+		return writeEvent(tx, ctx, &e)
 	})
 
 	return &article, err
 }
 
-// GetArticles gets global articles
-func (s *ArticleStore) GetArticles(ctx context.Context, tagName, username string, favoritedBy *model.User, limit, offset int64) ([]model.Article, error) {
-	var q bytes.Buffer
-	q.WriteString(`SELECT 
-		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, a.created_at, a.updated_at, 
-		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
-		FROM article_management.articles a 
-		INNER JOIN article_management.users u ON u.id = a.user_id `)
+// GetArticles gets global articles matching filter with the total count of matched articles
+func (s *ArticleStore) GetArticles(ctx context.Context, filter model.ArticleFilter, page model.Page) ([]model.Article, *model.PageInfo, error) {
+	from := ` FROM article_management.articles a 
+		INNER JOIN article_management.users u ON u.id = a.user_id `
 
 	condCount := 1
 	condStrings := []string{}
 	condArgs := []interface{}{}
 
-	if username != "" {
-		condStrings = append(condStrings, fmt.Sprintf("u.username = $%d", condCount))
-		condArgs = append(condArgs, username)
+	if len(filter.Authors) != 0 {
+		condStrings = append(condStrings, fmt.Sprintf("u.username = ANY($%d)", condCount))
+		condArgs = append(condArgs, pq.Array(filter.Authors))
 		condCount += 1
 	}
 
-	if tagName != "" {
-		q.WriteString(` INNER JOIN article_management.article_tags at ON at.article_id = a.id 
-			INNER JOIN article_management.tags t ON t.id = at.tag_id `)
+	tagsSubquery := ` FROM article_management.article_tags at 
+		INNER JOIN article_management.tags t ON t.id = at.tag_id 
+		WHERE at.article_id = a.id AND t.name = ANY($%d)`
+
+	if len(filter.Tags) != 0 {
+		if filter.TagMatch == model.TagMatchAll {
+			condStrings = append(condStrings, fmt.Sprintf("(SELECT COUNT(DISTINCT t.id)"+tagsSubquery+") = $%d", condCount, condCount+1))
+			condArgs = append(condArgs, pq.Array(filter.Tags), len(filter.Tags))
+			condCount += 2
+		} else {
+			condStrings = append(condStrings, fmt.Sprintf("EXISTS (SELECT 1"+tagsSubquery+")", condCount))
+			condArgs = append(condArgs, pq.Array(filter.Tags))
+			condCount += 1
+		}
+	}
 
-		condStrings = append(condStrings, fmt.Sprintf("t.name = $%d", condCount))
-		condArgs = append(condArgs, tagName)
+	if len(filter.ExcludeTags) != 0 {
+		condStrings = append(condStrings, fmt.Sprintf("NOT EXISTS (SELECT 1"+tagsSubquery+")", condCount))
+		condArgs = append(condArgs, pq.Array(filter.ExcludeTags))
 		condCount += 1
 	}
 
-	if favoritedBy != nil {
-		queryString := `SELECT article_id 
-			FROM article_management.favorite_articles 
-			WHERE user_id = $1`
-		rows, err := s.db.QueryContext(ctx, queryString, favoritedBy.ID)
-		if err != nil {
-			return []model.Article{}, err
+	dates := []struct {
+		cond  string
+		value *time.Time
+	}{
+		{"a.created_at >= $%d", filter.CreatedAfter},
+		{"a.created_at < $%d", filter.CreatedBefore},
+		{"a.updated_at >= $%d", filter.UpdatedAfter},
+		{"a.updated_at < $%d", filter.UpdatedBefore},
+	}
+
+	for _, d := range dates {
+		if d.value != nil {
+			condStrings = append(condStrings, fmt.Sprintf(d.cond, condCount))
+			condArgs = append(condArgs, *d.value)
+			condCount += 1
 		}
-		defer rows.Close()
+	}
 
-		ids := []uint{}
-		for rows.Next() {
-			var id uint
+	if filter.FavoritedBy != nil {
+		condStrings = append(condStrings, fmt.Sprintf(`EXISTS (SELECT 1 
+			FROM article_management.favorite_articles fa 
+			WHERE fa.article_id = a.id AND fa.user_id = $%d)`, condCount))
+		condArgs = append(condArgs, filter.FavoritedBy.ID)
+		condCount += 1
+	}
 
-			err = rows.Scan(&id)
-			if err != nil {
-				return []model.Article{}, err
-			}
+	// without a viewer, no user id matches the block and follower conditions
+	var viewerID uint
+	if filter.Viewer != nil {
+		viewerID = filter.Viewer.ID
+	}
 
-			ids = append(ids, id)
-		}
+	viewerParam := fmt.Sprintf("$%d", condCount)
+	condStrings = append(condStrings, notBlockedCond("a.user_id", viewerParam), visibleAuthorCond("u", viewerParam))
+	condArgs = append(condArgs, viewerID)
+	condCount += 1
+
+	return getArticlesPage(s.db, ctx, from, condStrings, condArgs, filter.Sort, page)
+}
+
+// GetFeedArticles gets articles in feed of the filter with the total count of them
+func (s *ArticleStore) GetFeedArticles(ctx context.Context, filter model.FeedFilter, page model.Page) ([]model.Article, *model.PageInfo, error) {
+	from := ` FROM article_management.articles a 
+		INNER JOIN article_management.users u ON u.id = a.user_id `
+	condStrings, condArgs := feedConds(filter)
+
+	return getArticlesPage(s.db, ctx, from, condStrings, condArgs, model.ArticleSortNewest, page)
+}
+
+// GetDigestArticles gets newest articles of the feed created in [since, until) with the total count of them
+func (s *ArticleStore) GetDigestArticles(ctx context.Context, filter model.FeedFilter, since, until time.Time, limit int64) ([]model.Article, int64, error) {
+	from := ` FROM article_management.articles a 
+		INNER JOIN article_management.users u ON u.id = a.user_id `
+	condStrings, condArgs := feedConds(filter)
+	condStrings = append(condStrings, "a.created_at >= $4", "a.created_at < $5")
+	condArgs = append(condArgs, since, until)
+
+	articles, pageInfo, err := getArticlesPage(s.db, ctx, from, condStrings, condArgs, model.ArticleSortNewest, model.Page{Limit: limit})
+	if err != nil {
+		return []model.Article{}, 0, err
+	}
+
+	return articles, pageInfo.TotalCount, nil
+}
+
+// feedConds returns conditions of articles in the feed of filter, taking $1 to $3
+func feedConds(filter model.FeedFilter) ([]string, []interface{}) {
+	var viewerID uint
+	if filter.Viewer != nil {
+		viewerID = filter.Viewer.ID
+	}
+
+	condStrings := []string{`(a.user_id = ANY($1) OR (EXISTS ( 
+		SELECT 1 FROM article_management.article_tags at 
+		WHERE at.article_id = a.id AND at.tag_id = ANY($2) 
+	) AND a.user_id <> $3 AND NOT EXISTS ( 
+		SELECT 1 FROM article_management.mutes mu 
+		WHERE mu.from_user_id = $3 AND mu.to_user_id = a.user_id 
+	) AND ` + notBlockedCond("a.user_id", "$3") + ` AND ` + visibleAuthorCond("u", "$3") + `))`}
+	condArgs := []interface{}{pq.Array(filter.UserIDs), pq.Array(filter.TagIDs), viewerID}
+
+	return condStrings, condArgs
+}
+
+// SearchArticles finds articles matching full-text search query ordered by rank,
+// with the total count of matched articles
+//
+// Articles hidden from viewer are left out as they are by GetArticles.
+func (s *ArticleStore) SearchArticles(ctx context.Context, query model.SearchQuery, viewer *model.User, tagName, username string, limit, offset int64) ([]model.ArticleSearchResult, int64, error) {
+	var count int64
+
+	var matched bytes.Buffer
+	matched.WriteString(` FROM article_management.articles a
+		INNER JOIN article_management.users u ON u.id = a.user_id
+		CROSS JOIN q
+		WHERE a.search_vector @@ q.query `)
+
+	condCount := 2
+	condArgs := []interface{}{query.TSQuery()}
+
+	if username != "" {
+		matched.WriteString(fmt.Sprintf(" AND u.username = $%d ", condCount))
+		condArgs = append(condArgs, username)
+		condCount += 1
+	}
 
-		condStrings = append(condStrings, fmt.Sprintf("a.id = ANY($%d)", condCount))
-		condArgs = append(condArgs, pq.Array(ids))
+	if tagName != "" {
+		matched.WriteString(fmt.Sprintf(` AND EXISTS (
+			SELECT 1 FROM article_management.article_tags at
+			INNER JOIN article_management.tags t ON t.id = at.tag_id
+			WHERE at.article_id = a.id AND t.name = $%d) `, condCount))
+		condArgs = append(condArgs, tagName)
 		condCount += 1
 	}
 
-	if len(condStrings) != 0 {
-		q.WriteString(" WHERE ")
-		q.WriteString(strings.Join(condStrings, " AND "))
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	viewerParam := fmt.Sprintf("$%d", condCount)
+	matched.WriteString(" AND " + notBlockedCond("a.user_id", viewerParam) + " AND " + visibleAuthorCond("u", viewerParam) + " ")
+	condArgs = append(condArgs, viewerID)
+	condCount += 1
+
+	withQuery := `WITH q AS (SELECT to_tsquery('english', $1) AS query)`
+
+	err := s.db.QueryRowContext(ctx, withQuery+` SELECT COUNT(*)`+matched.String(), condArgs...).Scan(&count)
+	if err != nil {
+		return []model.ArticleSearchResult{}, 0, err
 	}
 
-	q.WriteString(" ORDER BY a.created_at DESC ")
+	var q bytes.Buffer
+	q.WriteString(withQuery + `,
+		matched AS (
+			SELECT a.id, a.created_at, ts_rank_cd(a.search_vector, q.query, 32) AS rank` + matched.String())
+
+	q.WriteString(" ORDER BY rank DESC, a.created_at DESC, a.id DESC ")
 	q.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", condCount, condCount+1))
 	condArgs = append(condArgs, limit)
 	condArgs = append(condArgs, offset)
 	condCount += 2
 
+	// highlights are only generated for the page of matched articles, from escaped text
+	// so that the only markup in them is the highlighting
+	q.WriteString(`)
+		SELECT
+		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, cc.comments_count, a.created_at, a.updated_at,
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at,
+		m.rank,
+		ts_headline('english', ` + escapeHTML("a.title") + `, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		ts_headline('english', ` + escapeHTML("a.description") + `, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		ts_headline('english', ` + escapeHTML("a.body") + `, q.query, 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=3, FragmentDelimiter=" ... "')
+		FROM matched m
+		INNER JOIN article_management.articles a ON a.id = m.id
+		INNER JOIN article_management.users u ON u.id = a.user_id` + commentsCountJoin + `
+		CROSS JOIN q
+		ORDER BY m.rank DESC, m.created_at DESC, m.id DESC`)
+
 	rows, err := s.db.QueryContext(ctx, q.String(), condArgs...)
 	if err != nil {
-		return []model.Article{}, err
+		return []model.ArticleSearchResult{}, 0, err
 	}
 	defer rows.Close()
 
+	results := []model.ArticleSearchResult{}
 	articles := []model.Article{}
 	for rows.Next() {
-		var article model.Article
+		var result model.ArticleSearchResult
 		var author model.User
 
 		err = rows.Scan(
-			&article.ID,
-			&article.Title,
-			&article.Description,
-			&article.Body,
-			&article.UserID,
-			&article.FavoritesCount,
-			&article.CreatedAt,
-			&article.UpdatedAt,
+			&result.Article.ID,
+			&result.Article.Title,
+			&result.Article.Description,
+			&result.Article.Body,
+			&result.Article.UserID,
+			&result.Article.FavoritesCount,
+			&result.Article.CommentsCount,
+			&result.Article.CreatedAt,
+			&result.Article.UpdatedAt,
 
 			&author.ID,
 			&author.Username,
@@ -332,99 +492,75 @@ func (s *ArticleStore) GetArticles(ctx context.Context, tagName, username string
 			&author.Image,
 			&author.CreatedAt,
 			&author.UpdatedAt,
+
+			&result.Rank,
+			&result.TitleHighlight,
+			&result.DescriptionHighlight,
+			&result.BodyHighlight,
 		)
 		if err != nil {
-			return []model.Article{}, err
+			return []model.ArticleSearchResult{}, 0, err
 		}
 
-		article.Author = author
-		articles = append(articles, article)
+		result.Article.Author = author
+		results = append(results, result)
+		articles = append(articles, result.Article)
 	}
 
 	tagsMap, err := getArticlesTags(s.db, ctx, articles)
 	if err != nil {
-		return []model.Article{}, err
+		return []model.ArticleSearchResult{}, 0, err
 	}
 
-	for i, article := range articles {
-		if tags, exists := tagsMap[article.ID]; exists {
-			article.Tags = append(article.Tags, tags...)
-			articles[i] = article
+	for i, result := range results {
+		if tags, exists := tagsMap[result.Article.ID]; exists {
+			result.Article.Tags = append(result.Article.Tags, tags...)
+			results[i] = result
 		}
 	}
 
-	return articles, nil
+	return results, count, nil
 }
 
-// GetFeedArticles gets following users' articles
-func (s *ArticleStore) GetFeedArticles(ctx context.Context, userIDs []uint, limit, offset int64) ([]model.Article, error) {
-	queryString := `SELECT 
-		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, a.created_at, a.updated_at, 
-		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
-		FROM article_management.articles a 
-		INNER JOIN article_management.users u ON u.id = a.user_id 
-		WHERE a.user_id = ANY($1) 
-		ORDER BY a.created_at DESC 
-		LIMIT $2 OFFSET $3`
-	rows, err := s.db.QueryContext(ctx, queryString, pq.Array(userIDs), limit, offset)
-	if err != nil {
-		return []model.Article{}, err
-	}
-
-	articles := []model.Article{}
-	for rows.Next() {
+// Delete deletes an article
+func (s *ArticleStore) Delete(ctx context.Context, m *model.Article) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
 		var article model.Article
-		var author model.User
-
-		err = rows.Scan(
-			&article.ID,
-			&article.Title,
-			&article.Description,
-			&article.Body,
-			&article.UserID,
-			&article.FavoritesCount,
-			&article.CreatedAt,
-			&article.UpdatedAt,
 
-			&author.ID,
-			&author.Username,
-			&author.Email,
-			&author.Password,
-			&author.Name,
-			&author.Bio,
-			&author.Image,
-			&author.CreatedAt,
-			&author.UpdatedAt,
-		)
+		queryString := `DELETE FROM article_management.articles WHERE id = $1 
+			RETURNING id, title, description, body, user_id, favorites_count, created_at, updated_at`
+		err := tx.QueryRowContext(ctx, queryString, m.ID).
+			Scan(
+				&article.ID,
+				&article.Title,
+				&article.Description,
+				&article.Body,
+				&article.UserID,
+				&article.FavoritesCount,
+				&article.CreatedAt,
+				&article.UpdatedAt,
+			)
 		if err != nil {
-			return []model.Article{}, err
+			// deleting an article which does not exist does nothing
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
 		}
 
-		article.Author = author
-		articles = append(articles, article)
-	}
+		author, err := getArticleAuthor(s.db, ctx, &article)
+		if err != nil {
+			return err
+		}
 
-	tagsMap, err := getArticlesTags(s.db, ctx, articles)
-	if err != nil {
-		return []model.Article{}, err
-	}
+		article.Author = *author
 
-	for i, article := range articles {
-		if tags, exists := tagsMap[article.ID]; exists {
-			article.Tags = append(article.Tags, tags...)
-			articles[i] = article
+		e, err := model.NewArticleEvent(model.DomainEventArticleDeleted, &article)
+		if err != nil {
+			return err
 		}
-	}
 
-	return articles, nil
-}
-
-// Delete deletes an article
-func (s *ArticleStore) Delete(ctx context.Context, m *model.Article) error {
-	return db.RunInTx(s.db, func(tx *sql.Tx) error {
-		queryString := `DELETE FROM article_management.articles WHERE id = $1`
-		_, err := tx.ExecContext(ctx, queryString, m.ID)
-		return err
+		return writeEvent(tx, ctx, &e)
 	})
 }
 
@@ -447,7 +583,37 @@ func (s *ArticleStore) IsFavorited(ctx context.Context, article *model.Article,
 	return count != 0, nil
 }
 
-// AddFavorite favorites an article
+// AreFavorited returns which of articles (by id) are favorited by the user in a single query
+func (s *ArticleStore) AreFavorited(ctx context.Context, articleIDs []uint, user *model.User) (map[uint]bool, error) {
+	favorited := make(map[uint]bool)
+	if len(articleIDs) == 0 || user == nil {
+		return favorited, nil
+	}
+
+	queryString := `SELECT article_id 
+		FROM article_management.favorite_articles 
+		WHERE user_id = $1 AND article_id = ANY($2)`
+	rows, err := s.db.QueryContext(ctx, queryString, user.ID, pq.Array(articleIDs))
+	if err != nil {
+		return favorited, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return favorited, err
+		}
+
+		favorited[id] = true
+	}
+
+	return favorited, nil
+}
+
+// AddFavorite favorites an article, its author is notified once the event is relayed
 func (s *ArticleStore) AddFavorite(ctx context.Context, article *model.Article, user *model.User, updateFn func(favoritesCount int64, updatedAt time.Time)) error {
 	return db.RunInTx(s.db, func(tx *sql.Tx) error {
 		queryString := `INSERT INTO article_management.favorite_articles 
@@ -469,6 +635,12 @@ func (s *ArticleStore) AddFavorite(ctx context.Context, article *model.Article,
 			return err
 		}
 
+		e := model.NewFavoriteEvent(model.DomainEventArticleFavorited, article, user)
+		err = writeEvent(tx, ctx, &e)
+		if err != nil {
+			return err
+		}
+
 		updateFn(favoritesCount, updatedAt)
 		return nil
 	})
@@ -496,11 +668,65 @@ func (s *ArticleStore) DeleteFavorite(ctx context.Context, article *model.Articl
 			return err
 		}
 
+		e := model.NewFavoriteEvent(model.DomainEventArticleUnfavorited, article, user)
+		err = writeEvent(tx, ctx, &e)
+		if err != nil {
+			return err
+		}
+
 		updateFn(favoritesCount, updatedAt)
 		return nil
 	})
 }
 
+// AddArticleReaction adds a reaction of the user to the article, adding it again does nothing
+func (s *ArticleStore) AddArticleReaction(ctx context.Context, article *model.Article, user *model.User, reaction string) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		return addReaction(tx, ctx, articleReactionTables, article.ID, user.ID, reaction)
+	})
+}
+
+// DeleteArticleReaction removes a reaction of the user from the article, removing it again does nothing
+func (s *ArticleStore) DeleteArticleReaction(ctx context.Context, article *model.Article, user *model.User, reaction string) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		return deleteReaction(tx, ctx, articleReactionTables, article.ID, user.ID, reaction)
+	})
+}
+
+// GetArticlesReactions returns reactions of articles (by id) along with whether the user reacted with them
+func (s *ArticleStore) GetArticlesReactions(ctx context.Context, articleIDs []uint, user *model.User) (map[uint][]model.Reaction, error) {
+	return getReactions(s.db, ctx, articleReactionTables, articleIDs, user)
+}
+
+// AddCommentReaction adds a reaction of the user to the comment, adding it again does nothing
+func (s *ArticleStore) AddCommentReaction(ctx context.Context, comment *model.Comment, user *model.User, reaction string) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		return addReaction(tx, ctx, commentReactionTables, comment.ID, user.ID, reaction)
+	})
+}
+
+// DeleteCommentReaction removes a reaction of the user from the comment, removing it again does nothing
+func (s *ArticleStore) DeleteCommentReaction(ctx context.Context, comment *model.Comment, user *model.User, reaction string) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		return deleteReaction(tx, ctx, commentReactionTables, comment.ID, user.ID, reaction)
+	})
+}
+
+// GetCommentsReactions returns reactions of comments (by id) along with whether the user reacted with them
+func (s *ArticleStore) GetCommentsReactions(ctx context.Context, commentIDs []uint, user *model.User) (map[uint][]model.Reaction, error) {
+	return getReactions(s.db, ctx, commentReactionTables, commentIDs, user)
+}
+
+// GetArticlesMentions returns users mentioned by articles (by id)
+func (s *ArticleStore) GetArticlesMentions(ctx context.Context, articleIDs []uint) (map[uint][]model.User, error) {
+	return getMentions(s.db, ctx, articleMentionTable, articleIDs)
+}
+
+// GetCommentsMentions returns users mentioned by comments (by id)
+func (s *ArticleStore) GetCommentsMentions(ctx context.Context, commentIDs []uint) (map[uint][]model.User, error) {
+	return getMentions(s.db, ctx, commentMentionTable, commentIDs)
+}
+
 // GetTags gets all tags
 func (s *ArticleStore) GetTags(ctx context.Context) ([]model.Tag, error) {
 	queryString := `SELECT id, name, created_at, updated_at 
@@ -526,40 +752,109 @@ func (s *ArticleStore) GetTags(ctx context.Context) ([]model.Tag, error) {
 	return tags, nil
 }
 
-// CreateComment creates a comment of the article
-func (s *ArticleStore) CreateComment(ctx context.Context, m *model.Comment) (*model.Comment, error) {
-	var comment model.Comment
+// GetTagByName finds a tag by name
+func (s *ArticleStore) GetTagByName(ctx context.Context, name string) (*model.Tag, error) {
+	var tag model.Tag
 
-	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
-		queryString := `INSERT INTO article_management.comments 
-			(body, user_id, article_id) VALUES ($1, $2, $3) 
-			RETURNING id, body, user_id, article_id, created_at, updated_at`
-		err := tx.QueryRowContext(ctx, queryString, m.Body, m.UserID, m.ArticleID).
-			Scan(
-				&comment.ID,
-				&comment.Body,
-				&comment.UserID,
-				&comment.ArticleID,
-				&comment.CreatedAt,
-				&comment.UpdatedAt,
-			)
-		if err != nil {
-			if errors.Is(err, sql.ErrNoRows) {
-				err = fmt.Errorf("failed to get comment :%w", err)
-			}
-			return err
+	queryString := `SELECT id, name, created_at, updated_at 
+		FROM article_management.tags 
+		WHERE name = $1`
+	err := s.db.QueryRowContext(ctx, queryString, name).
+		Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get tag :%w", err)
 		}
+		return nil, err
+	}
 
-		var author model.User
+	return &tag, nil
+}
 
-		queryString = `SELECT 
-			id, username, email, password, name, bio, image, created_at, updated_at 
-			FROM article_management.users 
-			WHERE id = $1`
-		err = tx.QueryRowContext(ctx, queryString, m.UserID).
-			Scan(
-				&author.ID,
-				&author.Username,
+// GetFollowedTags gets tags the user follows ordered by name
+func (s *ArticleStore) GetFollowedTags(ctx context.Context, user *model.User) ([]model.Tag, error) {
+	queryString := `SELECT t.id, t.name, t.created_at, t.updated_at 
+		FROM article_management.tag_follows tf 
+		INNER JOIN article_management.tags t ON t.id = tf.tag_id 
+		WHERE tf.user_id = $1 
+		ORDER BY t.name`
+	rows, err := s.db.QueryContext(ctx, queryString, user.ID)
+	if err != nil {
+		return []model.Tag{}, err
+	}
+	defer rows.Close()
+
+	tags := []model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+
+		err = rows.Scan(&tag.ID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+		if err != nil {
+			return []model.Tag{}, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// FollowTag creates a follow of the tag by the user
+func (s *ArticleStore) FollowTag(ctx context.Context, tag *model.Tag, user *model.User) error {
+	queryString := `INSERT INTO article_management.tag_follows 
+		(user_id, tag_id) VALUES ($1, $2) 
+		ON CONFLICT DO NOTHING`
+	_, err := s.db.ExecContext(ctx, queryString, user.ID, tag.ID)
+	return err
+}
+
+// UnfollowTag deletes a follow of the tag by the user
+func (s *ArticleStore) UnfollowTag(ctx context.Context, tag *model.Tag, user *model.User) error {
+	queryString := `DELETE FROM article_management.tag_follows 
+		WHERE user_id = $1 AND tag_id = $2`
+	_, err := s.db.ExecContext(ctx, queryString, user.ID, tag.ID)
+	return err
+}
+
+// CreateComment creates a comment of the article with its mentioned users, the author of the article,
+// or of the parent comment for a reply, and mentioned users are notified once the events are relayed
+func (s *ArticleStore) CreateComment(ctx context.Context, m *model.Comment) (*model.Comment, error) {
+	var comment model.Comment
+
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.comments 
+			(body, user_id, article_id, parent_id, depth) VALUES ($1, $2, $3, $4, $5) 
+			RETURNING id, body, user_id, article_id, parent_id, depth, created_at, updated_at, edited_at, deleted_at`
+		err := tx.QueryRowContext(ctx, queryString, m.Body, m.UserID, m.ArticleID, m.ParentID, m.Depth).
+			Scan(
+				&comment.ID,
+				&comment.Body,
+				&comment.UserID,
+				&comment.ArticleID,
+				&comment.ParentID,
+				&comment.Depth,
+				&comment.CreatedAt,
+				&comment.UpdatedAt,
+				&comment.EditedAt,
+				&comment.DeletedAt,
+			)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = fmt.Errorf("failed to get comment :%w", err)
+			}
+			return err
+		}
+
+		var author model.User
+
+		queryString = `SELECT 
+			id, username, email, password, name, bio, image, created_at, updated_at 
+			FROM article_management.users 
+			WHERE id = $1`
+		err = tx.QueryRowContext(ctx, queryString, m.UserID).
+			Scan(
+				&author.ID,
+				&author.Username,
 				&author.Email,
 				&author.Password,
 				&author.Name,
@@ -576,58 +871,223 @@ func (s *ArticleStore) CreateComment(ctx context.Context, m *model.Comment) (*mo
 		}
 
 		comment.Author = author
-		return nil
+
+		var articleAuthorID uint
+
+		queryString = `SELECT user_id FROM article_management.articles WHERE id = $1`
+		err = tx.QueryRowContext(ctx, queryString, comment.ArticleID).Scan(&articleAuthorID)
+		if err != nil {
+			return err
+		}
+
+		err = setMentions(tx, ctx, commentMentionTable, comment.ID, comment.UserID, comment.ArticleID, &comment.ID, m.Mentions)
+		if err != nil {
+			return err
+		}
+
+		e, err := model.NewCommentEvent(model.DomainEventCommentCreated, &comment, articleAuthorID)
+		if err != nil {
+			return err
+		}
+
+		return writeEvent(tx, ctx, &e)
 	})
 
 	return &comment, err
 }
 
-// GetComments gets comments of the article
-func (s *ArticleStore) GetComments(ctx context.Context, m *model.Article) ([]model.Comment, error) {
-	queryString := `SELECT 
-		c.id, c.body, c.user_id, c.article_id, c.created_at, c.updated_at, 
-		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
+// GetComments gets a page of comment threads of the article with the total count of threads
+//
+// Top-level comments are paginated by the key of sort, and each of them comes
+// with all of its replies, including deleted comments kept for their replies.
+// Comments of users blocking or blocked by viewer are left out with their replies.
+func (s *ArticleStore) GetComments(ctx context.Context, m *model.Article, viewer *model.User, sort string, page model.Page) ([]model.Comment, *model.PageInfo, error) {
+	key, exists := commentSortKeys[sort]
+	if !exists {
+		return []model.Comment{}, nil, fmt.Errorf("unknown comment sort: %s", sort)
+	}
+
+	// without a viewer, no user id matches the block condition
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	var totalCount int64
+
+	queryString := `SELECT COUNT(c.id) 
 		FROM article_management.comments c 
-		INNER JOIN article_management.users u ON u.id = c.user_id 
-		WHERE c.article_id = $1 
-		ORDER BY c.created_at DESC`
-	rows, err := s.db.QueryContext(ctx, queryString, m.ID)
+		WHERE c.article_id = $1 AND c.parent_id IS NULL AND ` + notBlockedCond("c.user_id", "$2")
+	err := s.db.QueryRowContext(ctx, queryString, m.ID, viewerID).Scan(&totalCount)
 	if err != nil {
-		return []model.Comment{}, err
+		return []model.Comment{}, nil, err
 	}
 
-	comments := []model.Comment{}
-	for rows.Next() {
-		var comment model.Comment
-		var author model.User
+	columns := `SELECT 
+		c.id, c.body, c.user_id, c.article_id, c.parent_id, c.depth, rc.replies_count, 
+		c.created_at, c.updated_at, c.edited_at, c.deleted_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at, `
+	from := ` FROM article_management.comments c 
+		INNER JOIN article_management.users u ON u.id = c.user_id ` + repliesCountJoin
 
-		err = rows.Scan(
-			&comment.ID,
-			&comment.Body,
-			&comment.UserID,
-			&comment.ArticleID,
-			&comment.CreatedAt,
-			&comment.UpdatedAt,
+	var q bytes.Buffer
+	q.WriteString(columns)
+	q.WriteString(key.expr)
+	q.WriteString(from)
+
+	condCount := 3
+	condStrings := []string{"c.article_id = $1", "c.parent_id IS NULL", notBlockedCond("c.user_id", "$2")}
+	condArgs := []interface{}{m.ID, viewerID}
+
+	// rows before the cursor are fetched in reverse order
+	desc := key.desc
+	offset := page.Offset
+	cursor := page.After
+	if page.Before != nil {
+		desc = !desc
+		cursor = page.Before
+	}
 
-			&author.ID,
-			&author.Username,
-			&author.Email,
-			&author.Password,
-			&author.Name,
-			&author.Bio,
-			&author.Image,
-			&author.CreatedAt,
-			&author.UpdatedAt,
-		)
-		if err != nil {
-			return []model.Comment{}, err
+	if cursor != nil {
+		cmp := ">"
+		if desc {
+			cmp = "<"
 		}
 
-		comment.Author = author
-		comments = append(comments, comment)
+		var cursorValue interface{} = cursor.Time
+		if key.count {
+			cursorValue = cursor.Count
+		}
+
+		condStrings = append(condStrings, fmt.Sprintf("(%s, c.id) %s ($%d, $%d)", key.expr, cmp, condCount, condCount+1))
+		condArgs = append(condArgs, cursorValue, cursor.ID)
+		condCount += 2
+		offset = 0
 	}
 
-	return comments, nil
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+
+	q.WriteString(" WHERE ")
+	q.WriteString(strings.Join(condStrings, " AND "))
+	q.WriteString(fmt.Sprintf(" ORDER BY %s %s, c.id %s ", key.expr, order, order))
+	q.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", condCount, condCount+1))
+	condArgs = append(condArgs, page.Limit+1)
+	condArgs = append(condArgs, offset)
+
+	scanComments := func(rows *sql.Rows) ([]model.Comment, []*model.Cursor, error) {
+		defer rows.Close()
+
+		comments := []model.Comment{}
+		cursors := []*model.Cursor{}
+		for rows.Next() {
+			var comment model.Comment
+			var author model.User
+
+			cursor := &model.Cursor{Sort: sort}
+			var keyValue interface{} = &cursor.Time
+			if key.count {
+				keyValue = &cursor.Count
+			}
+
+			err := rows.Scan(
+				&comment.ID,
+				&comment.Body,
+				&comment.UserID,
+				&comment.ArticleID,
+				&comment.ParentID,
+				&comment.Depth,
+				&comment.RepliesCount,
+				&comment.CreatedAt,
+				&comment.UpdatedAt,
+				&comment.EditedAt,
+				&comment.DeletedAt,
+
+				&author.ID,
+				&author.Username,
+				&author.Email,
+				&author.Password,
+				&author.Name,
+				&author.Bio,
+				&author.Image,
+				&author.CreatedAt,
+				&author.UpdatedAt,
+
+				keyValue,
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			cursor.ID = comment.ID
+			cursors = append(cursors, cursor)
+
+			comment.Author = author
+			comments = append(comments, comment)
+		}
+
+		return comments, cursors, rows.Err()
+	}
+
+	rows, err := s.db.QueryContext(ctx, q.String(), condArgs...)
+	if err != nil {
+		return []model.Comment{}, nil, err
+	}
+
+	comments, cursors, err := scanComments(rows)
+	if err != nil {
+		return []model.Comment{}, nil, err
+	}
+
+	hasMore := int64(len(comments)) > page.Limit
+	if hasMore {
+		comments = comments[:page.Limit]
+		cursors = cursors[:page.Limit]
+	}
+
+	if page.Before != nil {
+		slices.Reverse(comments)
+		slices.Reverse(cursors)
+	}
+
+	var first, last *model.Cursor
+	if len(cursors) != 0 {
+		first = cursors[0]
+		last = cursors[len(cursors)-1]
+	}
+
+	pageInfo := model.NewPageInfo(page, totalCount, first, last, len(comments), hasMore)
+	if len(comments) == 0 {
+		return comments, pageInfo, nil
+	}
+
+	rootIDs := make([]uint, 0, len(comments))
+	for _, c := range comments {
+		rootIDs = append(rootIDs, c.ID)
+	}
+
+	queryString = `WITH RECURSIVE thread AS (
+			SELECT r.id FROM article_management.comments r 
+			WHERE r.parent_id = ANY($1) AND ` + notBlockedCond("r.user_id", "$2") + ` 
+			UNION ALL 
+			SELECT r.id FROM article_management.comments r INNER JOIN thread t ON r.parent_id = t.id
+			WHERE ` + notBlockedCond("r.user_id", "$2") + ` 
+		) ` + columns + key.expr + from + ` 
+		WHERE c.id IN (SELECT id FROM thread) 
+		ORDER BY c.created_at ASC, c.id ASC`
+	rows, err = s.db.QueryContext(ctx, queryString, pq.Array(rootIDs), viewerID)
+	if err != nil {
+		return []model.Comment{}, nil, err
+	}
+
+	replies, _, err := scanComments(rows)
+	if err != nil {
+		return []model.Comment{}, nil, err
+	}
+
+	return append(comments, replies...), pageInfo, nil
 }
 
 // GetCommentByID finds a comment from id
@@ -636,7 +1096,9 @@ func (s *ArticleStore) GetCommentByID(ctx context.Context, id uint) (*model.Comm
 	var author model.User
 
 	queryString := `SELECT 
-		c.id, c.body, c.user_id, c.article_id, c.created_at, c.updated_at, 
+		c.id, c.body, c.user_id, c.article_id, c.parent_id, c.depth, 
+		(SELECT COUNT(r.id) FROM article_management.comments r WHERE r.parent_id = c.id), 
+		c.created_at, c.updated_at, c.edited_at, c.deleted_at, 
 		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
 		FROM article_management.comments c 
 		INNER JOIN article_management.users u ON u.id = c.user_id 
@@ -647,8 +1109,13 @@ func (s *ArticleStore) GetCommentByID(ctx context.Context, id uint) (*model.Comm
 			&comment.Body,
 			&comment.UserID,
 			&comment.ArticleID,
+			&comment.ParentID,
+			&comment.Depth,
+			&comment.RepliesCount,
 			&comment.CreatedAt,
 			&comment.UpdatedAt,
+			&comment.EditedAt,
+			&comment.DeletedAt,
 
 			&author.ID,
 			&author.Username,
@@ -671,15 +1138,357 @@ func (s *ArticleStore) GetCommentByID(ctx context.Context, id uint) (*model.Comm
 	return &comment, nil
 }
 
+// UpdateComment updates body of a comment and keeps its prior body as a revision,
+// and replaces its mentioned users, newly mentioned users are notified
+func (s *ArticleStore) UpdateComment(ctx context.Context, m *model.Comment) (*model.Comment, error) {
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.comment_revisions (comment_id, body) 
+			SELECT id, body FROM article_management.comments WHERE id = $1`
+		_, err := tx.ExecContext(ctx, queryString, m.ID)
+		if err != nil {
+			return err
+		}
+
+		queryString = `UPDATE article_management.comments 
+			SET body = $1, edited_at = CURRENT_TIMESTAMP, updated_at = DEFAULT 
+			WHERE id = $2`
+		_, err = tx.ExecContext(ctx, queryString, m.Body, m.ID)
+		if err != nil {
+			return err
+		}
+
+		return setMentions(tx, ctx, commentMentionTable, m.ID, m.UserID, m.ArticleID, &m.ID, m.Mentions)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCommentByID(ctx, m.ID)
+}
+
+// GetCommentRevisions gets prior bodies of the comment, oldest first
+func (s *ArticleStore) GetCommentRevisions(ctx context.Context, m *model.Comment) ([]model.CommentRevision, error) {
+	queryString := `SELECT id, comment_id, body, created_at 
+		FROM article_management.comment_revisions 
+		WHERE comment_id = $1 
+		ORDER BY created_at ASC, id ASC`
+	rows, err := s.db.QueryContext(ctx, queryString, m.ID)
+	if err != nil {
+		return []model.CommentRevision{}, err
+	}
+	defer rows.Close()
+
+	revisions := []model.CommentRevision{}
+	for rows.Next() {
+		var revision model.CommentRevision
+
+		err = rows.Scan(
+			&revision.ID,
+			&revision.CommentID,
+			&revision.Body,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return []model.CommentRevision{}, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, nil
+}
+
 // DeleteComment deletes a comment
+//
+// A comment with replies is kept as a placeholder without body so that its thread
+// keeps its shape, and placeholders left without replies are deleted along the way.
 func (s *ArticleStore) DeleteComment(ctx context.Context, m *model.Comment) error {
 	return db.RunInTx(s.db, func(tx *sql.Tx) error {
-		queryString := `DELETE FROM article_management.comments WHERE id = $1`
-		_, err := tx.ExecContext(ctx, queryString, m.ID)
-		return err
+		var hasReplies bool
+
+		queryString := `SELECT EXISTS (
+			SELECT 1 FROM article_management.comments WHERE parent_id = $1
+		)`
+		err := tx.QueryRowContext(ctx, queryString, m.ID).Scan(&hasReplies)
+		if err != nil {
+			return err
+		}
+
+		var articleAuthorID uint
+
+		queryString = `SELECT user_id FROM article_management.articles WHERE id = $1`
+		err = tx.QueryRowContext(ctx, queryString, m.ArticleID).Scan(&articleAuthorID)
+		if err != nil {
+			return err
+		}
+
+		e, err := model.NewCommentEvent(model.DomainEventCommentDeleted, m, articleAuthorID)
+		if err != nil {
+			return err
+		}
+
+		err = writeEvent(tx, ctx, &e)
+		if err != nil {
+			return err
+		}
+
+		if hasReplies {
+			queryString = `UPDATE article_management.comments 
+				SET body = '', deleted_at = CURRENT_TIMESTAMP, updated_at = DEFAULT 
+				WHERE id = $1`
+			_, err = tx.ExecContext(ctx, queryString, m.ID)
+			return err
+		}
+
+		queryString = `DELETE FROM article_management.comments WHERE id = $1`
+		_, err = tx.ExecContext(ctx, queryString, m.ID)
+		if err != nil {
+			return err
+		}
+
+		queryString = `DELETE FROM article_management.comments c 
+			WHERE c.id = $1 AND c.deleted_at IS NOT NULL 
+			AND NOT EXISTS (SELECT 1 FROM article_management.comments r WHERE r.parent_id = c.id) 
+			RETURNING c.parent_id`
+		parentID := m.ParentID
+		for parentID != nil {
+			err = tx.QueryRowContext(ctx, queryString, *parentID).Scan(&parentID)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
 	})
 }
 
+// reactionTables names tables keeping reactions of a kind of target
+type reactionTables struct {
+	// reactions has a row per user and reaction of a target
+	reactions string
+	// counts has a row per reaction of a target with the number of users who reacted
+	counts string
+	// column refers to the target in both tables
+	column string
+}
+
+var articleReactionTables = reactionTables{
+	reactions: "article_management.article_reactions",
+	counts:    "article_management.article_reaction_counts",
+	column:    "article_id",
+}
+
+var commentReactionTables = reactionTables{
+	reactions: "article_management.comment_reactions",
+	counts:    "article_management.comment_reaction_counts",
+	column:    "comment_id",
+}
+
+// addReaction adds a reaction and counts it only when it was not added yet
+//
+// Concurrent adds of the same reaction wait on its primary key, so only the one
+// which inserted the reaction increments the count.
+func addReaction(tx *sql.Tx, ctx context.Context, t reactionTables, targetID, userID uint, reaction string) error {
+	queryString := fmt.Sprintf(`INSERT INTO %s (%s, user_id, reaction) VALUES ($1, $2, $3) 
+		ON CONFLICT DO NOTHING`, t.reactions, t.column)
+	result, err := tx.ExecContext(ctx, queryString, targetID, userID, reaction)
+	if err != nil {
+		return err
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil || inserted == 0 {
+		return err
+	}
+
+	queryString = fmt.Sprintf(`INSERT INTO %[1]s AS rc (%[2]s, reaction, count) VALUES ($1, $2, 1) 
+		ON CONFLICT (%[2]s, reaction) DO UPDATE SET count = rc.count + 1`, t.counts, t.column)
+	_, err = tx.ExecContext(ctx, queryString, targetID, reaction)
+	return err
+}
+
+// deleteReaction deletes a reaction and uncounts it only when it was added
+func deleteReaction(tx *sql.Tx, ctx context.Context, t reactionTables, targetID, userID uint, reaction string) error {
+	queryString := fmt.Sprintf(`DELETE FROM %s 
+		WHERE %s = $1 AND user_id = $2 AND reaction = $3`, t.reactions, t.column)
+	result, err := tx.ExecContext(ctx, queryString, targetID, userID, reaction)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil || deleted == 0 {
+		return err
+	}
+
+	queryString = fmt.Sprintf(`UPDATE %s SET count = GREATEST(0, count - 1) 
+		WHERE %s = $1 AND reaction = $2`, t.counts, t.column)
+	_, err = tx.ExecContext(ctx, queryString, targetID, reaction)
+	if err != nil {
+		return err
+	}
+
+	queryString = fmt.Sprintf(`DELETE FROM %s 
+		WHERE %s = $1 AND reaction = $2 AND count = 0`, t.counts, t.column)
+	_, err = tx.ExecContext(ctx, queryString, targetID, reaction)
+	return err
+}
+
+// getReactions returns reactions of targets (by id) ordered by count, most reacted first
+func getReactions(db *sql.DB, ctx context.Context, t reactionTables, targetIDs []uint, user *model.User) (map[uint][]model.Reaction, error) {
+	reactions := make(map[uint][]model.Reaction)
+	if len(targetIDs) == 0 {
+		return reactions, nil
+	}
+
+	var userID uint
+	if user != nil {
+		userID = user.ID
+	}
+
+	queryString := fmt.Sprintf(`SELECT rc.%[3]s, rc.reaction, rc.count, r.user_id IS NOT NULL 
+		FROM %[2]s rc 
+		LEFT JOIN %[1]s r ON r.%[3]s = rc.%[3]s AND r.reaction = rc.reaction AND r.user_id = $2 
+		WHERE rc.%[3]s = ANY($1) AND rc.count > 0 
+		ORDER BY rc.count DESC, rc.reaction ASC`, t.reactions, t.counts, t.column)
+	rows, err := db.QueryContext(ctx, queryString, pq.Array(targetIDs), userID)
+	if err != nil {
+		return reactions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetID uint
+		var reaction model.Reaction
+
+		err = rows.Scan(&targetID, &reaction.Name, &reaction.Count, &reaction.Reacted)
+		if err != nil {
+			return reactions, err
+		}
+
+		reactions[targetID] = append(reactions[targetID], reaction)
+	}
+
+	return reactions, nil
+}
+
+// mentionTable names a table keeping users mentioned by a kind of target
+type mentionTable struct {
+	table string
+	// column refers to the target
+	column string
+}
+
+var articleMentionTable = mentionTable{
+	table:  "article_management.article_mentions",
+	column: "article_id",
+}
+
+var commentMentionTable = mentionTable{
+	table:  "article_management.comment_mentions",
+	column: "comment_id",
+}
+
+// setMentions replaces mentions of a target, and writes a mention event for each newly mentioned user
+// other than the author, whom the notification consumer notifies
+//
+// Users still mentioned after an update keep their mention, so they are not notified again.
+// Users blocking or blocked by the author cannot be mentioned by the author.
+func setMentions(tx *sql.Tx, ctx context.Context, t mentionTable, targetID, authorID, articleID uint, commentID *uint, users []model.User) error {
+	userIDs := make([]uint, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.ID)
+	}
+
+	queryString := fmt.Sprintf(`DELETE FROM %s 
+		WHERE %s = $1 AND NOT (user_id = ANY($2))`, t.table, t.column)
+	_, err := tx.ExecContext(ctx, queryString, targetID, pq.Array(userIDs))
+	if err != nil {
+		return err
+	}
+
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	queryString = fmt.Sprintf(`INSERT INTO %s (%s, user_id) 
+		SELECT $1, u.user_id FROM unnest($2::INTEGER[]) AS u (user_id) 
+		WHERE %s 
+		ON CONFLICT DO NOTHING 
+		RETURNING user_id`, t.table, t.column, notBlockedCond("u.user_id", "$3::INTEGER"))
+	rows, err := tx.QueryContext(ctx, queryString, targetID, pq.Array(userIDs), authorID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	mentionedIDs := []uint{}
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		if id != authorID {
+			mentionedIDs = append(mentionedIDs, id)
+		}
+	}
+
+	err = rows.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, id := range mentionedIDs {
+		e := model.NewMentionEvent(authorID, id, articleID, commentID)
+
+		err = writeEvent(tx, ctx, &e)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getMentions returns users mentioned by targets (by id) in order of username
+func getMentions(db *sql.DB, ctx context.Context, t mentionTable, targetIDs []uint) (map[uint][]model.User, error) {
+	mentions := make(map[uint][]model.User)
+	if len(targetIDs) == 0 {
+		return mentions, nil
+	}
+
+	queryString := fmt.Sprintf(`SELECT m.%[2]s, u.id, u.username, u.name 
+		FROM %[1]s m 
+		INNER JOIN article_management.users u ON u.id = m.user_id 
+		WHERE m.%[2]s = ANY($1) 
+		ORDER BY u.username ASC`, t.table, t.column)
+	rows, err := db.QueryContext(ctx, queryString, pq.Array(targetIDs))
+	if err != nil {
+		return mentions, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var targetID uint
+		var user model.User
+
+		err = rows.Scan(&targetID, &user.ID, &user.Username, &user.Name)
+		if err != nil {
+			return mentions, err
+		}
+
+		mentions[targetID] = append(mentions[targetID], user)
+	}
+
+	return mentions, nil
+}
+
 func getArticleAuthor(db *sql.DB, ctx context.Context, article *model.Article) (*model.User, error) {
 	var author model.User
 
@@ -772,3 +1581,268 @@ func getArticlesTags(db *sql.DB, ctx context.Context, articles []model.Article)
 
 	return tagsMap, nil
 }
+
+// queryArticles runs a query selecting articles (a) with their authors (u) as listed by getArticlesPage,
+// and returns the articles with their tags in the order of the query
+func queryArticles(db *sql.DB, ctx context.Context, queryString string, args ...interface{}) ([]model.Article, error) {
+	rows, err := db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return []model.Article{}, err
+	}
+	defer rows.Close()
+
+	articles := []model.Article{}
+	for rows.Next() {
+		var article model.Article
+		var author model.User
+
+		err = rows.Scan(
+			&article.ID,
+			&article.Title,
+			&article.Description,
+			&article.Body,
+			&article.UserID,
+			&article.FavoritesCount,
+			&article.CommentsCount,
+			&article.CreatedAt,
+			&article.UpdatedAt,
+
+			&author.ID,
+			&author.Username,
+			&author.Email,
+			&author.Password,
+			&author.Name,
+			&author.Bio,
+			&author.Image,
+			&author.CreatedAt,
+			&author.UpdatedAt,
+		)
+		if err != nil {
+			return []model.Article{}, err
+		}
+
+		article.Author = author
+		articles = append(articles, article)
+	}
+
+	tagsMap, err := getArticlesTags(db, ctx, articles)
+	if err != nil {
+		return []model.Article{}, err
+	}
+
+	for i, article := range articles {
+		if tags, exists := tagsMap[article.ID]; exists {
+			article.Tags = append(article.Tags, tags...)
+			articles[i] = article
+		}
+	}
+
+	return articles, nil
+}
+
+// escapeHTML returns an expression of the text column with HTML special characters escaped
+func escapeHTML(column string) string {
+	return `replace(replace(replace(replace(replace(` + column + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;'), '''', '&#39;')`
+}
+
+// commentsCountJoin is joined to articles (a) to count their comments as cc.comments_count,
+// deleted comments only kept for their replies are not counted
+const commentsCountJoin = ` LEFT JOIN LATERAL (
+	SELECT COUNT(c.id) AS comments_count 
+	FROM article_management.comments c 
+	WHERE c.article_id = a.id AND c.deleted_at IS NULL
+) cc ON TRUE `
+
+// repliesCountJoin is joined to comments (c) to count their direct replies as rc.replies_count
+const repliesCountJoin = ` LEFT JOIN LATERAL (
+	SELECT COUNT(r.id) AS replies_count 
+	FROM article_management.comments r 
+	WHERE r.parent_id = c.id
+) rc ON TRUE `
+
+// sortKey describes how rows of a keyset paginated listing are ordered for a sort
+type sortKey struct {
+	// expr is the sort key expression, ties are broken by row id
+	expr string
+	// desc tells whether rows are ordered in descending order
+	desc bool
+	// count tells whether sort key is a count rather than a timestamp
+	count bool
+}
+
+var articleSortKeys = map[string]sortKey{
+	model.ArticleSortNewest:          {expr: "a.created_at", desc: true},
+	model.ArticleSortOldest:          {expr: "a.created_at"},
+	model.ArticleSortRecentlyUpdated: {expr: "a.updated_at", desc: true},
+	model.ArticleSortMostFavorited:   {expr: "a.favorites_count", desc: true, count: true},
+	model.ArticleSortMostCommented:   {expr: "cc.comments_count", desc: true, count: true},
+}
+
+var commentSortKeys = map[string]sortKey{
+	model.CommentSortNewest: {expr: "c.created_at", desc: true},
+	model.CommentSortOldest: {expr: "c.created_at"},
+	model.CommentSortTop:    {expr: "rc.replies_count", desc: true, count: true},
+}
+
+// getArticlesPage counts articles matching conditions and fetches a page of them
+//
+// Articles are ordered by the key of sort. A cursor page is fetched with
+// one extra row to find out whether there are more rows beyond it.
+func getArticlesPage(db *sql.DB, ctx context.Context, from string, condStrings []string, condArgs []interface{}, sort string, page model.Page) ([]model.Article, *model.PageInfo, error) {
+	key, exists := articleSortKeys[sort]
+	if !exists {
+		return []model.Article{}, nil, fmt.Errorf("unknown article sort: %s", sort)
+	}
+
+	var where string
+	if len(condStrings) != 0 {
+		where = " WHERE " + strings.Join(condStrings, " AND ")
+	}
+
+	var totalCount int64
+
+	queryString := `SELECT COUNT(a.id) ` + from + where
+	err := db.QueryRowContext(ctx, queryString, condArgs...).Scan(&totalCount)
+	if err != nil {
+		return []model.Article{}, nil, err
+	}
+
+	var q bytes.Buffer
+	q.WriteString(`SELECT 
+		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, cc.comments_count, a.created_at, a.updated_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at, `)
+	q.WriteString(key.expr)
+	q.WriteString(from)
+	q.WriteString(commentsCountJoin)
+
+	condCount := len(condArgs) + 1
+	condStrings = append([]string{}, condStrings...)
+	condArgs = append([]interface{}{}, condArgs...)
+
+	cursorValue := func(c *model.Cursor) interface{} {
+		if key.count {
+			return c.Count
+		}
+		return c.Time
+	}
+
+	// rows before the cursor are fetched in reverse order
+	desc := key.desc
+	offset := page.Offset
+	cursor := page.After
+	if page.Before != nil {
+		desc = !desc
+		cursor = page.Before
+	}
+
+	if cursor != nil {
+		cmp := ">"
+		if desc {
+			cmp = "<"
+		}
+
+		condStrings = append(condStrings, fmt.Sprintf("(%s, a.id) %s ($%d, $%d)", key.expr, cmp, condCount, condCount+1))
+		condArgs = append(condArgs, cursorValue(cursor), cursor.ID)
+		condCount += 2
+		offset = 0
+	}
+
+	order := "ASC"
+	if desc {
+		order = "DESC"
+	}
+
+	if len(condStrings) != 0 {
+		q.WriteString(" WHERE ")
+		q.WriteString(strings.Join(condStrings, " AND "))
+	}
+
+	q.WriteString(fmt.Sprintf(" ORDER BY %s %s, a.id %s ", key.expr, order, order))
+	q.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", condCount, condCount+1))
+	condArgs = append(condArgs, page.Limit+1)
+	condArgs = append(condArgs, offset)
+	condCount += 2
+
+	rows, err := db.QueryContext(ctx, q.String(), condArgs...)
+	if err != nil {
+		return []model.Article{}, nil, err
+	}
+	defer rows.Close()
+
+	articles := []model.Article{}
+	cursors := []*model.Cursor{}
+	for rows.Next() {
+		var article model.Article
+		var author model.User
+
+		cursor := &model.Cursor{Sort: sort}
+		var keyValue interface{} = &cursor.Time
+		if key.count {
+			keyValue = &cursor.Count
+		}
+
+		err = rows.Scan(
+			&article.ID,
+			&article.Title,
+			&article.Description,
+			&article.Body,
+			&article.UserID,
+			&article.FavoritesCount,
+			&article.CommentsCount,
+			&article.CreatedAt,
+			&article.UpdatedAt,
+
+			&author.ID,
+			&author.Username,
+			&author.Email,
+			&author.Password,
+			&author.Name,
+			&author.Bio,
+			&author.Image,
+			&author.CreatedAt,
+			&author.UpdatedAt,
+
+			keyValue,
+		)
+		if err != nil {
+			return []model.Article{}, nil, err
+		}
+
+		cursor.ID = article.ID
+		cursors = append(cursors, cursor)
+
+		article.Author = author
+		articles = append(articles, article)
+	}
+
+	hasMore := int64(len(articles)) > page.Limit
+	if hasMore {
+		articles = articles[:page.Limit]
+		cursors = cursors[:page.Limit]
+	}
+
+	if page.Before != nil {
+		slices.Reverse(articles)
+		slices.Reverse(cursors)
+	}
+
+	tagsMap, err := getArticlesTags(db, ctx, articles)
+	if err != nil {
+		return []model.Article{}, nil, err
+	}
+
+	for i, article := range articles {
+		if tags, exists := tagsMap[article.ID]; exists {
+			article.Tags = append(article.Tags, tags...)
+			articles[i] = article
+		}
+	}
+
+	var first, last *model.Cursor
+	if len(cursors) != 0 {
+		first = cursors[0]
+		last = cursors[len(cursors)-1]
+	}
+
+	return articles, model.NewPageInfo(page, totalCount, first, last, len(articles), hasMore), nil
+}
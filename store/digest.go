@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// digestClaimTimeout is how long a claimed digest is skipped by other claims
+const digestClaimTimeout = 15 * time.Minute
+
+// DigestStore is a data access struct for digest subscriptions
+type DigestStore struct {
+	db *sql.DB
+}
+
+// NewDigestStore returns a new DigestStore
+func NewDigestStore(db *sql.DB) *DigestStore {
+	return &DigestStore{db: db}
+}
+
+// GetByUser gets digest subscription of the user, which is off when the user never subscribed
+func (s *DigestStore) GetByUser(ctx context.Context, user *model.User) (*model.DigestSubscription, error) {
+	var sub model.DigestSubscription
+
+	queryString := `SELECT user_id, frequency, unsubscribe_token, last_sent_at, created_at, updated_at 
+		FROM article_management.digest_subscriptions 
+		WHERE user_id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, user.ID).
+		Scan(
+			&sub.UserID,
+			&sub.Frequency,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &model.DigestSubscription{UserID: user.ID, Frequency: model.DigestFrequencyOff}, nil
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// GetByUnsubscribeToken gets digest subscription of the unsubscribe token
+func (s *DigestStore) GetByUnsubscribeToken(ctx context.Context, token string) (*model.DigestSubscription, error) {
+	var sub model.DigestSubscription
+
+	queryString := `SELECT user_id, frequency, unsubscribe_token, last_sent_at, created_at, updated_at 
+		FROM article_management.digest_subscriptions 
+		WHERE unsubscribe_token = $1`
+	err := s.db.QueryRowContext(ctx, queryString, token).
+		Scan(
+			&sub.UserID,
+			&sub.Frequency,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get digest subscription :%w", err)
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// Subscribe sets frequency of digest subscription of the user, keeping its unsubscribe token
+//
+// Subscribing again after being off starts over from now, so that the first
+// digest does not cover articles from before subscribing.
+func (s *DigestStore) Subscribe(ctx context.Context, m *model.DigestSubscription) (*model.DigestSubscription, error) {
+	var sub model.DigestSubscription
+
+	queryString := `INSERT INTO article_management.digest_subscriptions 
+		(user_id, frequency, unsubscribe_token) VALUES ($1, $2, $3) 
+		ON CONFLICT (user_id) DO UPDATE SET 
+		frequency = EXCLUDED.frequency, 
+		last_sent_at = CASE WHEN digest_subscriptions.frequency = $4 
+			THEN CURRENT_TIMESTAMP ELSE digest_subscriptions.last_sent_at END, 
+		updated_at = DEFAULT 
+		RETURNING user_id, frequency, unsubscribe_token, last_sent_at, created_at, updated_at`
+	err := s.db.QueryRowContext(ctx, queryString, m.UserID, m.Frequency, m.UnsubscribeToken, model.DigestFrequencyOff).
+		Scan(
+			&sub.UserID,
+			&sub.Frequency,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
+		)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// Unsubscribe turns off digest subscription of the unsubscribe token
+func (s *DigestStore) Unsubscribe(ctx context.Context, token string) (*model.DigestSubscription, error) {
+	var sub model.DigestSubscription
+
+	queryString := `UPDATE article_management.digest_subscriptions 
+		SET frequency = $1, updated_at = DEFAULT 
+		WHERE unsubscribe_token = $2 
+		RETURNING user_id, frequency, unsubscribe_token, last_sent_at, created_at, updated_at`
+	err := s.db.QueryRowContext(ctx, queryString, model.DigestFrequencyOff, token).
+		Scan(
+			&sub.UserID,
+			&sub.Frequency,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get digest subscription :%w", err)
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+// ClaimDue claims up to limit subscriptions whose digest is due by until for the claim timeout,
+// and returns them with LastSentAt as the end of the period covered by their last digest
+//
+// Claimed subscriptions are skipped by concurrent claims until they are marked sent or released,
+// or until their claim times out when the claiming job stopped without doing either.
+func (s *DigestStore) ClaimDue(ctx context.Context, until time.Time, limit int64) ([]model.DigestSubscription, error) {
+	daily := model.DigestSubscription{Frequency: model.DigestFrequencyDaily}
+	weekly := model.DigestSubscription{Frequency: model.DigestFrequencyWeekly}
+
+	queryString := `WITH due AS ( 
+			SELECT user_id 
+			FROM article_management.digest_subscriptions 
+			WHERE ((frequency = $1 AND last_sent_at <= $2) OR (frequency = $3 AND last_sent_at <= $4)) 
+			AND (claimed_at IS NULL OR claimed_at < CURRENT_TIMESTAMP - $6 * INTERVAL '1 second') 
+			ORDER BY last_sent_at ASC 
+			LIMIT $5 
+			FOR UPDATE SKIP LOCKED 
+		) 
+		UPDATE article_management.digest_subscriptions ds 
+		SET claimed_at = CURRENT_TIMESTAMP 
+		FROM due 
+		WHERE ds.user_id = due.user_id 
+		RETURNING ds.user_id, ds.frequency, ds.unsubscribe_token, ds.last_sent_at, ds.created_at, ds.updated_at`
+	rows, err := s.db.QueryContext(ctx, queryString,
+		daily.Frequency, until.Add(-daily.Period()),
+		weekly.Frequency, until.Add(-weekly.Period()),
+		limit, int64(digestClaimTimeout/time.Second),
+	)
+	if err != nil {
+		return []model.DigestSubscription{}, err
+	}
+	defer rows.Close()
+
+	subs := make([]model.DigestSubscription, 0, limit)
+	for rows.Next() {
+		var sub model.DigestSubscription
+
+		err = rows.Scan(
+			&sub.UserID,
+			&sub.Frequency,
+			&sub.UnsubscribeToken,
+			&sub.LastSentAt,
+			&sub.CreatedAt,
+			&sub.UpdatedAt,
+		)
+		if err != nil {
+			return []model.DigestSubscription{}, err
+		}
+
+		subs = append(subs, sub)
+	}
+
+	return subs, nil
+}
+
+// MarkSent releases claim of the subscription and moves its last sent time to until,
+// the end of the period covered by the digest sent
+func (s *DigestStore) MarkSent(ctx context.Context, sub *model.DigestSubscription, until time.Time) error {
+	queryString := `UPDATE article_management.digest_subscriptions 
+		SET last_sent_at = $1, claimed_at = NULL, updated_at = DEFAULT 
+		WHERE user_id = $2`
+	_, err := s.db.ExecContext(ctx, queryString, until, sub.UserID)
+	return err
+}
+
+// ReleaseClaim releases claim of the subscription without moving its last sent time,
+// so that its digest is claimed again when it failed to be sent
+func (s *DigestStore) ReleaseClaim(ctx context.Context, sub *model.DigestSubscription) error {
+	queryString := `UPDATE article_management.digest_subscriptions 
+		SET claimed_at = NULL 
+		WHERE user_id = $1`
+	_, err := s.db.ExecContext(ctx, queryString, sub.UserID)
+	return err
+}
@@ -0,0 +1,342 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// MediaStore is a data access struct for media metadata
+type MediaStore struct {
+	db *sql.DB
+}
+
+// NewMediaStore returns a new MediaStore
+func NewMediaStore(db *sql.DB) *MediaStore {
+	return &MediaStore{db: db}
+}
+
+// GetByID finds a media by id
+func (s *MediaStore) GetByID(ctx context.Context, id uint) (*model.Media, error) {
+	var media model.Media
+
+	queryString := `SELECT id, user_id, storage_key, filename, content_type, size, created_at, updated_at 
+		FROM article_management.media 
+		WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, id).
+		Scan(
+			&media.ID,
+			&media.UserID,
+			&media.StorageKey,
+			&media.Filename,
+			&media.ContentType,
+			&media.Size,
+			&media.CreatedAt,
+			&media.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get media :%w", err)
+		}
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// Create creates a media and returns the newly created media
+func (s *MediaStore) Create(ctx context.Context, m *model.Media) (*model.Media, error) {
+	var media model.Media
+
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.media 
+			(user_id, storage_key, filename, content_type, size) VALUES ($1, $2, $3, $4, $5) 
+			RETURNING id, user_id, storage_key, filename, content_type, size, created_at, updated_at`
+		err := tx.QueryRowContext(ctx, queryString, m.UserID, m.StorageKey, m.Filename, m.ContentType, m.Size).
+			Scan(
+				&media.ID,
+				&media.UserID,
+				&media.StorageKey,
+				&media.Filename,
+				&media.ContentType,
+				&media.Size,
+				&media.CreatedAt,
+				&media.UpdatedAt,
+			)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = fmt.Errorf("failed to retrieve newly created media :%w", err)
+			}
+			return err
+		}
+
+		return nil
+	})
+
+	return &media, err
+}
+
+// Delete deletes a media, detaching it from articles
+func (s *MediaStore) Delete(ctx context.Context, m *model.Media) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `DELETE FROM article_management.media WHERE id = $1`
+		_, err := tx.ExecContext(ctx, queryString, m.ID)
+		return err
+	})
+}
+
+// GetArticleMedia gets media attached to an article in order of attachment
+func (s *MediaStore) GetArticleMedia(ctx context.Context, article *model.Article) ([]model.Media, error) {
+	queryString := `SELECT 
+		m.id, m.user_id, m.storage_key, m.filename, m.content_type, m.size, m.created_at, m.updated_at 
+		FROM article_management.article_media am 
+		INNER JOIN article_management.media m ON m.id = am.media_id 
+		WHERE am.article_id = $1 
+		ORDER BY am.created_at ASC, m.id ASC`
+	rows, err := s.db.QueryContext(ctx, queryString, article.ID)
+	if err != nil {
+		return []model.Media{}, err
+	}
+	defer rows.Close()
+
+	media := []model.Media{}
+	for rows.Next() {
+		var m model.Media
+
+		err = rows.Scan(
+			&m.ID,
+			&m.UserID,
+			&m.StorageKey,
+			&m.Filename,
+			&m.ContentType,
+			&m.Size,
+			&m.CreatedAt,
+			&m.UpdatedAt,
+		)
+		if err != nil {
+			return []model.Media{}, err
+		}
+
+		media = append(media, m)
+	}
+
+	return media, nil
+}
+
+// CanViewMedia returns whether a media (by id) is visible to the viewer, which holds unless it is
+// attached to articles and articles of none of their authors are visible to the viewer
+func (s *MediaStore) CanViewMedia(ctx context.Context, viewer *model.User, mediaID uint) (bool, error) {
+	// without a viewer, no user id matches the follower condition
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	var visible bool
+
+	queryString := `SELECT NOT EXISTS ( 
+			SELECT 1 FROM article_management.article_media am WHERE am.media_id = $1 
+		) OR EXISTS ( 
+			SELECT 1 FROM article_management.article_media am 
+			INNER JOIN article_management.articles a ON a.id = am.article_id 
+			INNER JOIN article_management.users u ON u.id = a.user_id 
+			WHERE am.media_id = $1 AND ` + visibleAuthorCond("u", "$2") + ` AND ` + notBlockedCond("u.id", "$2") + ` 
+		)`
+	err := s.db.QueryRowContext(ctx, queryString, mediaID, viewerID).Scan(&visible)
+	return visible, err
+}
+
+// AttachToArticle attaches a media to an article, attaching it again is a no-op
+func (s *MediaStore) AttachToArticle(ctx context.Context, article *model.Article, m *model.Media) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.article_media 
+			(article_id, media_id) VALUES ($1, $2) 
+			ON CONFLICT (article_id, media_id) DO NOTHING`
+		_, err := tx.ExecContext(ctx, queryString, article.ID, m.ID)
+		return err
+	})
+}
+
+// DetachFromArticle detaches a media from an article
+func (s *MediaStore) DetachFromArticle(ctx context.Context, article *model.Article, m *model.Media) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `DELETE FROM article_management.article_media 
+			WHERE article_id = $1 AND media_id = $2`
+		_, err := tx.ExecContext(ctx, queryString, article.ID, m.ID)
+		return err
+	})
+}
+
+// GetUnprocessedImageIDs gets ids of image media whose variants are not generated yet
+func (s *MediaStore) GetUnprocessedImageIDs(ctx context.Context, limit int64) ([]uint, error) {
+	queryString := `SELECT id 
+		FROM article_management.media 
+		WHERE processed_at IS NULL AND content_type LIKE 'image/%' 
+		ORDER BY id ASC 
+		LIMIT $1`
+	rows, err := s.db.QueryContext(ctx, queryString, limit)
+	if err != nil {
+		return []uint{}, err
+	}
+	defer rows.Close()
+
+	ids := []uint{}
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return []uint{}, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// SaveVariants saves generated variants of a media and marks it as processed
+func (s *MediaStore) SaveVariants(ctx context.Context, m *model.Media, variants []model.MediaVariant) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.media_variants 
+			(media_id, name, storage_key, content_type, width, height, size, hash) 
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+			ON CONFLICT (media_id, name) DO UPDATE 
+			SET storage_key = EXCLUDED.storage_key, content_type = EXCLUDED.content_type, 
+			width = EXCLUDED.width, height = EXCLUDED.height, size = EXCLUDED.size, hash = EXCLUDED.hash`
+		for _, v := range variants {
+			_, err := tx.ExecContext(ctx, queryString, m.ID, v.Name, v.StorageKey, v.ContentType, v.Width, v.Height, v.Size, v.Hash)
+			if err != nil {
+				return err
+			}
+		}
+
+		queryString = `UPDATE article_management.media 
+			SET processed_at = CURRENT_TIMESTAMP 
+			WHERE id = $1`
+		_, err := tx.ExecContext(ctx, queryString, m.ID)
+		return err
+	})
+}
+
+// GetVariant finds a variant of a media by name
+func (s *MediaStore) GetVariant(ctx context.Context, mediaID uint, name string) (*model.MediaVariant, error) {
+	var v model.MediaVariant
+
+	queryString := `SELECT id, media_id, name, storage_key, content_type, width, height, size, hash, created_at 
+		FROM article_management.media_variants 
+		WHERE media_id = $1 AND name = $2`
+	err := s.db.QueryRowContext(ctx, queryString, mediaID, name).
+		Scan(
+			&v.ID,
+			&v.MediaID,
+			&v.Name,
+			&v.StorageKey,
+			&v.ContentType,
+			&v.Width,
+			&v.Height,
+			&v.Size,
+			&v.Hash,
+			&v.CreatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get media variant :%w", err)
+		}
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// GetVariants gets variants of media (by id) in a single query
+func (s *MediaStore) GetVariants(ctx context.Context, mediaIDs []uint) (map[uint][]model.MediaVariant, error) {
+	if len(mediaIDs) == 0 {
+		return map[uint][]model.MediaVariant{}, nil
+	}
+
+	queryString := `SELECT 
+		v.media_id, v.id, v.media_id, v.name, v.storage_key, v.content_type, v.width, v.height, v.size, v.hash, v.created_at 
+		FROM article_management.media_variants v 
+		WHERE v.media_id = ANY($1) 
+		ORDER BY v.id ASC`
+	return s.queryVariants(ctx, queryString, pq.Array(mediaIDs))
+}
+
+// GetAvatarVariants gets variants of avatars of users (by id) in a single query
+func (s *MediaStore) GetAvatarVariants(ctx context.Context, userIDs []uint) (map[uint][]model.MediaVariant, error) {
+	if len(userIDs) == 0 {
+		return map[uint][]model.MediaVariant{}, nil
+	}
+
+	queryString := `SELECT 
+		u.id, v.id, v.media_id, v.name, v.storage_key, v.content_type, v.width, v.height, v.size, v.hash, v.created_at 
+		FROM article_management.users u 
+		INNER JOIN article_management.media_variants v ON v.media_id = u.avatar_media_id 
+		WHERE u.id = ANY($1) 
+		ORDER BY v.id ASC`
+	return s.queryVariants(ctx, queryString, pq.Array(userIDs))
+}
+
+// GetArticleThumbnailVariants gets variants of the first image attached
+// to each of articles (by id) in a single query
+func (s *MediaStore) GetArticleThumbnailVariants(ctx context.Context, articleIDs []uint) (map[uint][]model.MediaVariant, error) {
+	if len(articleIDs) == 0 {
+		return map[uint][]model.MediaVariant{}, nil
+	}
+
+	queryString := `SELECT 
+		c.article_id, v.id, v.media_id, v.name, v.storage_key, v.content_type, v.width, v.height, v.size, v.hash, v.created_at 
+		FROM (
+			SELECT DISTINCT ON (am.article_id) am.article_id, am.media_id 
+			FROM article_management.article_media am 
+			INNER JOIN article_management.media m ON m.id = am.media_id 
+			WHERE am.article_id = ANY($1) AND m.content_type LIKE 'image/%' 
+			ORDER BY am.article_id, am.created_at ASC, am.media_id ASC
+		) c 
+		INNER JOIN article_management.media_variants v ON v.media_id = c.media_id 
+		ORDER BY v.id ASC`
+	return s.queryVariants(ctx, queryString, pq.Array(articleIDs))
+}
+
+// queryVariants runs a query selecting a key followed by variant columns and groups variants by key
+func (s *MediaStore) queryVariants(ctx context.Context, queryString string, args ...interface{}) (map[uint][]model.MediaVariant, error) {
+	variants := make(map[uint][]model.MediaVariant)
+
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return variants, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key uint
+		var v model.MediaVariant
+
+		err = rows.Scan(
+			&key,
+			&v.ID,
+			&v.MediaID,
+			&v.Name,
+			&v.StorageKey,
+			&v.ContentType,
+			&v.Width,
+			&v.Height,
+			&v.Size,
+			&v.Hash,
+			&v.CreatedAt,
+		)
+		if err != nil {
+			return variants, err
+		}
+
+		variants[key] = append(variants[key], v)
+	}
+
+	return variants, nil
+}
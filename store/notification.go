@@ -0,0 +1,283 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// NotificationStore is a data access struct for notifications
+type NotificationStore struct {
+	db *sql.DB
+}
+
+// NewNotificationStore returns a new NotificationStore
+func NewNotificationStore(db *sql.DB) *NotificationStore {
+	return &NotificationStore{db: db}
+}
+
+// GetNotifications gets notifications of the user, newest first, with the total count of them
+func (s *NotificationStore) GetNotifications(ctx context.Context, user *model.User, unreadOnly bool, limit, offset int64) ([]model.Notification, int64, error) {
+	where := ` WHERE n.user_id = $1`
+	if unreadOnly {
+		where += ` AND n.read_at IS NULL`
+	}
+
+	var totalCount int64
+
+	queryString := `SELECT COUNT(n.id) FROM article_management.notifications n` + where
+	err := s.db.QueryRowContext(ctx, queryString, user.ID).Scan(&totalCount)
+	if err != nil {
+		return []model.Notification{}, 0, err
+	}
+
+	queryString = `SELECT 
+		n.id, n.user_id, n.type, n.article_id, n.comment_id, n.read_at, n.created_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
+		FROM article_management.notifications n 
+		INNER JOIN article_management.users u ON u.id = n.actor_id` + where + `
+		ORDER BY n.created_at DESC, n.id DESC 
+		LIMIT $2 OFFSET $3`
+	rows, err := s.db.QueryContext(ctx, queryString, user.ID, limit, offset)
+	if err != nil {
+		return []model.Notification{}, 0, err
+	}
+	defer rows.Close()
+
+	notifications := make([]model.Notification, 0, limit)
+	for rows.Next() {
+		var n model.Notification
+
+		err = rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.ArticleID,
+			&n.CommentID,
+			&n.ReadAt,
+			&n.CreatedAt,
+			&n.Actor.ID,
+			&n.Actor.Username,
+			&n.Actor.Email,
+			&n.Actor.Password,
+			&n.Actor.Name,
+			&n.Actor.Bio,
+			&n.Actor.Image,
+			&n.Actor.CreatedAt,
+			&n.Actor.UpdatedAt,
+		)
+		if err != nil {
+			return []model.Notification{}, 0, err
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, totalCount, nil
+}
+
+// CountUnread counts notifications of the user which are not read yet
+func (s *NotificationStore) CountUnread(ctx context.Context, user *model.User) (int64, error) {
+	var count int64
+
+	queryString := `SELECT COUNT(id) 
+		FROM article_management.notifications 
+		WHERE user_id = $1 AND read_at IS NULL`
+	err := s.db.QueryRowContext(ctx, queryString, user.ID).Scan(&count)
+	return count, err
+}
+
+// GetByID finds a notification by id
+func (s *NotificationStore) GetByID(ctx context.Context, id uint) (*model.Notification, error) {
+	var n model.Notification
+
+	queryString := `SELECT 
+		n.id, n.user_id, n.type, n.article_id, n.comment_id, n.read_at, n.created_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
+		FROM article_management.notifications n 
+		INNER JOIN article_management.users u ON u.id = n.actor_id 
+		WHERE n.id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, id).
+		Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Type,
+			&n.ArticleID,
+			&n.CommentID,
+			&n.ReadAt,
+			&n.CreatedAt,
+			&n.Actor.ID,
+			&n.Actor.Username,
+			&n.Actor.Email,
+			&n.Actor.Password,
+			&n.Actor.Name,
+			&n.Actor.Bio,
+			&n.Actor.Image,
+			&n.Actor.CreatedAt,
+			&n.Actor.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get notification :%w", err)
+		}
+		return nil, err
+	}
+
+	return &n, nil
+}
+
+// MarkRead marks a notification read, keeping the time it was first read
+func (s *NotificationStore) MarkRead(ctx context.Context, m *model.Notification) error {
+	queryString := `UPDATE article_management.notifications 
+		SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP) 
+		WHERE id = $1 
+		RETURNING read_at`
+	return s.db.QueryRowContext(ctx, queryString, m.ID).Scan(&m.ReadAt)
+}
+
+// MarkAllRead marks all unread notifications of the user read
+func (s *NotificationStore) MarkAllRead(ctx context.Context, user *model.User) error {
+	queryString := `UPDATE article_management.notifications 
+		SET read_at = CURRENT_TIMESTAMP 
+		WHERE user_id = $1 AND read_at IS NULL`
+	_, err := s.db.ExecContext(ctx, queryString, user.ID)
+	return err
+}
+
+// GetPreferences gets which notification types the user receives
+func (s *NotificationStore) GetPreferences(ctx context.Context, user *model.User) (model.NotificationPreferences, error) {
+	queryString := `SELECT type 
+		FROM article_management.notification_opt_outs 
+		WHERE user_id = $1`
+	rows, err := s.db.QueryContext(ctx, queryString, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	optOuts := []string{}
+	for rows.Next() {
+		var t string
+
+		err = rows.Scan(&t)
+		if err != nil {
+			return nil, err
+		}
+
+		optOuts = append(optOuts, t)
+	}
+
+	return model.NewNotificationPreferences(optOuts), nil
+}
+
+// UpdatePreferences opts the user in or out of notification types, types not in preferences are kept
+func (s *NotificationStore) UpdatePreferences(ctx context.Context, user *model.User, prefs model.NotificationPreferences) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		for t, enabled := range prefs {
+			queryString := `DELETE FROM article_management.notification_opt_outs 
+				WHERE user_id = $1 AND type = $2`
+			if !enabled {
+				queryString = `INSERT INTO article_management.notification_opt_outs 
+					(user_id, type) VALUES ($1, $2) 
+					ON CONFLICT DO NOTHING`
+			}
+
+			_, err := tx.ExecContext(ctx, queryString, user.ID, t)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ConsumeEvent notifies users concerned by the domain event of it
+//
+// Events of an article or a comment which is gone by now are not notified.
+func (s *NotificationStore) ConsumeEvent(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error {
+	switch e.Type {
+	case model.DomainEventArticleFavorited:
+		return notify(tx, ctx, *e.UserID, e.ActorID, model.NotificationTypeFavorite, e.ArticleID, nil)
+	case model.DomainEventUserFollowed:
+		return notify(tx, ctx, *e.UserID, e.ActorID, model.NotificationTypeFollow, nil, nil)
+	case model.DomainEventUserMentioned:
+		return notify(tx, ctx, *e.UserID, e.ActorID, model.NotificationTypeMention, e.ArticleID, e.CommentID)
+	case model.DomainEventCommentCreated:
+		var comment model.Comment
+
+		queryString := `SELECT id, user_id, article_id, parent_id 
+			FROM article_management.comments 
+			WHERE id = $1 AND deleted_at IS NULL`
+		err := tx.QueryRowContext(ctx, queryString, *e.CommentID).
+			Scan(&comment.ID, &comment.UserID, &comment.ArticleID, &comment.ParentID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		return notifyComment(tx, ctx, &comment)
+	default:
+		return nil
+	}
+}
+
+// notifyComment notifies the author of the parent comment of a reply, and the author of
+// the article unless they were notified of the reply already
+func notifyComment(tx *sql.Tx, ctx context.Context, comment *model.Comment) error {
+	var parentAuthorID uint
+	if comment.ParentID != nil {
+		queryString := `SELECT user_id FROM article_management.comments WHERE id = $1`
+		err := tx.QueryRowContext(ctx, queryString, *comment.ParentID).Scan(&parentAuthorID)
+		if err != nil {
+			return err
+		}
+
+		err = notify(tx, ctx, parentAuthorID, comment.UserID, model.NotificationTypeReply, &comment.ArticleID, &comment.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	var articleAuthorID uint
+
+	queryString := `SELECT user_id FROM article_management.articles WHERE id = $1`
+	err := tx.QueryRowContext(ctx, queryString, comment.ArticleID).Scan(&articleAuthorID)
+	if err != nil || articleAuthorID == parentAuthorID {
+		return err
+	}
+
+	return notify(tx, ctx, articleAuthorID, comment.UserID, model.NotificationTypeComment, &comment.ArticleID, &comment.ID)
+}
+
+// notify notifies the user of what the actor did unless the user is the actor or opted out of its type,
+// muted the actor or blocks the actor either way, or its article or comment is gone, or its article
+// is of a private user whom the user does not follow
+func notify(tx *sql.Tx, ctx context.Context, userID, actorID uint, notificationType string, articleID, commentID *uint) error {
+	queryString := `INSERT INTO article_management.notifications 
+		(user_id, actor_id, type, article_id, comment_id) 
+		SELECT $1::INTEGER, $2::INTEGER, $3::VARCHAR, $4::INTEGER, $5::INTEGER 
+		WHERE $1::INTEGER <> $2::INTEGER AND NOT EXISTS ( 
+			SELECT 1 FROM article_management.notification_opt_outs o 
+			WHERE o.user_id = $1 AND o.type = $3 
+		) 
+		AND NOT EXISTS ( 
+			SELECT 1 FROM article_management.mutes mu 
+			WHERE mu.from_user_id = $1 AND mu.to_user_id = $2 
+		) 
+		AND ` + notBlockedCond("$1::INTEGER", "$2::INTEGER") + ` 
+		AND ($4::INTEGER IS NULL OR EXISTS ( 
+			SELECT 1 FROM article_management.articles a 
+			INNER JOIN article_management.users u ON u.id = a.user_id 
+			WHERE a.id = $4 AND ` + visibleAuthorCond("u", "$1::INTEGER") + ` 
+		)) 
+		AND ($5::INTEGER IS NULL OR EXISTS (SELECT 1 FROM article_management.comments c WHERE c.id = $5))`
+	_, err := tx.ExecContext(ctx, queryString, userID, actorID, notificationType, articleID, commentID)
+	return err
+}
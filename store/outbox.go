@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// OutboxStore is a data access struct for domain events of outbox and checkpoints of their consumers
+type OutboxStore struct {
+	db *sql.DB
+}
+
+// NewOutboxStore returns a new OutboxStore
+func NewOutboxStore(db *sql.DB) *OutboxStore {
+	return &OutboxStore{db: db}
+}
+
+// ConsumeEvents passes up to limit events after the checkpoint of the consumer to fn
+// in order, and returns how many of them were consumed
+//
+// Changes made by fn in tx are committed along with the checkpoint, so every event
+// is consumed exactly once by each consumer. When fn fails, its changes are rolled
+// back and the events consumed before it are kept, the failed one is passed again
+// next time. Concurrent calls for the same consumer wait for each other.
+//
+// Events are only consumed once all transactions started before theirs are finished, so any
+// transaction open in the cluster (such as a session idle in a transaction, a long VACUUM
+// or pg_dump) holds back the events of later ones until it is finished.
+func (s *OutboxStore) ConsumeEvents(ctx context.Context, consumer string, limit int64, fn func(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error) (int, error) {
+	var consumed int
+	var consumeErr error
+
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.outbox_checkpoints (consumer) VALUES ($1) 
+			ON CONFLICT (consumer) DO NOTHING`
+		_, err := tx.ExecContext(ctx, queryString, consumer)
+		if err != nil {
+			return err
+		}
+
+		var lastTxID string
+		var lastEventID uint
+
+		queryString = `SELECT last_tx_id, last_event_id 
+			FROM article_management.outbox_checkpoints 
+			WHERE consumer = $1 
+			FOR UPDATE`
+		err = tx.QueryRowContext(ctx, queryString, consumer).Scan(&lastTxID, &lastEventID)
+		if err != nil {
+			return err
+		}
+
+		// events of a transaction are only visible once all transactions which
+		// started before it are finished, so that none is visible after a later one
+		queryString = `SELECT id, tx_id, type, actor_id, user_id, article_id, comment_id, payload, created_at 
+			FROM article_management.outbox 
+			WHERE (tx_id, id) > ($1::XID8, $2) AND tx_id < pg_snapshot_xmin(pg_current_snapshot()) 
+			ORDER BY tx_id ASC, id ASC 
+			LIMIT $3`
+		rows, err := tx.QueryContext(ctx, queryString, lastTxID, lastEventID, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		events := make([]model.DomainEvent, 0, limit)
+		txIDs := make([]string, 0, limit)
+		for rows.Next() {
+			var e model.DomainEvent
+			var txID string
+
+			err = rows.Scan(
+				&e.ID,
+				&txID,
+				&e.Type,
+				&e.ActorID,
+				&e.UserID,
+				&e.ArticleID,
+				&e.CommentID,
+				&e.Payload,
+				&e.CreatedAt,
+			)
+			if err != nil {
+				return err
+			}
+
+			events = append(events, e)
+			txIDs = append(txIDs, txID)
+		}
+
+		err = rows.Close()
+		if err != nil {
+			return err
+		}
+
+		for i := range events {
+			_, err = tx.ExecContext(ctx, `SAVEPOINT event`)
+			if err != nil {
+				return err
+			}
+
+			err = fn(tx, ctx, &events[i])
+			if err != nil {
+				consumeErr = fmt.Errorf("failed to consume event (id=%d) :%w", events[i].ID, err)
+
+				_, err = tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT event`)
+				if err != nil {
+					return errors.Join(consumeErr, err)
+				}
+				break
+			}
+
+			lastTxID, lastEventID = txIDs[i], events[i].ID
+			consumed++
+		}
+
+		if consumed == 0 {
+			return nil
+		}
+
+		queryString = `UPDATE article_management.outbox_checkpoints 
+			SET last_tx_id = $1::XID8, last_event_id = $2, updated_at = DEFAULT 
+			WHERE consumer = $3`
+		_, err = tx.ExecContext(ctx, queryString, lastTxID, lastEventID, consumer)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return consumed, consumeErr
+}
+
+// DeleteConsumedEventsBefore deletes events created before the time which all consumers consumed
+func (s *OutboxStore) DeleteConsumedEventsBefore(ctx context.Context, consumers []string, before time.Time) (int64, error) {
+	queryString := `DELETE FROM article_management.outbox o 
+		WHERE o.created_at < $1 
+		AND (SELECT COUNT(*) FROM article_management.outbox_checkpoints WHERE consumer = ANY($2)) = CARDINALITY($2::VARCHAR[]) 
+		AND NOT EXISTS ( 
+			SELECT 1 FROM article_management.outbox_checkpoints c 
+			WHERE c.consumer = ANY($2) AND (c.last_tx_id, c.last_event_id) < (o.tx_id, o.id) 
+		)`
+	result, err := s.db.ExecContext(ctx, queryString, before, pq.Array(consumers))
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// writeEvent writes a domain event to outbox, to be consumed once the transaction is committed
+func writeEvent(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error {
+	queryString := `INSERT INTO article_management.outbox 
+		(type, actor_id, user_id, article_id, comment_id, payload) VALUES ($1, $2, $3, $4, $5, $6::JSONB)`
+	_, err := tx.ExecContext(ctx, queryString, e.Type, e.ActorID, e.UserID, e.ArticleID, e.CommentID, string(e.Payload))
+	return err
+}
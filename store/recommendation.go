@@ -0,0 +1,128 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// RecommendationStore is a data access struct for related articles and suggested follows
+//
+// Recommendations are read from materialized views, so they are only as recent
+// as their last refresh.
+type RecommendationStore struct {
+	db *sql.DB
+}
+
+// NewRecommendationStore returns a new RecommendationStore
+func NewRecommendationStore(db *sql.DB) *RecommendationStore {
+	return &RecommendationStore{db: db}
+}
+
+// Refresh recomputes related articles and suggested follows without blocking reads of them
+func (s *RecommendationStore) Refresh(ctx context.Context) error {
+	for _, view := range []string{"related_articles", "suggested_follows"} {
+		_, err := s.db.ExecContext(ctx, `REFRESH MATERIALIZED VIEW CONCURRENTLY article_management.`+view)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetRelatedArticles gets articles related to the article, most related first, with the total count of them
+//
+// Articles hidden from viewer are left out as they are by GetArticles.
+func (s *RecommendationStore) GetRelatedArticles(ctx context.Context, m *model.Article, viewer *model.User, limit, offset int64) ([]model.Article, int64, error) {
+	var count int64
+
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	from := ` FROM article_management.related_articles r 
+		INNER JOIN article_management.articles a ON a.id = r.related_article_id 
+		INNER JOIN article_management.users u ON u.id = a.user_id `
+	where := ` WHERE r.article_id = $1 
+		AND ` + notBlockedCond("a.user_id", "$2") + ` 
+		AND ` + visibleAuthorCond("u", "$2")
+
+	queryString := `SELECT COUNT(*)` + from + where
+	err := s.db.QueryRowContext(ctx, queryString, m.ID, viewerID).Scan(&count)
+	if err != nil {
+		return []model.Article{}, 0, err
+	}
+
+	queryString = `SELECT 
+		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, cc.comments_count, a.created_at, a.updated_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at` +
+		from + commentsCountJoin + where + ` 
+		ORDER BY r.score DESC, a.created_at DESC, a.id DESC 
+		LIMIT $3 OFFSET $4`
+	articles, err := queryArticles(s.db, ctx, queryString, m.ID, viewerID, limit, offset)
+	if err != nil {
+		return []model.Article{}, 0, err
+	}
+
+	return articles, count, nil
+}
+
+// GetSuggestedUsers gets users suggested for the user to follow, most suggested first, with the total count of them
+//
+// Users the user started following since the last refresh, and users blocking or
+// blocked by the user are left out.
+func (s *RecommendationStore) GetSuggestedUsers(ctx context.Context, m *model.User, limit, offset int64) ([]model.User, int64, error) {
+	var count int64
+
+	from := ` FROM article_management.suggested_follows sf 
+		INNER JOIN article_management.users u ON u.id = sf.suggested_user_id 
+		WHERE sf.user_id = $1 
+		AND NOT EXISTS ( 
+			SELECT 1 FROM article_management.follows f 
+			WHERE f.from_user_id = $1 AND f.to_user_id = u.id 
+		) 
+		AND ` + notBlockedCond("u.id", "$1")
+
+	queryString := `SELECT COUNT(*)` + from
+	err := s.db.QueryRowContext(ctx, queryString, m.ID).Scan(&count)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+
+	queryString = `SELECT 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at` + from + ` 
+		ORDER BY sf.score DESC, u.id DESC 
+		LIMIT $2 OFFSET $3`
+	rows, err := s.db.QueryContext(ctx, queryString, m.ID, limit, offset)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, limit)
+	for rows.Next() {
+		var user model.User
+
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Bio,
+			&user.Image,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return []model.User{}, 0, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, count, nil
+}
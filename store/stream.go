@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// StreamChannel is the postgres channel ids of new stream events are notified on
+const StreamChannel = "article_management_stream"
+
+// StreamStore is a data access struct for stream events
+type StreamStore struct {
+	db *sql.DB
+}
+
+// NewStreamStore returns a new StreamStore
+func NewStreamStore(db *sql.DB) *StreamStore {
+	return &StreamStore{db: db}
+}
+
+// GetLastPosition gets tx id and id of the latest stream event in order of transactions,
+// or zeros if there is none
+//
+// Events of transactions which are not finished yet, or which started after one that
+// is not finished yet, are left for the next events after the position.
+func (s *StreamStore) GetLastPosition(ctx context.Context) (string, uint, error) {
+	var txID string
+	var id uint
+
+	queryString := `SELECT tx_id, id 
+		FROM article_management.stream_events 
+		WHERE ` + finishedTxCond + ` 
+		ORDER BY tx_id DESC, id DESC 
+		LIMIT 1`
+	err := s.db.QueryRowContext(ctx, queryString).Scan(&txID, &id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "0", 0, nil
+		}
+		return "", 0, err
+	}
+
+	return txID, id, nil
+}
+
+// GetEventsAfter gets stream events after the position of tx id and id in order of transactions,
+// with tx id of the last one (or the tx id given if there is none)
+func (s *StreamStore) GetEventsAfter(ctx context.Context, lastTxID string, lastID uint, limit int64) ([]model.StreamEvent, string, error) {
+	queryString := `SELECT id, tx_id, type, user_id, article_id, target_id, created_at 
+		FROM article_management.stream_events 
+		WHERE (tx_id, id) > ($1::XID8, $2) AND ` + finishedTxCond + ` 
+		ORDER BY tx_id ASC, id ASC 
+		LIMIT $3`
+	events, txID, err := s.getEvents(ctx, queryString, lastTxID, lastID, limit)
+	if err != nil {
+		return []model.StreamEvent{}, "", err
+	}
+
+	if len(events) == 0 {
+		txID = lastTxID
+	}
+
+	return events, txID, nil
+}
+
+// GetUserEventsAfter gets stream events after the event of id in order of transactions,
+// pushed to the user viewing an article (if any)
+//
+// Events are gotten from the start if the event of id is gone.
+func (s *StreamStore) GetUserEventsAfter(ctx context.Context, lastID, userID uint, articleID *uint, limit int64) ([]model.StreamEvent, error) {
+	queryString := `SELECT id, tx_id, type, user_id, article_id, target_id, created_at 
+		FROM article_management.stream_events 
+		WHERE (tx_id, id) > ( 
+			COALESCE((SELECT l.tx_id FROM article_management.stream_events l WHERE l.id = $1), '0'::XID8), $1 
+		) 
+		AND ` + finishedTxCond + ` 
+		AND ( 
+			(type = $2 AND user_id = $3) OR 
+			(type IN ($4, $5) AND article_id = $6::INTEGER) 
+		) 
+		ORDER BY tx_id ASC, id ASC 
+		LIMIT $7`
+	events, _, err := s.getEvents(ctx, queryString,
+		lastID, model.StreamEventNotification, userID,
+		model.StreamEventComment, model.StreamEventFavorites, articleID,
+		limit,
+	)
+	return events, err
+}
+
+// DeleteEventsBefore deletes stream events created before the time, which can no longer be replayed
+func (s *StreamStore) DeleteEventsBefore(ctx context.Context, t time.Time) (int64, error) {
+	queryString := `DELETE FROM article_management.stream_events WHERE created_at < $1`
+	result, err := s.db.ExecContext(ctx, queryString, t)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// finishedTxCond holds for stream events whose transaction and all transactions started before it
+// are finished, so that none is gotten after an event of a later transaction
+//
+// Any transaction open in the cluster holds back the events of later ones, even one which writes
+// no event (such as a session idle in a transaction, a long VACUUM or pg_dump), until it is finished.
+// Sessions of the app are ended after being idle in a transaction for a while (see db.New).
+const finishedTxCond = `tx_id < pg_snapshot_xmin(pg_current_snapshot())`
+
+// getEvents gets stream events with tx id of the last one
+func (s *StreamStore) getEvents(ctx context.Context, queryString string, args ...interface{}) ([]model.StreamEvent, string, error) {
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return []model.StreamEvent{}, "", err
+	}
+	defer rows.Close()
+
+	var lastTxID string
+
+	events := []model.StreamEvent{}
+	for rows.Next() {
+		var e model.StreamEvent
+
+		err = rows.Scan(&e.ID, &lastTxID, &e.Type, &e.UserID, &e.ArticleID, &e.TargetID, &e.CreatedAt)
+		if err != nil {
+			return []model.StreamEvent{}, "", err
+		}
+
+		events = append(events, e)
+	}
+
+	return events, lastTxID, nil
+}
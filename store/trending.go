@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// TrendingStore is a data access struct for article activity and trending scores
+type TrendingStore struct {
+	db *sql.DB
+}
+
+// NewTrendingStore returns a new TrendingStore
+func NewTrendingStore(db *sql.DB) *TrendingStore {
+	return &TrendingStore{db: db}
+}
+
+// RecordView counts a view of the article by the viewer key in the activity of current hour,
+// where each viewer counts once per hour and authors viewing their own articles do not count
+func (s *TrendingStore) RecordView(ctx context.Context, article *model.Article, viewer *model.User, viewerKey string) error {
+	if viewer != nil && viewer.ID == article.UserID {
+		return nil
+	}
+
+	queryString := `WITH viewed AS ( 
+			INSERT INTO article_management.article_views (article_id, bucket_start, viewer) 
+			VALUES ($1, date_trunc('hour', CURRENT_TIMESTAMP), $2) 
+			ON CONFLICT DO NOTHING 
+			RETURNING article_id, bucket_start 
+		) 
+		INSERT INTO article_management.article_activity (article_id, bucket_start, views) 
+		SELECT article_id, bucket_start, 1 FROM viewed 
+		ON CONFLICT (article_id, bucket_start) DO UPDATE SET views = article_activity.views + 1`
+	_, err := s.db.ExecContext(ctx, queryString, article.ID, viewerKey)
+	return err
+}
+
+// ConsumeEvent counts favorites and comments of domain events in the activity of the hour they happened,
+// taking them back when they are undone
+func (s *TrendingStore) ConsumeEvent(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error {
+	var column string
+	var delta int
+
+	switch e.Type {
+	case model.DomainEventArticleFavorited:
+		column, delta = "favorites", 1
+	case model.DomainEventArticleUnfavorited:
+		column, delta = "favorites", -1
+	case model.DomainEventCommentCreated:
+		column, delta = "comments", 1
+	case model.DomainEventCommentDeleted:
+		column, delta = "comments", -1
+	default:
+		return nil
+	}
+
+	// the article may be gone by the time the event is consumed
+	queryString := `INSERT INTO article_management.article_activity (article_id, bucket_start, ` + column + `) 
+		SELECT a.id, date_trunc('hour', $2::TIMESTAMPTZ), $3 
+		FROM article_management.articles a 
+		WHERE a.id = $1 
+		ON CONFLICT (article_id, bucket_start) DO UPDATE SET ` + column + ` = article_activity.` + column + ` + EXCLUDED.` + column
+	_, err := tx.ExecContext(ctx, queryString, *e.ArticleID, e.CreatedAt, delta)
+	return err
+}
+
+// Recompute ranks articles by their activity in each trending window at now, and deletes
+// activity older than every window
+//
+// Only articles with activity in a window are ranked in it, so the cost follows
+// how much recent activity there is rather than how many articles there are.
+func (s *TrendingStore) Recompute(ctx context.Context, now time.Time) error {
+	var longest time.Duration
+
+	for _, w := range model.TrendingWindows {
+		err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+			queryString := `INSERT INTO article_management.trending_scores (trending_window, article_id, score, computed_at) 
+				SELECT $1, aa.article_id, 
+				SUM((aa.views * $4 + aa.comments * $5 + aa.favorites * $6) 
+					* POWER(0.5, EXTRACT(EPOCH FROM ($2 - aa.bucket_start)) / $3)) AS score, 
+				$2 
+				FROM article_management.article_activity aa 
+				WHERE aa.bucket_start > $2 - make_interval(secs => $7) AND aa.bucket_start <= $2 
+				GROUP BY aa.article_id 
+				HAVING SUM(aa.views * $4 + aa.comments * $5 + aa.favorites * $6) > 0 
+				ON CONFLICT (trending_window, article_id) 
+				DO UPDATE SET score = EXCLUDED.score, computed_at = EXCLUDED.computed_at`
+			_, err := tx.ExecContext(ctx, queryString,
+				w.Name,
+				now,
+				w.HalfLife.Seconds(),
+				model.TrendingWeightView,
+				model.TrendingWeightComment,
+				model.TrendingWeightFavorite,
+				w.Period.Seconds(),
+			)
+			if err != nil {
+				return err
+			}
+
+			// articles without activity in the window since are no longer trending
+			queryString = `DELETE FROM article_management.trending_scores 
+				WHERE trending_window = $1 AND computed_at < $2`
+			_, err = tx.ExecContext(ctx, queryString, w.Name, now)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		longest = max(longest, w.Period)
+	}
+
+	queryString := `DELETE FROM article_management.article_activity 
+		WHERE bucket_start <= $1`
+	_, err := s.db.ExecContext(ctx, queryString, now.Add(-longest-time.Hour))
+	if err != nil {
+		return err
+	}
+
+	// viewers are only told apart within the bucket being counted
+	queryString = `DELETE FROM article_management.article_views 
+		WHERE bucket_start < $1`
+	_, err = s.db.ExecContext(ctx, queryString, now.Truncate(time.Hour).Add(-time.Hour))
+	return err
+}
+
+// GetTrendingArticles gets articles ranked in the trending window as of the last recompute,
+// highest score first, with the total count of them
+//
+// Articles hidden from viewer are left out as they are by GetArticles.
+func (s *TrendingStore) GetTrendingArticles(ctx context.Context, window string, viewer *model.User, limit, offset int64) ([]model.Article, int64, error) {
+	var count int64
+
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	from := ` FROM article_management.trending_scores ts 
+		INNER JOIN article_management.articles a ON a.id = ts.article_id 
+		INNER JOIN article_management.users u ON u.id = a.user_id `
+	where := ` WHERE ts.trending_window = $1 
+		AND ` + notBlockedCond("a.user_id", "$2") + ` 
+		AND ` + visibleAuthorCond("u", "$2")
+
+	queryString := `SELECT COUNT(*)` + from + where
+	err := s.db.QueryRowContext(ctx, queryString, window, viewerID).Scan(&count)
+	if err != nil {
+		return []model.Article{}, 0, err
+	}
+
+	queryString = `SELECT 
+		a.id, a.title, a.description, a.body, a.user_id, a.favorites_count, cc.comments_count, a.created_at, a.updated_at, 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at` +
+		from + commentsCountJoin + where + ` 
+		ORDER BY ts.score DESC, a.id DESC 
+		LIMIT $3 OFFSET $4`
+	articles, err := queryArticles(s.db, ctx, queryString, window, viewerID, limit, offset)
+	if err != nil {
+		return []model.Article{}, 0, err
+	}
+
+	return articles, count, nil
+}
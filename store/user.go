@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/nathanbizkit/article-management-go/db"
 	"github.com/nathanbizkit/article-management-go/model"
 )
@@ -25,7 +26,7 @@ func (s *UserStore) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
 
 	queryString := `SELECT 
-		id, username, email, password, name, bio, image, created_at, updated_at 
+		id, username, email, password, name, bio, image, private, created_at, updated_at 
 		FROM article_management.users 
 		WHERE id = $1`
 	err := s.db.QueryRowContext(ctx, queryString, id).
@@ -37,6 +38,7 @@ func (s *UserStore) GetByID(ctx context.Context, id uint) (*model.User, error) {
 			&user.Name,
 			&user.Bio,
 			&user.Image,
+			&user.Private,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -55,7 +57,7 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*model.User,
 	var user model.User
 
 	queryString := `SELECT 
-		id, username, email, password, name, bio, image, created_at, updated_at 
+		id, username, email, password, name, bio, image, private, created_at, updated_at 
 		FROM article_management.users 
 		WHERE email = $1`
 	err := s.db.QueryRowContext(ctx, queryString, email).
@@ -67,6 +69,7 @@ func (s *UserStore) GetByEmail(ctx context.Context, email string) (*model.User,
 			&user.Name,
 			&user.Bio,
 			&user.Image,
+			&user.Private,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -85,7 +88,7 @@ func (s *UserStore) GetByUsername(ctx context.Context, username string) (*model.
 	var user model.User
 
 	queryString := `SELECT 
-		id, username, email, password, name, bio, image, created_at, updated_at 
+		id, username, email, password, name, bio, image, private, created_at, updated_at 
 		FROM article_management.users 
 		WHERE username = $1`
 	err := s.db.QueryRowContext(ctx, queryString, username).
@@ -97,6 +100,7 @@ func (s *UserStore) GetByUsername(ctx context.Context, username string) (*model.
 			&user.Name,
 			&user.Bio,
 			&user.Image,
+			&user.Private,
 			&user.CreatedAt,
 			&user.UpdatedAt,
 		)
@@ -117,7 +121,7 @@ func (s *UserStore) Create(ctx context.Context, m *model.User) (*model.User, err
 	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
 		queryString := `INSERT INTO article_management.users 
 			(username, email, password, name, bio, image) VALUES ($1, $2, $3, $4, $5, $6) 
-			RETURNING id, username, email, password, name, bio, image, created_at, updated_at`
+			RETURNING id, username, email, password, name, bio, image, private, created_at, updated_at`
 		err := tx.QueryRowContext(ctx, queryString, m.Username, m.Email, m.Password, m.Name, m.Bio, m.Image).
 			Scan(
 				&user.ID,
@@ -127,6 +131,7 @@ func (s *UserStore) Create(ctx context.Context, m *model.User) (*model.User, err
 				&user.Name,
 				&user.Bio,
 				&user.Image,
+				&user.Private,
 				&user.CreatedAt,
 				&user.UpdatedAt,
 			)
@@ -143,16 +148,19 @@ func (s *UserStore) Create(ctx context.Context, m *model.User) (*model.User, err
 	return &user, err
 }
 
-// Update updates a user (for username, email, password, name, bio, image)
+// Update updates a user (for username, email, password, name, bio, image, private)
+//
+// Avatar media of user is unset once image no longer points to it.
 func (s *UserStore) Update(ctx context.Context, m *model.User) (*model.User, error) {
 	var user model.User
 
 	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
 		queryString := `UPDATE article_management.users 
-			SET username = $1, email = $2, password = $3, name = $4, bio = $5, image = $6, updated_at = DEFAULT 
-			WHERE id = $7 
-			RETURNING id, username, email, password, name, bio, image, created_at, updated_at`
-		err := tx.QueryRowContext(ctx, queryString, m.Username, m.Email, m.Password, m.Name, m.Bio, m.Image, m.ID).
+			SET username = $1, email = $2, password = $3, name = $4, bio = $5, image = $6, private = $7, 
+			avatar_media_id = CASE WHEN image = $6 THEN avatar_media_id END, updated_at = DEFAULT 
+			WHERE id = $8 
+			RETURNING id, username, email, password, name, bio, image, private, created_at, updated_at`
+		err := tx.QueryRowContext(ctx, queryString, m.Username, m.Email, m.Password, m.Name, m.Bio, m.Image, m.Private, m.ID).
 			Scan(
 				&user.ID,
 				&user.Username,
@@ -161,6 +169,42 @@ func (s *UserStore) Update(ctx context.Context, m *model.User) (*model.User, err
 				&user.Name,
 				&user.Bio,
 				&user.Image,
+				&user.Private,
+				&user.CreatedAt,
+				&user.UpdatedAt,
+			)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				err = fmt.Errorf("failed to retrieve newly updated user :%w", err)
+			}
+			return err
+		}
+
+		return nil
+	})
+
+	return &user, err
+}
+
+// UpdateAvatar sets an image media as avatar of a user, image is set to url of media
+func (s *UserStore) UpdateAvatar(ctx context.Context, m *model.User, media *model.Media, image string) (*model.User, error) {
+	var user model.User
+
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `UPDATE article_management.users 
+			SET image = $1, avatar_media_id = $2, updated_at = DEFAULT 
+			WHERE id = $3 
+			RETURNING id, username, email, password, name, bio, image, private, created_at, updated_at`
+		err := tx.QueryRowContext(ctx, queryString, image, media.ID, m.ID).
+			Scan(
+				&user.ID,
+				&user.Username,
+				&user.Email,
+				&user.Password,
+				&user.Name,
+				&user.Bio,
+				&user.Image,
+				&user.Private,
 				&user.CreatedAt,
 				&user.UpdatedAt,
 			)
@@ -196,13 +240,238 @@ func (s *UserStore) IsFollowing(ctx context.Context, a *model.User, b *model.Use
 	return count != 0, nil
 }
 
-// Follow creates a follow relationship from user A to user B
+// AreFollowing returns which of users (by id) user A follows in a single query
+func (s *UserStore) AreFollowing(ctx context.Context, a *model.User, userIDs []uint) (map[uint]bool, error) {
+	following := make(map[uint]bool)
+	if a == nil || len(userIDs) == 0 {
+		return following, nil
+	}
+
+	queryString := `SELECT to_user_id 
+		FROM article_management.follows 
+		WHERE from_user_id = $1 AND to_user_id = ANY($2)`
+	rows, err := s.db.QueryContext(ctx, queryString, a.ID, pq.Array(userIDs))
+	if err != nil {
+		return following, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return following, err
+		}
+
+		following[id] = true
+	}
+
+	return following, nil
+}
+
+// AreFollowedBy returns which of users (by id) follow user A in a single query
+func (s *UserStore) AreFollowedBy(ctx context.Context, a *model.User, userIDs []uint) (map[uint]bool, error) {
+	followedBy := make(map[uint]bool)
+	if a == nil || len(userIDs) == 0 {
+		return followedBy, nil
+	}
+
+	queryString := `SELECT from_user_id 
+		FROM article_management.follows 
+		WHERE to_user_id = $1 AND from_user_id = ANY($2)`
+	rows, err := s.db.QueryContext(ctx, queryString, a.ID, pq.Array(userIDs))
+	if err != nil {
+		return followedBy, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return followedBy, err
+		}
+
+		followedBy[id] = true
+	}
+
+	return followedBy, nil
+}
+
+// GetProfileStats counts followers, followed users and articles of the user
+func (s *UserStore) GetProfileStats(ctx context.Context, m *model.User) (*model.ProfileStats, error) {
+	var stats model.ProfileStats
+
+	queryString := `SELECT 
+		(SELECT COUNT(*) FROM article_management.follows WHERE to_user_id = $1), 
+		(SELECT COUNT(*) FROM article_management.follows WHERE from_user_id = $1), 
+		(SELECT COUNT(*) FROM article_management.articles WHERE user_id = $1)`
+	err := s.db.QueryRowContext(ctx, queryString, m.ID).
+		Scan(&stats.FollowersCount, &stats.FollowingCount, &stats.ArticlesCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stats, nil
+}
+
+// GetFollowers gets users following the user, except those blocked by or blocking the viewer,
+// newest follow first, with the total count of them
+func (s *UserStore) GetFollowers(ctx context.Context, m *model.User, viewer *model.User, limit, offset int64) ([]model.User, int64, error) {
+	return s.getRelatedUsers(ctx, "article_management.follows", "to_user_id", "from_user_id", m, viewer, limit, offset)
+}
+
+// GetFollowing gets users the user follows, except those blocked by or blocking the viewer,
+// newest follow first, with the total count of them
+func (s *UserStore) GetFollowing(ctx context.Context, m *model.User, viewer *model.User, limit, offset int64) ([]model.User, int64, error) {
+	return s.getRelatedUsers(ctx, "article_management.follows", "from_user_id", "to_user_id", m, viewer, limit, offset)
+}
+
+// getRelatedUsers gets users on the other side (column) of relationships in table
+// of the user on one side (userColumn), newest relationship first
+//
+// Users blocked by or blocking the viewer (if any) are left out.
+func (s *UserStore) getRelatedUsers(ctx context.Context, table, userColumn, column string, m *model.User, viewer *model.User, limit, offset int64) ([]model.User, int64, error) {
+	where := fmt.Sprintf("r.%s = $1", userColumn)
+	args := []interface{}{m.ID}
+
+	if viewer != nil {
+		args = append(args, viewer.ID)
+		where += " AND " + notBlockedCond("r."+column, "$2")
+	}
+
+	var count int64
+
+	queryString := fmt.Sprintf(`SELECT COUNT(*) FROM %s r WHERE %s`, table, where)
+	err := s.db.QueryRowContext(ctx, queryString, args...).Scan(&count)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+
+	queryString = fmt.Sprintf(`SELECT 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at 
+		FROM %s r 
+		INNER JOIN article_management.users u ON u.id = r.%s 
+		WHERE %s 
+		ORDER BY r.created_at DESC, u.id DESC 
+		LIMIT $%d OFFSET $%d`, table, column, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+	rows, err := s.db.QueryContext(ctx, queryString, args...)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, limit)
+	for rows.Next() {
+		var user model.User
+
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Bio,
+			&user.Image,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return []model.User{}, 0, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, count, nil
+}
+
+// SearchProfiles finds users matching the profile search, except those blocking or blocked by
+// the viewer (if any), with the total count of them
+//
+// There is no suspension of users, so no suspended users are left out yet.
+func (s *UserStore) SearchProfiles(ctx context.Context, search model.ProfileSearch, limit, offset int64) ([]model.User, int64, error) {
+	var count int64
+
+	var viewerID uint
+	if search.Viewer != nil {
+		viewerID = search.Viewer.ID
+	}
+
+	// <% holds when a word of the right side is similar to the query on the left side
+	where := ` FROM article_management.users u 
+		WHERE ($1 <% u.username OR $1 <% u.name OR $1 <% u.bio) 
+		AND ` + notBlockedCond("u.id", "$2")
+
+	queryString := `SELECT COUNT(*)` + where
+	err := s.db.QueryRowContext(ctx, queryString, search.Query, viewerID).Scan(&count)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+
+	orderBy := `GREATEST(word_similarity($1, u.username), word_similarity($1, u.name), word_similarity($1, u.bio)) DESC`
+	if search.Sort == model.ProfileSortFollowers {
+		orderBy = `(SELECT COUNT(*) FROM article_management.follows f WHERE f.to_user_id = u.id) DESC`
+	}
+
+	queryString = `SELECT 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.created_at, u.updated_at` + where + ` 
+		ORDER BY ` + orderBy + `, u.id DESC 
+		LIMIT $3 OFFSET $4`
+	rows, err := s.db.QueryContext(ctx, queryString, search.Query, viewerID, limit, offset)
+	if err != nil {
+		return []model.User{}, 0, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0, limit)
+	for rows.Next() {
+		var user model.User
+
+		err = rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Bio,
+			&user.Image,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+		if err != nil {
+			return []model.User{}, 0, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, count, nil
+}
+
+// Follow creates a follow relationship from user A to user B, approving a pending request of user A,
+// user B is notified once the event is relayed
 func (s *UserStore) Follow(ctx context.Context, a *model.User, b *model.User) error {
 	return db.RunInTx(s.db, func(tx *sql.Tx) error {
 		queryString := `INSERT INTO article_management.follows 
 			(from_user_id, to_user_id) VALUES ($1, $2)`
 		_, err := tx.ExecContext(ctx, queryString, a.ID, b.ID)
-		return err
+		if err != nil {
+			return err
+		}
+
+		queryString = `DELETE FROM article_management.follow_requests 
+			WHERE from_user_id = $1 AND to_user_id = $2`
+		_, err = tx.ExecContext(ctx, queryString, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+
+		e := model.NewFollowEvent(model.DomainEventUserFollowed, a, b)
+		return writeEvent(tx, ctx, &e)
 	})
 }
 
@@ -211,11 +480,87 @@ func (s *UserStore) Unfollow(ctx context.Context, a *model.User, b *model.User)
 	return db.RunInTx(s.db, func(tx *sql.Tx) error {
 		queryString := `DELETE FROM article_management.follows 
 			WHERE from_user_id = $1 AND to_user_id = $2`
-		_, err := tx.ExecContext(ctx, queryString, a.ID, b.ID)
-		return err
+		result, err := tx.ExecContext(ctx, queryString, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil || deleted == 0 {
+			return err
+		}
+
+		e := model.NewFollowEvent(model.DomainEventUserUnfollowed, a, b)
+		return writeEvent(tx, ctx, &e)
 	})
 }
 
+// HasFollowRequest returns whether user A requested to follow user B
+func (s *UserStore) HasFollowRequest(ctx context.Context, a *model.User, b *model.User) (bool, error) {
+	var requested bool
+
+	queryString := `SELECT EXISTS ( 
+		SELECT 1 FROM article_management.follow_requests 
+		WHERE from_user_id = $1 AND to_user_id = $2 
+	)`
+	err := s.db.QueryRowContext(ctx, queryString, a.ID, b.ID).Scan(&requested)
+	return requested, err
+}
+
+// GetFollowRequests gets users requesting to follow the user, newest request first, with the total count of them
+func (s *UserStore) GetFollowRequests(ctx context.Context, m *model.User, limit, offset int64) ([]model.User, int64, error) {
+	return s.getRelatedUsers(ctx, "article_management.follow_requests", "to_user_id", "from_user_id", m, nil, limit, offset)
+}
+
+// RequestFollow creates a request of user A to follow user B, requesting again does nothing
+func (s *UserStore) RequestFollow(ctx context.Context, a *model.User, b *model.User) error {
+	queryString := `INSERT INTO article_management.follow_requests 
+		(from_user_id, to_user_id) VALUES ($1, $2) 
+		ON CONFLICT DO NOTHING`
+	_, err := s.db.ExecContext(ctx, queryString, a.ID, b.ID)
+	return err
+}
+
+// DeleteFollowRequest deletes a request of user A to follow user B, either cancelled by user A
+// or rejected by user B, and returns whether there was one
+func (s *UserStore) DeleteFollowRequest(ctx context.Context, a *model.User, b *model.User) (bool, error) {
+	queryString := `DELETE FROM article_management.follow_requests 
+		WHERE from_user_id = $1 AND to_user_id = $2`
+	result, err := s.db.ExecContext(ctx, queryString, a.ID, b.ID)
+	if err != nil {
+		return false, err
+	}
+
+	deleted, err := result.RowsAffected()
+	return deleted != 0, err
+}
+
+// CanViewArticlesOf returns whether articles of the author are visible to the viewer,
+// which holds unless a block is between them either way, or the author is private and
+// the viewer is neither the author nor a follower
+func (s *UserStore) CanViewArticlesOf(ctx context.Context, viewer *model.User, author *model.User) (bool, error) {
+	// without a viewer, no user id matches the follower condition
+	var viewerID uint
+	if viewer != nil {
+		viewerID = viewer.ID
+	}
+
+	var visible bool
+
+	queryString := `SELECT ` + visibleAuthorCond("u", "$2") + ` AND ` + notBlockedCond("u.id", "$2") + ` 
+		FROM article_management.users u 
+		WHERE u.id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, author.ID, viewerID).Scan(&visible)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get user :%w", err)
+		}
+		return false, err
+	}
+
+	return visible, nil
+}
+
 // GetFollowingUserIDs returns user ids that current user follows
 func (s *UserStore) GetFollowingUserIDs(ctx context.Context, m *model.User) ([]uint, error) {
 	queryString := `SELECT to_user_id 
@@ -241,3 +586,247 @@ func (s *UserStore) GetFollowingUserIDs(ctx context.Context, m *model.User) ([]u
 
 	return ids, nil
 }
+
+// GetFeedUserIDs returns user ids whose articles are in feed of the user,
+// which are users the user follows except those muted or blocked either way
+func (s *UserStore) GetFeedUserIDs(ctx context.Context, m *model.User) ([]uint, error) {
+	queryString := `SELECT f.to_user_id 
+		FROM article_management.follows f 
+		WHERE f.from_user_id = $1 
+		AND NOT EXISTS ( 
+			SELECT 1 FROM article_management.mutes mu 
+			WHERE mu.from_user_id = $1 AND mu.to_user_id = f.to_user_id 
+		) 
+		AND ` + notBlockedCond("f.to_user_id", "$1")
+	rows, err := s.db.QueryContext(ctx, queryString, m.ID)
+	if err != nil {
+		return []uint{}, err
+	}
+	defer rows.Close()
+
+	ids := []uint{}
+	for rows.Next() {
+		var id uint
+
+		err = rows.Scan(&id)
+		if err != nil {
+			return []uint{}, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// IsBlockedBetween returns whether user A blocks user B or user B blocks user A
+func (s *UserStore) IsBlockedBetween(ctx context.Context, a *model.User, b *model.User) (bool, error) {
+	if a == nil || b == nil {
+		return false, nil
+	}
+
+	var blocked bool
+
+	queryString := `SELECT EXISTS ( 
+		SELECT 1 FROM article_management.blocks 
+		WHERE (from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1) 
+	)`
+	err := s.db.QueryRowContext(ctx, queryString, a.ID, b.ID).Scan(&blocked)
+	return blocked, err
+}
+
+// GetBlocks gets users blocked by the user, newest block first, with the total count of them
+func (s *UserStore) GetBlocks(ctx context.Context, m *model.User, limit, offset int64) ([]model.User, int64, error) {
+	return s.getRelatedUsers(ctx, "article_management.blocks", "from_user_id", "to_user_id", m, nil, limit, offset)
+}
+
+// Block creates a block from user A to user B, removing follows and follow requests between them both ways
+//
+// Removed follows are relayed as unfollow events. Blocking a blocked user does nothing.
+func (s *UserStore) Block(ctx context.Context, a *model.User, b *model.User) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.blocks 
+			(from_user_id, to_user_id) VALUES ($1, $2) 
+			ON CONFLICT DO NOTHING`
+		_, err := tx.ExecContext(ctx, queryString, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+
+		queryString = `DELETE FROM article_management.follow_requests 
+			WHERE (from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1)`
+		_, err = tx.ExecContext(ctx, queryString, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+
+		queryString = `DELETE FROM article_management.follows 
+			WHERE (from_user_id = $1 AND to_user_id = $2) OR (from_user_id = $2 AND to_user_id = $1) 
+			RETURNING from_user_id`
+		rows, err := tx.QueryContext(ctx, queryString, a.ID, b.ID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		fromIDs := []uint{}
+		for rows.Next() {
+			var id uint
+
+			err = rows.Scan(&id)
+			if err != nil {
+				return err
+			}
+
+			fromIDs = append(fromIDs, id)
+		}
+
+		err = rows.Err()
+		if err != nil {
+			return err
+		}
+
+		for _, id := range fromIDs {
+			from, to := a, b
+			if id == b.ID {
+				from, to = b, a
+			}
+
+			e := model.NewFollowEvent(model.DomainEventUserUnfollowed, from, to)
+			err = writeEvent(tx, ctx, &e)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Unblock deletes a block from user A to user B
+func (s *UserStore) Unblock(ctx context.Context, a *model.User, b *model.User) error {
+	queryString := `DELETE FROM article_management.blocks 
+		WHERE from_user_id = $1 AND to_user_id = $2`
+	_, err := s.db.ExecContext(ctx, queryString, a.ID, b.ID)
+	return err
+}
+
+// GetMutes gets users muted by the user, newest mute first, with the total count of them
+func (s *UserStore) GetMutes(ctx context.Context, m *model.User, limit, offset int64) ([]model.User, int64, error) {
+	return s.getRelatedUsers(ctx, "article_management.mutes", "from_user_id", "to_user_id", m, nil, limit, offset)
+}
+
+// Mute creates a mute from user A to user B, muting a muted user does nothing
+func (s *UserStore) Mute(ctx context.Context, a *model.User, b *model.User) error {
+	queryString := `INSERT INTO article_management.mutes 
+		(from_user_id, to_user_id) VALUES ($1, $2) 
+		ON CONFLICT DO NOTHING`
+	_, err := s.db.ExecContext(ctx, queryString, a.ID, b.ID)
+	return err
+}
+
+// Unmute deletes a mute from user A to user B
+func (s *UserStore) Unmute(ctx context.Context, a *model.User, b *model.User) error {
+	queryString := `DELETE FROM article_management.mutes 
+		WHERE from_user_id = $1 AND to_user_id = $2`
+	_, err := s.db.ExecContext(ctx, queryString, a.ID, b.ID)
+	return err
+}
+
+// GetFeedToken gets private feed token of the user, creating one on first use
+func (s *UserStore) GetFeedToken(ctx context.Context, m *model.User) (*model.FeedToken, error) {
+	var token model.FeedToken
+
+	err := db.RunInTx(s.db, func(tx *sql.Tx) error {
+		newToken, err := model.NewFeedToken(m.ID)
+		if err != nil {
+			return err
+		}
+
+		queryString := `INSERT INTO article_management.feed_tokens (user_id, token) VALUES ($1, $2) 
+			ON CONFLICT (user_id) DO NOTHING`
+		_, err = tx.ExecContext(ctx, queryString, newToken.UserID, newToken.Token)
+		if err != nil {
+			return err
+		}
+
+		queryString = `SELECT user_id, token, created_at 
+			FROM article_management.feed_tokens 
+			WHERE user_id = $1`
+		return tx.QueryRowContext(ctx, queryString, m.ID).
+			Scan(&token.UserID, &token.Token, &token.CreatedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// RotateFeedToken replaces private feed token of the user, revoking the previous feed url
+func (s *UserStore) RotateFeedToken(ctx context.Context, m *model.FeedToken) (*model.FeedToken, error) {
+	var token model.FeedToken
+
+	queryString := `INSERT INTO article_management.feed_tokens (user_id, token) VALUES ($1, $2) 
+		ON CONFLICT (user_id) DO UPDATE SET token = EXCLUDED.token, created_at = DEFAULT 
+		RETURNING user_id, token, created_at`
+	err := s.db.QueryRowContext(ctx, queryString, m.UserID, m.Token).
+		Scan(&token.UserID, &token.Token, &token.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// GetByFeedToken finds a user by private feed token
+func (s *UserStore) GetByFeedToken(ctx context.Context, token string) (*model.User, error) {
+	var user model.User
+
+	queryString := `SELECT 
+		u.id, u.username, u.email, u.password, u.name, u.bio, u.image, u.private, u.created_at, u.updated_at 
+		FROM article_management.users u 
+		INNER JOIN article_management.feed_tokens ft ON ft.user_id = u.id 
+		WHERE ft.token = $1`
+	err := s.db.QueryRowContext(ctx, queryString, token).
+		Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Password,
+			&user.Name,
+			&user.Bio,
+			&user.Image,
+			&user.Private,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get user :%w", err)
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// notBlockedCond returns a query condition which holds unless the user of column
+// and the user of param block each other either way
+func notBlockedCond(column, param string) string {
+	return fmt.Sprintf(`NOT EXISTS ( 
+		SELECT 1 FROM article_management.blocks bl 
+		WHERE (bl.from_user_id = %[2]s AND bl.to_user_id = %[1]s) 
+		OR (bl.from_user_id = %[1]s AND bl.to_user_id = %[2]s) 
+	)`, column, param)
+}
+
+// visibleAuthorCond returns a query condition which holds when articles of the user of alias
+// are visible to the user of param, as the user of alias is not private, or is the user of param,
+// or is followed by the user of param
+func visibleAuthorCond(alias, param string) string {
+	return fmt.Sprintf(`(NOT %[1]s.private OR %[1]s.id = %[2]s OR EXISTS ( 
+		SELECT 1 FROM article_management.follows vf 
+		WHERE vf.from_user_id = %[2]s AND vf.to_user_id = %[1]s.id 
+	))`, alias, param)
+}
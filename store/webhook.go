@@ -0,0 +1,382 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/nathanbizkit/article-management-go/db"
+	"github.com/nathanbizkit/article-management-go/message"
+	"github.com/nathanbizkit/article-management-go/model"
+)
+
+// WebhookStore is a data access struct for webhooks and their deliveries
+type WebhookStore struct {
+	db *sql.DB
+}
+
+// NewWebhookStore returns a new WebhookStore
+func NewWebhookStore(db *sql.DB) *WebhookStore {
+	return &WebhookStore{db: db}
+}
+
+// Create creates a webhook and returns the newly created webhook
+func (s *WebhookStore) Create(ctx context.Context, m *model.Webhook) (*model.Webhook, error) {
+	var webhook model.Webhook
+
+	queryString := `INSERT INTO article_management.webhooks 
+		(user_id, url, secret, events, all_articles, active) VALUES ($1, $2, $3, $4, $5, $6) 
+		RETURNING id, user_id, url, secret, events, all_articles, active, created_at, updated_at`
+	err := s.db.QueryRowContext(ctx, queryString, m.UserID, m.URL, m.Secret, pq.Array(m.Events), m.AllArticles, m.Active).
+		Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.AllArticles,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to retrieve newly created webhook :%w", err)
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// GetByID finds a webhook by id
+func (s *WebhookStore) GetByID(ctx context.Context, id uint) (*model.Webhook, error) {
+	var webhook model.Webhook
+
+	queryString := `SELECT id, user_id, url, secret, events, all_articles, active, created_at, updated_at 
+		FROM article_management.webhooks 
+		WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, id).
+		Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.AllArticles,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get webhook :%w", err)
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// GetByUser gets webhooks of the user, oldest first
+func (s *WebhookStore) GetByUser(ctx context.Context, user *model.User) ([]model.Webhook, error) {
+	queryString := `SELECT id, user_id, url, secret, events, all_articles, active, created_at, updated_at 
+		FROM article_management.webhooks 
+		WHERE user_id = $1 
+		ORDER BY id ASC`
+	rows, err := s.db.QueryContext(ctx, queryString, user.ID)
+	if err != nil {
+		return []model.Webhook{}, err
+	}
+	defer rows.Close()
+
+	webhooks := []model.Webhook{}
+	for rows.Next() {
+		var webhook model.Webhook
+
+		err = rows.Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.AllArticles,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+		if err != nil {
+			return []model.Webhook{}, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, nil
+}
+
+// Update updates a webhook (for url, events, active)
+func (s *WebhookStore) Update(ctx context.Context, m *model.Webhook) (*model.Webhook, error) {
+	var webhook model.Webhook
+
+	queryString := `UPDATE article_management.webhooks 
+		SET url = $1, events = $2, active = $3, updated_at = DEFAULT 
+		WHERE id = $4 
+		RETURNING id, user_id, url, secret, events, all_articles, active, created_at, updated_at`
+	err := s.db.QueryRowContext(ctx, queryString, m.URL, pq.Array(m.Events), m.Active, m.ID).
+		Scan(
+			&webhook.ID,
+			&webhook.UserID,
+			&webhook.URL,
+			&webhook.Secret,
+			pq.Array(&webhook.Events),
+			&webhook.AllArticles,
+			&webhook.Active,
+			&webhook.CreatedAt,
+			&webhook.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to retrieve newly updated webhook :%w", err)
+		}
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+// Delete deletes a webhook along with its deliveries
+func (s *WebhookStore) Delete(ctx context.Context, m *model.Webhook) error {
+	queryString := `DELETE FROM article_management.webhooks WHERE id = $1`
+	_, err := s.db.ExecContext(ctx, queryString, m.ID)
+	return err
+}
+
+// GetDeliveries gets deliveries of the webhook, newest first, with the total count of them
+func (s *WebhookStore) GetDeliveries(ctx context.Context, m *model.Webhook, limit, offset int64) ([]model.WebhookDelivery, int64, error) {
+	var totalCount int64
+
+	queryString := `SELECT COUNT(id) FROM article_management.webhook_deliveries WHERE webhook_id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, m.ID).Scan(&totalCount)
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+
+	queryString = `SELECT 
+		id, webhook_id, event, payload, status, attempts, next_attempt_at, 
+		last_response_code, delivered_at, created_at, updated_at 
+		FROM article_management.webhook_deliveries 
+		WHERE webhook_id = $1 
+		ORDER BY created_at DESC, id DESC 
+		LIMIT $2 OFFSET $3`
+	rows, err := s.db.QueryContext(ctx, queryString, m.ID, limit, offset)
+	if err != nil {
+		return []model.WebhookDelivery{}, 0, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		var d model.WebhookDelivery
+
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.Event,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastResponseCode,
+			&d.DeliveredAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+		if err != nil {
+			return []model.WebhookDelivery{}, 0, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, totalCount, nil
+}
+
+// GetDeliveryByID finds a delivery by id along with its attempt log
+func (s *WebhookStore) GetDeliveryByID(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+
+	queryString := `SELECT 
+		id, webhook_id, event, payload, status, attempts, next_attempt_at, 
+		last_response_code, delivered_at, created_at, updated_at 
+		FROM article_management.webhook_deliveries 
+		WHERE id = $1`
+	err := s.db.QueryRowContext(ctx, queryString, id).
+		Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.Event,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastResponseCode,
+			&d.DeliveredAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+		)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = fmt.Errorf("failed to get webhook delivery :%w", err)
+		}
+		return nil, err
+	}
+
+	queryString = `SELECT id, delivery_id, response_code, error, duration_ms, created_at 
+		FROM article_management.webhook_delivery_attempts 
+		WHERE delivery_id = $1 
+		ORDER BY id ASC`
+	rows, err := s.db.QueryContext(ctx, queryString, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	d.AttemptLog = []model.WebhookDeliveryAttempt{}
+	for rows.Next() {
+		var a model.WebhookDeliveryAttempt
+		var durationMS int64
+
+		err = rows.Scan(&a.ID, &a.DeliveryID, &a.ResponseCode, &a.Error, &durationMS, &a.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		a.Duration = time.Duration(durationMS) * time.Millisecond
+		d.AttemptLog = append(d.AttemptLog, a)
+	}
+
+	return &d, nil
+}
+
+// Redeliver queues a delivery to be attempted again right away, with its attempts started over
+func (s *WebhookStore) Redeliver(ctx context.Context, m *model.WebhookDelivery) error {
+	queryString := `UPDATE article_management.webhook_deliveries 
+		SET status = $1, attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL, updated_at = DEFAULT 
+		WHERE id = $2 
+		RETURNING status, attempts, next_attempt_at, delivered_at, updated_at`
+	return s.db.QueryRowContext(ctx, queryString, model.WebhookDeliveryPending, m.ID).
+		Scan(&m.Status, &m.Attempts, &m.NextAttemptAt, &m.DeliveredAt, &m.UpdatedAt)
+}
+
+// ClaimDueDeliveries claims pending deliveries of active webhooks which are due along with their webhooks,
+// postponing them by lease so that no other worker or replica attempts them meanwhile
+func (s *WebhookStore) ClaimDueDeliveries(ctx context.Context, limit int64, lease time.Duration) ([]model.WebhookDelivery, error) {
+	queryString := `WITH due AS ( 
+			SELECT id FROM article_management.webhook_deliveries 
+			WHERE status = $1 AND next_attempt_at <= CURRENT_TIMESTAMP 
+			AND webhook_id IN (SELECT id FROM article_management.webhooks WHERE active) 
+			ORDER BY next_attempt_at ASC 
+			LIMIT $2 
+			FOR UPDATE SKIP LOCKED 
+		) 
+		UPDATE article_management.webhook_deliveries d 
+		SET next_attempt_at = CURRENT_TIMESTAMP + $3::FLOAT * INTERVAL '1 second' 
+		FROM due, article_management.webhooks w 
+		WHERE d.id = due.id AND w.id = d.webhook_id 
+		RETURNING 
+		d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at, 
+		d.last_response_code, d.delivered_at, d.created_at, d.updated_at, 
+		w.id, w.user_id, w.url, w.secret, w.events, w.all_articles, w.active, w.created_at, w.updated_at`
+	rows, err := s.db.QueryContext(ctx, queryString, model.WebhookDeliveryPending, limit, lease.Seconds())
+	if err != nil {
+		return []model.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries := make([]model.WebhookDelivery, 0, limit)
+	for rows.Next() {
+		var d model.WebhookDelivery
+
+		err = rows.Scan(
+			&d.ID,
+			&d.WebhookID,
+			&d.Event,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastResponseCode,
+			&d.DeliveredAt,
+			&d.CreatedAt,
+			&d.UpdatedAt,
+
+			&d.Webhook.ID,
+			&d.Webhook.UserID,
+			&d.Webhook.URL,
+			&d.Webhook.Secret,
+			pq.Array(&d.Webhook.Events),
+			&d.Webhook.AllArticles,
+			&d.Webhook.Active,
+			&d.Webhook.CreatedAt,
+			&d.Webhook.UpdatedAt,
+		)
+		if err != nil {
+			return []model.WebhookDelivery{}, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
+
+// SaveAttempt logs an attempt of a delivery and saves its updated status
+func (s *WebhookStore) SaveAttempt(ctx context.Context, m *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+	return db.RunInTx(s.db, func(tx *sql.Tx) error {
+		queryString := `INSERT INTO article_management.webhook_delivery_attempts 
+			(delivery_id, response_code, error, duration_ms) VALUES ($1, $2, $3, $4)`
+		_, err := tx.ExecContext(ctx, queryString, m.ID, attempt.ResponseCode, attempt.Error, attempt.Duration.Milliseconds())
+		if err != nil {
+			return err
+		}
+
+		queryString = `UPDATE article_management.webhook_deliveries 
+			SET status = $1, attempts = $2, next_attempt_at = $3, last_response_code = $4, 
+			delivered_at = $5, updated_at = DEFAULT 
+			WHERE id = $6`
+		_, err = tx.ExecContext(ctx, queryString,
+			m.Status, m.Attempts, m.NextAttemptAt, m.LastResponseCode, m.DeliveredAt, m.ID,
+		)
+		return err
+	})
+}
+
+// ConsumeEvent queues deliveries of the domain event to webhooks subscribed to it
+func (s *WebhookStore) ConsumeEvent(tx *sql.Tx, ctx context.Context, e *model.DomainEvent) error {
+	payload, delivered, err := model.NewWebhookPayload(e)
+	if err != nil || !delivered {
+		return err
+	}
+
+	return enqueueWebhooks(tx, ctx, *e.UserID, payload)
+}
+
+// enqueueWebhooks queues a payload for delivery to active webhooks subscribed to its event,
+// which are those of the owner of the article or of all articles
+func enqueueWebhooks(tx *sql.Tx, ctx context.Context, ownerID uint, payload message.WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	queryString := `INSERT INTO article_management.webhook_deliveries (webhook_id, event, payload) 
+		SELECT id, $1::VARCHAR, $2::JSONB FROM article_management.webhooks 
+		WHERE active AND $1::VARCHAR = ANY(events) AND (all_articles OR user_id = $3)`
+	_, err = tx.ExecContext(ctx, queryString, payload.Event, string(body), ownerID)
+	return err
+}
@@ -137,16 +137,28 @@ func NewLocalTestContainer() (*LocalTestContainer, error) {
 	// set env
 	dbHostPort := strings.Split(dbResource.GetHostPort("5432/tcp"), ":")
 	environ := &env.ENV{
-		AppMode:          "test",
-		AppPort:          strconv.Itoa(appPort),
-		AppTLSPort:       strconv.Itoa(appTLSPort),
-		AuthJWTSecretKey: "secretKey",
-		DBUser:           dbUser,
-		DBPass:           dbPass,
-		DBHost:           dbHostPort[0],
-		DBPort:           dbHostPort[1],
-		DBName:           dbName,
-		IsDevelopment:    true,
+		AppMode:            "test",
+		AppPort:            strconv.Itoa(appPort),
+		AppTLSPort:         strconv.Itoa(appTLSPort),
+		AppBaseURL:         fmt.Sprintf("http://localhost:%d", appPort),
+		AuthJWTSecretKey:   "secretKey",
+		DBUser:             dbUser,
+		DBPass:             dbPass,
+		DBHost:             dbHostPort[0],
+		DBPort:             dbHostPort[1],
+		DBName:             dbName,
+		StorageDriver:      "local",
+		MediaMaxSize:       5 << 20,
+		CommentMaxDepth:    5,
+		CommentEditWindow:  15 * time.Minute,
+		Reactions:          []string{"thumbs_up", "heart", "laugh"},
+		StreamHeartbeat:    15 * time.Second,
+		StreamRetention:    24 * time.Hour,
+		WebhookTimeout:     10 * time.Second,
+		WebhookMaxAttempts: 8,
+		MailDriver:         "log",
+		MailFrom:           "no-reply@localhost",
+		IsDevelopment:      true,
 	}
 
 	return &LocalTestContainer{
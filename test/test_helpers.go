@@ -20,7 +20,7 @@ const englishCharset = "abcdefghijklmnopqrstuvwxyz" +
 var seededRand *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
 
 // NewTestENV returns a mock of env object
-func NewTestENV(t *testing.T) *env.ENV {
+func NewTestENV(t testing.TB) *env.ENV {
 	t.Helper()
 
 	return &env.ENV{
@@ -38,7 +38,7 @@ func NewTestENV(t *testing.T) *env.ENV {
 }
 
 // NewTestLogger returns a logger for testing
-func NewTestLogger(t *testing.T) zerolog.Logger {
+func NewTestLogger(t testing.TB) zerolog.Logger {
 	t.Helper()
 
 	w := zerolog.ConsoleWriter{Out: io.Discard}
@@ -46,7 +46,7 @@ func NewTestLogger(t *testing.T) zerolog.Logger {
 }
 
 // NewLocalTestContainer returns a local test container
-func NewLocalTestContainer(t *testing.T) *container.LocalTestContainer {
+func NewLocalTestContainer(t testing.TB) *container.LocalTestContainer {
 	t.Helper()
 
 	if testing.Short() {
@@ -66,7 +66,7 @@ func NewLocalTestContainer(t *testing.T) *container.LocalTestContainer {
 }
 
 // RandomString returns a random string with x length in English and Numbers
-func RandomString(t *testing.T, length int) string {
+func RandomString(t testing.TB, length int) string {
 	t.Helper()
 
 	b := make([]byte, 0, length)
@@ -78,7 +78,7 @@ func RandomString(t *testing.T, length int) string {
 }
 
 // AddCookieToRequest attaches a api-related cookie to request header
-func AddCookieToRequest(t *testing.T, req *http.Request, name, value string) {
+func AddCookieToRequest(t testing.TB, req *http.Request, name, value string) {
 	t.Helper()
 
 	cookie := &http.Cookie{
@@ -98,7 +98,7 @@ func AddCookieToRequest(t *testing.T, req *http.Request, name, value string) {
 }
 
 // AddCookieToResponse attaches a api-related cookie to response header
-func AddCookieToResponse(t *testing.T, w http.ResponseWriter, name, value string) {
+func AddCookieToResponse(t testing.TB, w http.ResponseWriter, name, value string) {
 	t.Helper()
 
 	cookie := &http.Cookie{
@@ -118,7 +118,7 @@ func AddCookieToResponse(t *testing.T, w http.ResponseWriter, name, value string
 }
 
 // GetResponseBody parses http json response body into specific object type
-func GetResponseBody[T any](t *testing.T, res *http.Response) T {
+func GetResponseBody[T any](t testing.TB, res *http.Response) T {
 	t.Helper()
 
 	var jsonBody T
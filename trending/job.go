@@ -0,0 +1,54 @@
+package trending
+
+import (
+	"context"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+// DefaultInterval is how often trending articles are ranked again
+const DefaultInterval = 10 * time.Minute
+
+// Job ranks trending articles from their recent activity in the background
+//
+// Activity is counted as it happens, views by the article handler and favorites
+// and comments by the trending consumer of outbox, so ranking only reads counts.
+type Job struct {
+	logger *zerolog.Logger
+	ts     *store.TrendingStore
+}
+
+// NewJob returns a new trending job with logger and trending store
+func NewJob(l *zerolog.Logger, ts *store.TrendingStore) *Job {
+	return &Job{
+		logger: l,
+		ts:     ts,
+	}
+}
+
+// Run ranks trending articles right away and then every interval until ctx is done
+func (j *Job) Run(ctx context.Context, interval time.Duration) {
+	j.logger.Info().Msg("starting trending job...")
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+
+		err := j.ts.Recompute(ctx, start)
+		if err != nil {
+			j.logger.Error().Err(err).Msg("failed to rank trending articles")
+		} else {
+			j.logger.Info().Dur("took", time.Since(start)).Msg("ranked trending articles")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// DefaultWorkers is the number of deliveries attempted concurrently
+	DefaultWorkers = 4
+	// DefaultPollInterval is how often due deliveries are claimed
+	DefaultPollInterval = 5 * time.Second
+)
+
+// Headers of posted deliveries
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// maxErrorLen is the length error messages of failed attempts are truncated to
+const maxErrorLen = 255
+
+// Dispatcher posts queued deliveries to webhooks in the background
+//
+// Deliveries are queued in database along with the changes causing them,
+// so they survive restarts and are shared by replicas, each claiming due
+// deliveries for a while so that no delivery is attempted twice at once.
+type Dispatcher struct {
+	logger      *zerolog.Logger
+	ws          *store.WebhookStore
+	client      *http.Client
+	timeout     time.Duration
+	maxAttempts int
+}
+
+// NewDispatcher returns a new dispatcher with logger, webhook store, timeout of attempts and max attempts of deliveries
+func NewDispatcher(l *zerolog.Logger, ws *store.WebhookStore, timeout time.Duration, maxAttempts int) *Dispatcher {
+	return &Dispatcher{
+		logger:      l,
+		ws:          ws,
+		client:      newClient(timeout, model.IsPublicAddr),
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// newClient returns a client which only connects to allowed addresses and does not follow redirects
+//
+// Addresses are checked once resolved, right before connecting, so that no host can resolve to
+// an address which is not allowed after its url was validated. Redirects are attempts which failed.
+func newClient(timeout time.Duration, allowed func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !allowed(addrPort.Addr()) {
+				return fmt.Errorf("address %s is not allowed", addrPort.Addr())
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy, which would connect to addresses for the client
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run claims due deliveries every poll interval and attempts them with workers until ctx is done
+func (d *Dispatcher) Run(ctx context.Context, workers int, pollInterval time.Duration) {
+	d.logger.Info().Int("workers", workers).Msg("starting webhook dispatcher...")
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.dispatchDue(ctx, workers)
+		}
+	}
+}
+
+// dispatchDue attempts due deliveries with workers until there is none left
+func (d *Dispatcher) dispatchDue(ctx context.Context, workers int) {
+	for ctx.Err() == nil {
+		// deliveries are claimed long enough for all of them to be attempted
+		deliveries, err := d.ws.ClaimDueDeliveries(ctx, int64(workers), 2*d.timeout)
+		if err != nil {
+			d.logger.Error().Err(err).Msg("failed to claim webhook deliveries")
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			wg.Add(1)
+			go func(delivery *model.WebhookDelivery) {
+				defer wg.Done()
+
+				err := d.Deliver(ctx, delivery)
+				if err != nil {
+					d.logger.Error().Err(err).Uint("delivery_id", delivery.ID).Msg("failed to save webhook delivery attempt")
+				}
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < workers {
+			return
+		}
+	}
+}
+
+// Deliver posts a claimed delivery to its webhook signed with its secret along with the time it is sent,
+// and logs the attempt along with updated status of delivery
+func (d *Dispatcher) Deliver(ctx context.Context, delivery *model.WebhookDelivery) error {
+	var responseCode *int
+	var errMsg string
+
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(delivery.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "article-management-webhook")
+		req.Header.Set(HeaderEvent, delivery.Event)
+		req.Header.Set(HeaderDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+		timestamp := start.Unix()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(HeaderSignature, delivery.Webhook.Sign(timestamp, delivery.Payload))
+
+		var resp *http.Response
+		resp, err = d.client.Do(req)
+		if err == nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+
+			responseCode = &resp.StatusCode
+		}
+	}
+
+	if err != nil {
+		errMsg = err.Error()
+		if len(errMsg) > maxErrorLen {
+			errMsg = errMsg[:maxErrorLen]
+		}
+	}
+
+	attempt := model.WebhookDeliveryAttempt{
+		DeliveryID:   delivery.ID,
+		ResponseCode: responseCode,
+		Error:        errMsg,
+		Duration:     time.Since(start),
+	}
+
+	delivery.RecordAttempt(responseCode, time.Now(), d.maxAttempts)
+
+	// attempt is saved even when ctx is done, otherwise it would be attempted again
+	return d.ws.SaveAttempt(context.WithoutCancel(ctx), delivery, &attempt)
+}
@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nathanbizkit/article-management-go/model"
+	"github.com/nathanbizkit/article-management-go/outbox"
+	"github.com/nathanbizkit/article-management-go/store"
+	"github.com/nathanbizkit/article-management-go/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegration_Dispatcher(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration tests.")
+	}
+
+	l := test.NewTestLogger(t)
+	lct := test.NewLocalTestContainer(t)
+
+	us := store.NewUserStore(lct.DB())
+	as := store.NewArticleStore(lct.DB())
+	ws := store.NewWebhookStore(lct.DB())
+
+	randStr := test.RandomString(t, 10)
+	user, err := us.Create(context.Background(), &model.User{
+		Username: fmt.Sprintf("user_%s", randStr),
+		Email:    fmt.Sprintf("%s@example.com", randStr),
+		Password: "P@55w0rD!",
+		Name:     "USER",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_, err := lct.DB().Exec(`DELETE FROM article_management.users WHERE id = $1`, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	type received struct {
+		event     string
+		timestamp string
+		signature string
+		body      []byte
+	}
+
+	var mu sync.Mutex
+	var requests []received
+	statusCode := http.StatusInternalServerError
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests = append(requests, received{
+			event:     r.Header.Get(HeaderEvent),
+			timestamp: r.Header.Get(HeaderTimestamp),
+			signature: r.Header.Get(HeaderSignature),
+			body:      body,
+		})
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	m, err := model.NewWebhook(user.ID, server.URL, []string{model.WebhookEventArticlePublished}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook, err := ws.Create(context.Background(), &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = as.Create(context.Background(), &model.Article{
+		Title:       "webhook article",
+		Description: "webhook article",
+		Body:        "webhook article",
+		UserID:      user.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	relay := outbox.NewRelay(&l, store.NewOutboxStore(lct.DB()), outbox.DefaultRetention)
+	relay.Subscribe(outbox.ConsumerWebhooks, ws.ConsumeEvent)
+
+	err = relay.RelayAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewDispatcher(&l, ws, time.Second, 2)
+
+	// the test server listens on loopback, which deliveries are not allowed to reach
+	d.client = newClient(time.Second, func(netip.Addr) bool { return true })
+
+	deliveries, _, err := ws.GetDeliveries(context.Background(), webhook, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Len(t, deliveries, 1) {
+		return
+	}
+
+	deliveryID := deliveries[0].ID
+
+	t.Run("Deliver: failed attempt is retried later", func(t *testing.T) {
+		d.dispatchDue(context.Background(), DefaultWorkers)
+
+		delivery, err := ws.GetDeliveryByID(context.Background(), deliveryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, http.StatusInternalServerError, *delivery.LastResponseCode)
+		assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+		assert.Len(t, delivery.AttemptLog, 1)
+
+		if assert.Len(t, requests, 1) {
+			assert.Equal(t, model.WebhookEventArticlePublished, requests[0].event)
+			timestamp, err := strconv.ParseInt(requests[0].timestamp, 10, 64)
+			assert.NoError(t, err)
+			assert.WithinDuration(t, time.Now(), time.Unix(timestamp, 0), time.Minute)
+			assert.Equal(t, webhook.Sign(timestamp, requests[0].body), requests[0].signature)
+			assert.JSONEq(t, string(delivery.Payload), string(requests[0].body))
+		}
+
+		// not due yet
+		d.dispatchDue(context.Background(), DefaultWorkers)
+		assert.Len(t, requests, 1)
+	})
+
+	t.Run("Deliver: succeeded after redelivery", func(t *testing.T) {
+		mu.Lock()
+		statusCode = http.StatusNoContent
+		mu.Unlock()
+
+		delivery, err := ws.GetDeliveryByID(context.Background(), deliveryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ws.Redeliver(context.Background(), delivery)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		d.dispatchDue(context.Background(), DefaultWorkers)
+
+		delivery, err = ws.GetDeliveryByID(context.Background(), deliveryID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+		assert.Len(t, delivery.AttemptLog, 2)
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("newClient", func(t *testing.T) {
+		redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusFound))
+		defer redirect.Close()
+
+		// non-public addresses are refused once resolved
+		_, err := NewDispatcher(&l, ws, time.Second, 2).client.Get(server.URL)
+		assert.ErrorContains(t, err, "is not allowed")
+
+		// redirects are not followed
+		resp, err := d.client.Get(redirect.URL)
+		if assert.NoError(t, err) {
+			resp.Body.Close()
+			assert.Equal(t, http.StatusFound, resp.StatusCode)
+		}
+	})
+}